@@ -0,0 +1,387 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/converged-computing/metrics-operator/api/v1beta1"
+)
+
+// v1beta1 addons can reference several metrics (or none that exist) and
+// v1alpha2 addons belong to one metric. When the layout cannot be expressed
+// per metric, the original v1beta1 addons are kept in this annotation.
+const AddonsAnnotation = "flux-framework.org/v1beta1-addons"
+
+// v1alpha2 metrics give sole tenancy as soleTenancy or sole-tenancy, and both are
+// lifted into the same field. The metrics that used sole-tenancy are kept in this
+// annotation, so they convert back with the same spelling.
+const SoleTenancyAnnotation = "flux-framework.org/v1alpha2-sole-tenancy"
+
+// ConvertTo converts this MetricSet to the v1beta1 (hub) version
+func (src *MetricSet) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.MetricSet)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1 MetricSet but got a %T", dstRaw)
+	}
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	// Addons in v1beta1 reference metrics by name, so names must be unique
+	names := map[string]bool{}
+	for _, metric := range src.Spec.Metrics {
+		if names[metric.Name] {
+			return fmt.Errorf("metric %s is defined more than once and cannot be converted to v1beta1", metric.Name)
+		}
+		names[metric.Name] = true
+	}
+
+	spec := src.Spec.DeepCopy()
+	dst.Spec = v1beta1.MetricSetSpec{
+		DontSetFQDN:     spec.DontSetFQDN,
+		ServiceName:     spec.ServiceName,
+		DeadlineSeconds: spec.DeadlineSeconds,
+		Pod:             v1beta1.Pod(spec.Pod),
+		Pods:            spec.Pods,
+		Resources:       v1beta1.ContainerResource(spec.Resources),
+		Logging:         v1beta1.Logging(spec.Logging),
+		Results:         (*v1beta1.Results)(spec.Results),
+	}
+	if spec.Metrics != nil {
+		dst.Spec.Metrics = []v1beta1.Metric{}
+	}
+	spelled := []string{}
+	for _, metric := range spec.Metrics {
+		_, ok := metric.Options["sole-tenancy"]
+		converted := metricToHub(metric)
+		_, kept := converted.Options["sole-tenancy"]
+		if ok && !kept {
+			spelled = append(spelled, metric.Name)
+		}
+		dst.Spec.Metrics = append(dst.Spec.Metrics, converted)
+	}
+	delete(dst.Annotations, SoleTenancyAnnotation)
+	if len(spelled) > 0 {
+		raw, err := json.Marshal(spelled)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[SoleTenancyAnnotation] = string(raw)
+	}
+	dst.Spec.Addons = addonsToHub(spec.Metrics)
+	for _, status := range src.Status.Metrics {
+		dst.Status.Metrics = append(dst.Status.Metrics, v1beta1.MetricStatus(*status.DeepCopy()))
+	}
+	dst.Status.HeldPods = src.Status.HeldPods
+	for _, image := range src.Status.Images {
+		dst.Status.Images = append(dst.Status.Images, v1beta1.ImageStatus(image))
+	}
+
+	// Restore the original v1beta1 addons if the metrics still match them
+	raw, ok := dst.Annotations[AddonsAnnotation]
+	if !ok {
+		return nil
+	}
+	addons := []v1beta1.MetricAddon{}
+	err := json.Unmarshal([]byte(raw), &addons)
+	if err != nil {
+		return nil
+	}
+	expanded := addonsFromHub(addons, dst.Spec.Metrics)
+	for _, metric := range spec.Metrics {
+		if !equality.Semantic.DeepEqual(expanded[metric.Name], metric.Addons) {
+			return nil
+		}
+	}
+	dst.Spec.Addons = addons
+	delete(dst.Annotations, AddonsAnnotation)
+	return nil
+}
+
+// ConvertFrom converts from the v1beta1 (hub) version to this version
+func (dst *MetricSet) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.MetricSet)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1 MetricSet but got a %T", srcRaw)
+	}
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	spec := src.Spec.DeepCopy()
+	dst.Spec = MetricSetSpec{
+		DontSetFQDN:     spec.DontSetFQDN,
+		ServiceName:     spec.ServiceName,
+		DeadlineSeconds: spec.DeadlineSeconds,
+		Pod:             Pod(spec.Pod),
+		Pods:            spec.Pods,
+		Resources:       ContainerResource(spec.Resources),
+		Logging:         Logging(spec.Logging),
+		Results:         (*Results)(spec.Results),
+	}
+	if spec.Metrics != nil {
+		dst.Spec.Metrics = []Metric{}
+	}
+	for _, status := range src.Status.Metrics {
+		dst.Status.Metrics = append(dst.Status.Metrics, MetricStatus(*status.DeepCopy()))
+	}
+	dst.Status.HeldPods = src.Status.HeldPods
+	for _, image := range src.Status.Images {
+		dst.Status.Images = append(dst.Status.Images, ImageStatus(image))
+	}
+
+	// Restore the sole-tenancy spelling for the metrics that used it
+	names := []string{}
+	spellings, ok := dst.Annotations[SoleTenancyAnnotation]
+	if ok {
+		delete(dst.Annotations, SoleTenancyAnnotation)
+		_ = json.Unmarshal([]byte(spellings), &names)
+	}
+	spelled := map[string]bool{}
+	for _, name := range names {
+		spelled[name] = true
+	}
+	addons := addonsFromHub(spec.Addons, spec.Metrics)
+	for _, metric := range spec.Metrics {
+		converted := metricFromHub(metric)
+		converted.Addons = addons[metric.Name]
+		st, ok := converted.Options["soleTenancy"]
+		if ok && metric.SoleTenancy != nil && spelled[metric.Name] {
+			delete(converted.Options, "soleTenancy")
+			converted.Options["sole-tenancy"] = st
+		}
+		dst.Spec.Metrics = append(dst.Spec.Metrics, converted)
+	}
+
+	// Keep the v1beta1 addons if converting back would not reproduce them
+	if equality.Semantic.DeepEqual(addonsToHub(dst.Spec.Metrics), spec.Addons) {
+		return nil
+	}
+	raw, err := json.Marshal(spec.Addons)
+	if err != nil {
+		return err
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[AddonsAnnotation] = string(raw)
+	return nil
+}
+
+// metricToHub lifts common options into typed v1beta1 fields. An option is
+// only lifted when the typed field can represent it exactly, and sole tenancy
+// is not lifted when both of its spellings are given.
+func metricToHub(metric Metric) v1beta1.Metric {
+	converted := v1beta1.Metric{
+		Name:         metric.Name,
+		Image:        metric.Image,
+		Pods:         metric.Pods,
+		LauncherPods: metric.LauncherPods,
+		WorkerPods:   metric.WorkerPods,
+		Timeout:      metric.Timeout,
+		Templates:    templatesToHub(metric.Templates),
+		Options:      metric.Options,
+		ListOptions:  metric.ListOptions,
+		MapOptions:   metric.MapOptions,
+		Attributes:   containerSpecToHub(metric.Attributes),
+		Resources: v1beta1.ContainerResources{
+			Limits:   v1beta1.ContainerResource(metric.Resources.Limits),
+			Requests: v1beta1.ContainerResource(metric.Resources.Requests),
+		},
+	}
+	_, camel := metric.Options["soleTenancy"]
+	_, kebab := metric.Options["sole-tenancy"]
+	for key, value := range metric.Options {
+		if !v1beta1.IsTypedOption(key, value) {
+			continue
+		}
+		if (key == "soleTenancy" || key == "sole-tenancy") && camel && kebab {
+			continue
+		}
+		switch key {
+		case "command":
+			converted.Command = value.StrVal
+		case "workdir":
+			converted.Workdir = value.StrVal
+		case "tasks":
+			tasks := value.IntVal
+			converted.Tasks = &tasks
+		case "soleTenancy", "sole-tenancy":
+			st := value.StrVal == "true"
+			converted.SoleTenancy = &st
+		}
+		delete(converted.Options, key)
+	}
+	return converted
+}
+
+// metricFromHub puts typed v1beta1 fields back into options
+func metricFromHub(metric v1beta1.Metric) Metric {
+	converted := Metric{
+		Name:         metric.Name,
+		Image:        metric.Image,
+		Pods:         metric.Pods,
+		LauncherPods: metric.LauncherPods,
+		WorkerPods:   metric.WorkerPods,
+		Timeout:      metric.Timeout,
+		Templates:    templatesFromHub(metric.Templates),
+		Options:      metric.Options,
+		ListOptions:  metric.ListOptions,
+		MapOptions:   metric.MapOptions,
+		Attributes:   containerSpecFromHub(metric.Attributes),
+		Resources: ContainerResources{
+			Limits:   ContainerResource(metric.Resources.Limits),
+			Requests: ContainerResource(metric.Resources.Requests),
+		},
+	}
+	setOption := func(key string, value intstr.IntOrString) {
+		if converted.Options == nil {
+			converted.Options = map[string]intstr.IntOrString{}
+		}
+		converted.Options[key] = value
+	}
+	if metric.Command != "" {
+		setOption("command", intstr.FromString(metric.Command))
+	}
+	if metric.Workdir != "" {
+		setOption("workdir", intstr.FromString(metric.Workdir))
+	}
+	if metric.Tasks != nil {
+		setOption("tasks", intstr.FromInt(int(*metric.Tasks)))
+	}
+	if metric.SoleTenancy != nil {
+		setOption("soleTenancy", intstr.FromString(fmt.Sprintf("%t", *metric.SoleTenancy)))
+	}
+	return converted
+}
+
+// addonsToHub lists the addons of each metric, in order, referencing the metric
+func addonsToHub(metrics []Metric) []v1beta1.MetricAddon {
+	addons := []v1beta1.MetricAddon{}
+	for _, metric := range metrics {
+		for _, addon := range metric.Addons {
+			addons = append(addons, v1beta1.MetricAddon{
+				Name:        addon.Name,
+				Metrics:     []string{metric.Name},
+				Options:     addon.Options,
+				ListOptions: addon.ListOptions,
+				MapOptions:  addon.MapOptions,
+			})
+		}
+	}
+	return addons
+}
+
+// addonsFromHub returns the addons for each metric (by name) that exists
+func addonsFromHub(addons []v1beta1.MetricAddon, metrics []v1beta1.Metric) map[string][]MetricAddon {
+	lookup := map[string][]MetricAddon{}
+	for _, metric := range metrics {
+		lookup[metric.Name] = nil
+	}
+	for _, addon := range addons {
+		for _, name := range addon.Metrics {
+			existing, ok := lookup[name]
+			if !ok {
+				continue
+			}
+			lookup[name] = append(existing, MetricAddon{
+				Name:        addon.Name,
+				Options:     addon.Options,
+				ListOptions: addon.ListOptions,
+				MapOptions:  addon.MapOptions,
+			})
+		}
+	}
+	return lookup
+}
+
+func templatesToHub(templates []EntrypointTemplate) []v1beta1.EntrypointTemplate {
+	if templates == nil {
+		return nil
+	}
+	converted := []v1beta1.EntrypointTemplate{}
+	for _, t := range templates {
+		template := v1beta1.EntrypointTemplate{Block: t.Block, Job: t.Job, Template: t.Template}
+		if t.ConfigMap != nil {
+			cm := v1beta1.TemplateConfigMap(*t.ConfigMap)
+			template.ConfigMap = &cm
+		}
+		converted = append(converted, template)
+	}
+	return converted
+}
+
+func templatesFromHub(templates []v1beta1.EntrypointTemplate) []EntrypointTemplate {
+	if templates == nil {
+		return nil
+	}
+	converted := []EntrypointTemplate{}
+	for _, t := range templates {
+		template := EntrypointTemplate{Block: t.Block, Job: t.Job, Template: t.Template}
+		if t.ConfigMap != nil {
+			cm := TemplateConfigMap(*t.ConfigMap)
+			template.ConfigMap = &cm
+		}
+		converted = append(converted, template)
+	}
+	return converted
+}
+
+func containerSpecToHub(spec ContainerSpec) v1beta1.ContainerSpec {
+	sc := spec.SecurityContext
+	return v1beta1.ContainerSpec{
+		SecurityContext: v1beta1.SecurityContext{
+			Privileged:               sc.Privileged,
+			AllowPtrace:              sc.AllowPtrace,
+			AllowAdmin:               sc.AllowAdmin,
+			RunAsUser:                sc.RunAsUser,
+			RunAsGroup:               sc.RunAsGroup,
+			FSGroup:                  sc.FSGroup,
+			RunAsNonRoot:             sc.RunAsNonRoot,
+			AllowPrivilegeEscalation: sc.AllowPrivilegeEscalation,
+			ReadOnlyRootFilesystem:   sc.ReadOnlyRootFilesystem,
+			Capabilities:             v1beta1.Capabilities(sc.Capabilities),
+			SeccompProfile:           v1beta1.Profile(sc.SeccompProfile),
+			AppArmorProfile:          v1beta1.Profile(sc.AppArmorProfile),
+		},
+	}
+}
+
+func containerSpecFromHub(spec v1beta1.ContainerSpec) ContainerSpec {
+	sc := spec.SecurityContext
+	return ContainerSpec{
+		SecurityContext: SecurityContext{
+			Privileged:               sc.Privileged,
+			AllowPtrace:              sc.AllowPtrace,
+			AllowAdmin:               sc.AllowAdmin,
+			RunAsUser:                sc.RunAsUser,
+			RunAsGroup:               sc.RunAsGroup,
+			FSGroup:                  sc.FSGroup,
+			RunAsNonRoot:             sc.RunAsNonRoot,
+			AllowPrivilegeEscalation: sc.AllowPrivilegeEscalation,
+			ReadOnlyRootFilesystem:   sc.ReadOnlyRootFilesystem,
+			Capabilities:             Capabilities(sc.Capabilities),
+			SeccompProfile:           Profile(sc.SeccompProfile),
+			AppArmorProfile:          Profile(sc.AppArmorProfile),
+		},
+	}
+}
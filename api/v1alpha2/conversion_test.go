@@ -0,0 +1,245 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"fmt"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/converged-computing/metrics-operator/api/v1beta1"
+)
+
+const fuzzIterations = 1000
+
+// newFuzzer returns a fuzzer that only generates objects valid for conversion
+func newFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.NewWithSeed(seed).NilChance(0.2).NumElements(0, 4).Funcs(
+
+		// Type metadata is set by the conversion webhook, not the functions
+		func(meta *metav1.TypeMeta, c fuzz.Continue) {},
+
+		// Metadata isn't changed by conversion, keep it small
+		func(meta *metav1.ObjectMeta, c fuzz.Continue) {
+			c.Fuzz(&meta.Name)
+			c.Fuzz(&meta.Namespace)
+			c.Fuzz(&meta.Labels)
+			c.Fuzz(&meta.Annotations)
+			delete(meta.Annotations, AddonsAnnotation)
+			delete(meta.Annotations, SoleTenancyAnnotation)
+		},
+
+		// Metric names must be unique to reference them from v1beta1 addons
+		func(spec *MetricSetSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(spec)
+			for i := range spec.Metrics {
+				spec.Metrics[i].Name = fmt.Sprintf("%s-%d", spec.Metrics[i].Name, i)
+			}
+		},
+
+		// Common options are more interesting when they are present
+		func(metric *Metric, c fuzz.Continue) {
+			c.FuzzNoCustom(metric)
+			if metric.Options == nil {
+				return
+			}
+			if c.RandBool() {
+				metric.Options["command"] = intstr.FromString(c.RandString())
+			}
+			if c.RandBool() {
+				metric.Options["tasks"] = intstr.FromInt(int(c.Int31()))
+			}
+			if c.RandBool() {
+				metric.Options["soleTenancy"] = intstr.FromString(fmt.Sprintf("%t", c.RandBool()))
+			}
+			if c.RandBool() {
+				metric.Options["sole-tenancy"] = intstr.FromString(fmt.Sprintf("%t", c.RandBool()))
+			}
+		},
+		func(spec *v1beta1.MetricSetSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(spec)
+			names := []string{}
+			for i := range spec.Metrics {
+				spec.Metrics[i].Name = fmt.Sprintf("%s-%d", spec.Metrics[i].Name, i)
+				names = append(names, spec.Metrics[i].Name)
+			}
+
+			// Reference existing metrics most of the time, and unknown ones otherwise
+			for i := range spec.Addons {
+				for j := range spec.Addons[i].Metrics {
+					if len(names) > 0 && c.Intn(4) > 0 {
+						spec.Addons[i].Metrics[j] = names[c.Intn(len(names))]
+					}
+				}
+			}
+		},
+
+		// Reserved options are typed fields in v1beta1
+		func(metric *v1beta1.Metric, c fuzz.Continue) {
+			c.FuzzNoCustom(metric)
+			for key, value := range metric.Options {
+				if v1beta1.IsTypedOption(key, value) {
+					delete(metric.Options, key)
+				}
+			}
+		},
+	)
+}
+
+func TestFuzzyConversionSpokeHubSpoke(t *testing.T) {
+	for i := 0; i < fuzzIterations; i++ {
+		original := &MetricSet{}
+		newFuzzer(int64(i)).Fuzz(original)
+
+		hub := &v1beta1.MetricSet{}
+		err := original.ConvertTo(hub)
+		if err != nil {
+			t.Fatalf("seed %d: converting to v1beta1: %s", i, err)
+		}
+		converted := &MetricSet{}
+		err = converted.ConvertFrom(hub)
+		if err != nil {
+			t.Fatalf("seed %d: converting from v1beta1: %s", i, err)
+		}
+		if !equality.Semantic.DeepEqual(original, converted) {
+			t.Fatalf("seed %d: v1alpha2 round trip is lossy\nbefore: %+v\nafter:  %+v", i, original, converted)
+		}
+	}
+}
+
+func TestFuzzyConversionHubSpokeHub(t *testing.T) {
+	for i := 0; i < fuzzIterations; i++ {
+		original := &v1beta1.MetricSet{}
+		newFuzzer(int64(i)).Fuzz(original)
+
+		spoke := &MetricSet{}
+		err := spoke.ConvertFrom(original)
+		if err != nil {
+			t.Fatalf("seed %d: converting from v1beta1: %s", i, err)
+		}
+		converted := &v1beta1.MetricSet{}
+		err = spoke.ConvertTo(converted)
+		if err != nil {
+			t.Fatalf("seed %d: converting to v1beta1: %s", i, err)
+		}
+		if !equality.Semantic.DeepEqual(original, converted) {
+			t.Fatalf("seed %d: v1beta1 round trip is lossy\nbefore: %+v\nafter:  %+v", i, original, converted)
+		}
+	}
+}
+
+func TestConvertLiftsCommonOptions(t *testing.T) {
+	set := &MetricSet{
+		Spec: MetricSetSpec{
+			Pods: 2,
+			Metrics: []Metric{
+				{
+					Name: "network-osu-benchmark",
+					Options: map[string]intstr.IntOrString{
+						"command":     intstr.FromString("osu_latency"),
+						"tasks":       intstr.FromInt(4),
+						"soleTenancy": intstr.FromString("no"),
+					},
+					Addons: []MetricAddon{{Name: "volume-empty"}},
+				},
+			},
+		},
+	}
+	hub := &v1beta1.MetricSet{}
+	err := set.ConvertTo(hub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metric := hub.Spec.Metrics[0]
+	if metric.Command != "osu_latency" || metric.Tasks == nil || *metric.Tasks != 4 {
+		t.Errorf("expected command and tasks to be lifted, got %+v", metric)
+	}
+
+	// "no" is not a boolean we can represent exactly, so it stays an option
+	if metric.SoleTenancy != nil || metric.Options["soleTenancy"].StrVal != "no" {
+		t.Errorf("expected soleTenancy to remain an option, got %+v", metric)
+	}
+	if len(hub.Spec.Addons) != 1 || hub.Spec.Addons[0].Metrics[0] != "network-osu-benchmark" {
+		t.Errorf("expected addon to reference its metric, got %+v", hub.Spec.Addons)
+	}
+	err = hub.Validate()
+	if err != nil {
+		t.Errorf("converted MetricSet is not valid: %s", err)
+	}
+}
+
+func TestConvertSoleTenancy(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		options map[string]intstr.IntOrString
+		lifted  *bool
+	}{
+		{options: map[string]intstr.IntOrString{"soleTenancy": intstr.FromString("false")}, lifted: &no},
+		{options: map[string]intstr.IntOrString{"sole-tenancy": intstr.FromString("true")}, lifted: &yes},
+		{
+			options: map[string]intstr.IntOrString{
+				"soleTenancy":  intstr.FromString("false"),
+				"sole-tenancy": intstr.FromString("true"),
+			},
+		},
+	}
+	for _, test := range tests {
+
+		// Either spelling is lifted, but not both
+		set := &MetricSet{Spec: MetricSetSpec{Metrics: []Metric{{Name: "perf-stream", Options: test.options}}}}
+		original := set.DeepCopy()
+		hub := &v1beta1.MetricSet{}
+		err := set.ConvertTo(hub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		metric := hub.Spec.Metrics[0]
+		if test.lifted == nil {
+			if metric.SoleTenancy != nil || len(metric.Options) != 2 {
+				t.Errorf("%v: expected both spellings to remain options, got %+v", test.options, metric)
+			}
+		} else if metric.SoleTenancy == nil || *metric.SoleTenancy != *test.lifted || len(metric.Options) != 0 {
+			t.Errorf("%v: expected sole tenancy %t to be lifted, got %+v", test.options, *test.lifted, metric)
+		}
+
+		// It converts back with the same spelling
+		converted := &MetricSet{}
+		err = converted.ConvertFrom(hub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equality.Semantic.DeepEqual(original, converted) {
+			t.Errorf("%v: expected the same options, got %v (%v)", test.options, converted.Spec.Metrics[0].Options, converted.Annotations)
+		}
+	}
+}
+
+func TestConvertDuplicateMetrics(t *testing.T) {
+	set := &MetricSet{
+		Spec: MetricSetSpec{
+			Metrics: []Metric{{Name: "perf-sysstat"}, {Name: "perf-sysstat"}},
+		},
+	}
+	err := set.ConvertTo(&v1beta1.MetricSet{})
+	if err == nil {
+		t.Errorf("expected duplicate metric names to fail conversion")
+	}
+}
@@ -19,6 +19,7 @@ package v1alpha2
 import (
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -68,16 +69,69 @@ type MetricSetSpec struct {
 	// Right now we just include an interactive option
 	//+optional
 	Logging Logging `json:"logging"`
+
+	// Persist the output of metric containers (and files) to a volume
+	// +optional
+	Results *Results `json:"results,omitempty"`
+}
+
+// Results persists the collected output of each metric container to a
+// PersistentVolumeClaim, under <metricset>/<run-id>/<replicated-job>/<pod-index>/<container>/
+type Results struct {
+
+	// An existing claim to use. If not set, a claim named <metricset>-results is created,
+	// and it is kept when the MetricSet is deleted.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// Storage class for a created claim
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Size of a created claim
+	// +kubebuilder:default="1Gi"
+	// +default="1Gi"
+	// +optional
+	Size string `json:"size,omitempty"`
+
+	// Access modes of a created claim (defaults to ReadWriteMany, since pods can be on different nodes)
+	// +optional
+	AccessModes []string `json:"accessModes,omitempty"`
+
+	// Globs of files to save in addition to the output (e.g., /opt/traces/*.txt)
+	// +optional
+	Files []string `json:"files,omitempty"`
 }
 
 type Logging struct {
 
 	// Don't allow the application, metric, or storage test to finish
 	// This adds sleep infinity at the end to allow for interactive mode.
+	// Prefer debug, which lets successful runs finish.
 	// +optional
 	Interactive bool `json:"interactive"`
+
+	// Hold metric containers for debugging after the metric command runs,
+	// either onFailure (a non-zero exit code) or always
+	// +kubebuilder:validation:Enum=onFailure;always;""
+	// +optional
+	Debug string `json:"debug,omitempty"`
+
+	// Lines of output to save to the debug file (defaults to 100)
+	// +optional
+	DebugLines int32 `json:"debugLines,omitempty"`
+
+	// Seconds to hold a container for debugging (defaults to 3600)
+	// +optional
+	DebugHoldSeconds int32 `json:"debugHoldSeconds,omitempty"`
 }
 
+// Debug modes for logging
+const (
+	DebugOnFailure = "onFailure"
+	DebugAlways    = "always"
+)
+
 // Pod attributes that can be given to an application or metric
 type Pod struct {
 
@@ -116,6 +170,66 @@ type SecurityContext struct {
 
 	//+optional
 	AllowAdmin bool `json:"allowAdmin"`
+
+	// Run the container as this user id
+	//+optional
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+
+	// Run the container as this group id
+	//+optional
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+
+	// Group id that owns mounted volumes. This is set for the entire pod,
+	// so containers in the same replicated job should agree on it.
+	//+optional
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+
+	// Require the container to run as a non-root user
+	//+optional
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
+
+	// Allow the process to gain more privileges than its parent
+	//+optional
+	AllowPrivilegeEscalation *bool `json:"allowPrivilegeEscalation,omitempty"`
+
+	// Mount the root filesystem of the container as read only
+	//+optional
+	ReadOnlyRootFilesystem bool `json:"readOnlyRootFilesystem"`
+
+	// Linux capabilities to add or drop (e.g., NET_RAW, or ALL to drop)
+	//+optional
+	Capabilities Capabilities `json:"capabilities"`
+
+	// Seccomp profile for the container
+	//+optional
+	SeccompProfile Profile `json:"seccompProfile"`
+
+	// AppArmor profile for the container
+	//+optional
+	AppArmorProfile Profile `json:"appArmorProfile"`
+}
+
+// Capabilities to add and drop, by name
+type Capabilities struct {
+
+	//+optional
+	Add []string `json:"add,omitempty"`
+
+	//+optional
+	Drop []string `json:"drop,omitempty"`
+}
+
+// A Profile is a seccomp or AppArmor profile
+type Profile struct {
+
+	// Type of profile: RuntimeDefault, Localhost, or Unconfined
+	// +kubebuilder:validation:Enum=RuntimeDefault;Localhost;Unconfined;""
+	//+optional
+	Type string `json:"type,omitempty"`
+
+	// Name of the profile on the node, only used for Localhost
+	//+optional
+	LocalhostProfile string `json:"localhostProfile,omitempty"`
 }
 
 // A Metric addon is an interface that exposes extra volumes for a metric. Examples include:
@@ -177,6 +291,28 @@ type ContainerResource map[string]intstr.IntOrString
 type Metric struct {
 	Name string `json:"name"`
 
+	// Number of pods for the metric, overrides the MetricSet pods
+	// +optional
+	Pods int32 `json:"pods,omitempty"`
+
+	// Number of launcher pods for a launcher/worker metric (defaults to 1)
+	// +optional
+	LauncherPods int32 `json:"launcherPods,omitempty"`
+
+	// Number of worker pods for a launcher/worker metric
+	// Defaults to the metric pods minus the launcher
+	// +optional
+	WorkerPods int32 `json:"workerPods,omitempty"`
+
+	// Seconds the metric can run before the entrypoint stops it.
+	// The default (0) does not set a timeout.
+	// +optional
+	Timeout int32 `json:"timeout,omitempty"`
+
+	// Templates override blocks of the metric entrypoints
+	// +optional
+	Templates []EntrypointTemplate `json:"templates,omitempty"`
+
 	// Metric Options
 	// Metric specific options
 	// +optional
@@ -225,11 +361,105 @@ func (m *MetricSet) GetPodLabels() map[string]string {
 	return podLabels
 }
 
+// An EntrypointTemplate overrides a block of the metric entrypoints with a
+// Go template. The template is given inline or in a ConfigMap.
+type EntrypointTemplate struct {
+
+	// Block of the entrypoint to override
+	// +kubebuilder:validation:Enum=pre;command;post
+	Block string `json:"block"`
+
+	// Replicated job of the entrypoint (e.g., l for a launcher), defaults to all
+	// +optional
+	Job string `json:"job,omitempty"`
+
+	// Inline template
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// ConfigMap (in the MetricSet namespace) with the template
+	// +optional
+	ConfigMap *TemplateConfigMap `json:"configMap,omitempty"`
+}
+
+// TemplateConfigMap references a template in a ConfigMap
+type TemplateConfigMap struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// Entrypoint blocks that can be overridden with a template
+const (
+	BlockPre     = "pre"
+	BlockCommand = "command"
+	BlockPost    = "post"
+)
+
+// Validate an entrypoint template, which is rendered when the JobSet is created
+func (t *EntrypointTemplate) Validate() error {
+	if t.Block != BlockPre && t.Block != BlockCommand && t.Block != BlockPost {
+		return fmt.Errorf("template block %q must be one of pre, command, or post", t.Block)
+	}
+	if (t.Template == "") == (t.ConfigMap == nil) {
+		return fmt.Errorf("template for the %s block needs one of an inline template or a configMap", t.Block)
+	}
+	if t.ConfigMap != nil && (t.ConfigMap.Name == "" || t.ConfigMap.Key == "") {
+		return fmt.Errorf("template configMap for the %s block needs a name and key", t.Block)
+	}
+	return nil
+}
+
+// Reasons a metric did not complete, as reported in the status
+const (
+	MetricReasonTimeout = "Timeout"
+	MetricReasonFailed  = "Failed"
+)
+
 // MetricStatus defines the observed state of Metric
-type MetricSetStatus struct{}
+type MetricSetStatus struct {
+
+	// Metrics that did not complete, with the reason and pods
+	// +optional
+	Metrics []MetricStatus `json:"metrics,omitempty"`
+
+	// Pods with a metric container held for debugging
+	// +optional
+	HeldPods []string `json:"heldPods,omitempty"`
+
+	// Images of the metrics and addons, with digests resolved by the operator
+	// +optional
+	Images []ImageStatus `json:"images,omitempty"`
+}
+
+// ImageStatus is an image reference and the digest it resolved to
+type ImageStatus struct {
+	Image string `json:"image"`
+
+	// Digest (e.g., sha256:...), empty if it could not be resolved
+	// +optional
+	Digest string `json:"digest,omitempty"`
+}
+
+// MetricStatus describes pods of a metric that did not complete
+type MetricStatus struct {
+	Name string `json:"name"`
+
+	// Reason the metric did not complete (Timeout or Failed)
+	Reason string `json:"reason"`
 
+	// Exit code of the metric container
+	// +optional
+	ExitCode int32 `json:"exitCode,omitempty"`
+
+	// Pods with this reason
+	// +optional
+	Pods []string `json:"pods,omitempty"`
+}
+
+// +genclient
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 
 // MetricSet is the Schema for the metrics API
 type MetricSet struct {
@@ -257,6 +487,34 @@ func (m *MetricSet) Validate() bool {
 		fmt.Printf("😥️ Pods must be >= 1.")
 		return false
 	}
+	for _, metric := range m.Spec.Metrics {
+		if metric.Pods < 0 || metric.LauncherPods < 0 || metric.WorkerPods < 0 {
+			fmt.Printf("😥️ Pods for metric %s cannot be negative.\n", metric.Name)
+			return false
+		}
+		if metric.Timeout < 0 {
+			fmt.Printf("😥️ Timeout for metric %s cannot be negative.\n", metric.Name)
+			return false
+		}
+		for _, t := range metric.Templates {
+			err := t.Validate()
+			if err != nil {
+				fmt.Printf("😥️ Metric %s: %s.\n", metric.Name, err)
+				return false
+			}
+		}
+	}
+	if m.Spec.Logging.DebugLines < 0 || m.Spec.Logging.DebugHoldSeconds < 0 {
+		fmt.Printf("😥️ Debug lines and hold seconds cannot be negative.\n")
+		return false
+	}
+	if m.Spec.Results != nil && m.Spec.Results.Size != "" {
+		_, err := resource.ParseQuantity(m.Spec.Results.Size)
+		if err != nil {
+			fmt.Printf("😥️ Results size %s is not a valid quantity: %s\n", m.Spec.Results.Size, err)
+			return false
+		}
+	}
 	return true
 }
 
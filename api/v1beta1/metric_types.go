@@ -0,0 +1,525 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// IsTypedOption determines if an option (from v1alpha2) can be expressed
+// exactly as a typed Metric field. These options must be given as fields.
+func IsTypedOption(key string, value intstr.IntOrString) bool {
+	switch key {
+	case "command", "workdir":
+		return value.Type == intstr.String && value.StrVal != ""
+	case "tasks":
+		return value.Type == intstr.Int
+	case "soleTenancy", "sole-tenancy":
+		return value.Type == intstr.String && (value.StrVal == "true" || value.StrVal == "false")
+	}
+	return false
+}
+
+// MetricSetSpec defines the desired state of a MetricSet
+type MetricSetSpec struct {
+
+	// Metrics to run, each is identified by a unique name
+	// +optional
+	Metrics []Metric `json:"metrics"`
+
+	// Addons customize one or more metrics, referenced by name
+	// +optional
+	Addons []MetricAddon `json:"addons,omitempty"`
+
+	// Don't set JobSet FQDN
+	// +optional
+	DontSetFQDN bool `json:"dontSetFQDN"`
+
+	// Service name for the JobSet (MetricsSet) cluster network
+	// +kubebuilder:default="ms"
+	// +default="ms"
+	// +optional
+	ServiceName string `json:"serviceName"`
+
+	// Should the job be limited to a particular number of seconds?
+	// Approximately one year. This cannot be zero or job won't start
+	// +kubebuilder:default=31500000
+	// +default=31500000
+	// +optional
+	DeadlineSeconds int64 `json:"deadlineSeconds,omitempty"`
+
+	// Pod spec for the application, standalone, or storage metrics
+	//+optional
+	Pod Pod `json:"pod"`
+
+	// Parallelism (e.g., pods)
+	// +kubebuilder:default=1
+	// +default=1
+	// +optional
+	Pods int32 `json:"pods"`
+
+	// Resources include limits and requests for each pod (that include a JobSet)
+	// +optional
+	Resources ContainerResource `json:"resources"`
+
+	// Logging spec, preparing for other kinds of logging
+	// Right now we just include an interactive option
+	//+optional
+	Logging Logging `json:"logging"`
+
+	// Persist the output of metric containers (and files) to a volume
+	// +optional
+	Results *Results `json:"results,omitempty"`
+}
+
+// Results persists the collected output of each metric container to a
+// PersistentVolumeClaim, under <metricset>/<run-id>/<replicated-job>/<pod-index>/<container>/
+type Results struct {
+
+	// An existing claim to use. If not set, a claim named <metricset>-results is created,
+	// and it is kept when the MetricSet is deleted.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// Storage class for a created claim
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Size of a created claim
+	// +kubebuilder:default="1Gi"
+	// +default="1Gi"
+	// +optional
+	Size string `json:"size,omitempty"`
+
+	// Access modes of a created claim (defaults to ReadWriteMany, since pods can be on different nodes)
+	// +optional
+	AccessModes []string `json:"accessModes,omitempty"`
+
+	// Globs of files to save in addition to the output (e.g., /opt/traces/*.txt)
+	// +optional
+	Files []string `json:"files,omitempty"`
+}
+
+type Logging struct {
+
+	// Don't allow the application, metric, or storage test to finish
+	// This adds sleep infinity at the end to allow for interactive mode.
+	// Prefer debug, which lets successful runs finish.
+	// +optional
+	Interactive bool `json:"interactive"`
+
+	// Hold metric containers for debugging after the metric command runs,
+	// either onFailure (a non-zero exit code) or always
+	// +kubebuilder:validation:Enum=onFailure;always;""
+	// +optional
+	Debug string `json:"debug,omitempty"`
+
+	// Lines of output to save to the debug file (defaults to 100)
+	// +optional
+	DebugLines int32 `json:"debugLines,omitempty"`
+
+	// Seconds to hold a container for debugging (defaults to 3600)
+	// +optional
+	DebugHoldSeconds int32 `json:"debugHoldSeconds,omitempty"`
+}
+
+// Debug modes for logging
+const (
+	DebugOnFailure = "onFailure"
+	DebugAlways    = "always"
+)
+
+// Pod attributes that can be given to an application or metric
+type Pod struct {
+
+	// Annotations to add to the pod
+	//+optional
+	Annotations map[string]string `json:"annotations"`
+
+	// Labels to add to the pod
+	//+optional
+	Labels map[string]string `json:"labels"`
+
+	// name of service account to associate with pod
+	//+optional
+	ServiceAccountName string `json:"serviceAccountName"`
+
+	// NodeSelector labels
+	//+optional
+	NodeSelector map[string]string `json:"nodeSelector"`
+}
+
+// A container spec can belong to a metric or application
+type ContainerSpec struct {
+
+	// Security context for the pod
+	//+optional
+	SecurityContext SecurityContext `json:"securityContext"`
+}
+
+type SecurityContext struct {
+
+	//+optional
+	Privileged bool `json:"privileged"`
+
+	//+optional
+	AllowPtrace bool `json:"allowPtrace"`
+
+	//+optional
+	AllowAdmin bool `json:"allowAdmin"`
+
+	// Run the container as this user id
+	//+optional
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+
+	// Run the container as this group id
+	//+optional
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+
+	// Group id that owns mounted volumes. This is set for the entire pod,
+	// so containers in the same replicated job should agree on it.
+	//+optional
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+
+	// Require the container to run as a non-root user
+	//+optional
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
+
+	// Allow the process to gain more privileges than its parent
+	//+optional
+	AllowPrivilegeEscalation *bool `json:"allowPrivilegeEscalation,omitempty"`
+
+	// Mount the root filesystem of the container as read only
+	//+optional
+	ReadOnlyRootFilesystem bool `json:"readOnlyRootFilesystem"`
+
+	// Linux capabilities to add or drop (e.g., NET_RAW, or ALL to drop)
+	//+optional
+	Capabilities Capabilities `json:"capabilities"`
+
+	// Seccomp profile for the container
+	//+optional
+	SeccompProfile Profile `json:"seccompProfile"`
+
+	// AppArmor profile for the container
+	//+optional
+	AppArmorProfile Profile `json:"appArmorProfile"`
+}
+
+// Capabilities to add and drop, by name
+type Capabilities struct {
+
+	//+optional
+	Add []string `json:"add,omitempty"`
+
+	//+optional
+	Drop []string `json:"drop,omitempty"`
+}
+
+// A Profile is a seccomp or AppArmor profile
+type Profile struct {
+
+	// Type of profile: RuntimeDefault, Localhost, or Unconfined
+	// +kubebuilder:validation:Enum=RuntimeDefault;Localhost;Unconfined;""
+	//+optional
+	Type string `json:"type,omitempty"`
+
+	// Name of the profile on the node, only used for Localhost
+	//+optional
+	LocalhostProfile string `json:"localhostProfile,omitempty"`
+}
+
+// A MetricAddon exposes extra volumes, containers, or other customization
+// for the metrics it references.
+type MetricAddon struct {
+	Name string `json:"name"`
+
+	// Names of the metrics this addon applies to
+	Metrics []string `json:"metrics"`
+
+	// Metric Addon Options
+	// +optional
+	Options map[string]intstr.IntOrString `json:"options"`
+
+	// Addon List Options
+	// +optional
+	ListOptions map[string][]intstr.IntOrString `json:"listOptions"`
+
+	// Addon Map Options
+	// +optional
+	MapOptions map[string]map[string]intstr.IntOrString `json:"mapOptions"`
+}
+
+// ContainerResources include limits and requests
+type ContainerResources struct {
+
+	// +optional
+	Limits ContainerResource `json:"limits"`
+
+	// +optional
+	Requests ContainerResource `json:"requests"`
+}
+
+type ContainerResource map[string]intstr.IntOrString
+
+// A Metric is a named container (and replicated jobs) known to the operator.
+// Common settings are typed fields, and anything specific to the metric is
+// given with options.
+type Metric struct {
+	Name string `json:"name"`
+
+	// Use a custom container image (advanced users only)
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Number of pods for the metric, overrides the MetricSet pods
+	// +optional
+	Pods int32 `json:"pods,omitempty"`
+
+	// Number of launcher pods for a launcher/worker metric (defaults to 1)
+	// +optional
+	LauncherPods int32 `json:"launcherPods,omitempty"`
+
+	// Number of worker pods for a launcher/worker metric
+	// Defaults to the metric pods minus the launcher
+	// +optional
+	WorkerPods int32 `json:"workerPods,omitempty"`
+
+	// Seconds the metric can run before the entrypoint stops it.
+	// The default (0) does not set a timeout.
+	// +optional
+	Timeout int32 `json:"timeout,omitempty"`
+
+	// Templates override blocks of the metric entrypoints
+	// +optional
+	Templates []EntrypointTemplate `json:"templates,omitempty"`
+
+	// Command to run, for metrics that support a custom command
+	// +optional
+	Command string `json:"command,omitempty"`
+
+	// Working directory for the command
+	// +optional
+	Workdir string `json:"workdir,omitempty"`
+
+	// Number of tasks, for metrics that support it
+	// +optional
+	Tasks *int32 `json:"tasks,omitempty"`
+
+	// Assign one pod per node, for metrics that support it
+	// +optional
+	SoleTenancy *bool `json:"soleTenancy,omitempty"`
+
+	// Metric specific options
+	// +optional
+	Options map[string]intstr.IntOrString `json:"options,omitempty"`
+
+	// Metric specific list options
+	// +optional
+	ListOptions map[string][]intstr.IntOrString `json:"listOptions,omitempty"`
+
+	// Metric specific map options
+	// +optional
+	MapOptions map[string]map[string]intstr.IntOrString `json:"mapOptions,omitempty"`
+
+	// Container Spec has attributes for the container
+	//+optional
+	Attributes ContainerSpec `json:"attributes"`
+
+	// Resources include limits and requests for the metric container
+	// +optional
+	Resources ContainerResources `json:"resources"`
+}
+
+// An EntrypointTemplate overrides a block of the metric entrypoints with a
+// Go template. The template is given inline or in a ConfigMap.
+type EntrypointTemplate struct {
+
+	// Block of the entrypoint to override
+	// +kubebuilder:validation:Enum=pre;command;post
+	Block string `json:"block"`
+
+	// Replicated job of the entrypoint (e.g., l for a launcher), defaults to all
+	// +optional
+	Job string `json:"job,omitempty"`
+
+	// Inline template
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// ConfigMap (in the MetricSet namespace) with the template
+	// +optional
+	ConfigMap *TemplateConfigMap `json:"configMap,omitempty"`
+}
+
+// TemplateConfigMap references a template in a ConfigMap
+type TemplateConfigMap struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// Entrypoint blocks that can be overridden with a template
+const (
+	BlockPre     = "pre"
+	BlockCommand = "command"
+	BlockPost    = "post"
+)
+
+// Validate an entrypoint template, which is rendered when the JobSet is created
+func (t *EntrypointTemplate) Validate() error {
+	if t.Block != BlockPre && t.Block != BlockCommand && t.Block != BlockPost {
+		return fmt.Errorf("template block %q must be one of pre, command, or post", t.Block)
+	}
+	if (t.Template == "") == (t.ConfigMap == nil) {
+		return fmt.Errorf("template for the %s block needs one of an inline template or a configMap", t.Block)
+	}
+	if t.ConfigMap != nil && (t.ConfigMap.Name == "" || t.ConfigMap.Key == "") {
+		return fmt.Errorf("template configMap for the %s block needs a name and key", t.Block)
+	}
+	return nil
+}
+
+// Reasons a metric did not complete, as reported in the status
+const (
+	MetricReasonTimeout = "Timeout"
+	MetricReasonFailed  = "Failed"
+)
+
+// MetricSetStatus defines the observed state of a MetricSet
+type MetricSetStatus struct {
+
+	// Metrics that did not complete, with the reason and pods
+	// +optional
+	Metrics []MetricStatus `json:"metrics,omitempty"`
+
+	// Pods with a metric container held for debugging
+	// +optional
+	HeldPods []string `json:"heldPods,omitempty"`
+
+	// Images of the metrics and addons, with digests resolved by the operator
+	// +optional
+	Images []ImageStatus `json:"images,omitempty"`
+}
+
+// ImageStatus is an image reference and the digest it resolved to
+type ImageStatus struct {
+	Image string `json:"image"`
+
+	// Digest (e.g., sha256:...), empty if it could not be resolved
+	// +optional
+	Digest string `json:"digest,omitempty"`
+}
+
+// MetricStatus describes pods of a metric that did not complete
+type MetricStatus struct {
+	Name string `json:"name"`
+
+	// Reason the metric did not complete (Timeout or Failed)
+	Reason string `json:"reason"`
+
+	// Exit code of the metric container
+	// +optional
+	ExitCode int32 `json:"exitCode,omitempty"`
+
+	// Pods with this reason
+	// +optional
+	Pods []string `json:"pods,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:unservedversion
+
+// MetricSet is the Schema for the metrics API
+type MetricSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MetricSetSpec   `json:"spec,omitempty"`
+	Status MetricSetStatus `json:"status,omitempty"`
+}
+
+// Validate a requested metricset
+func (m *MetricSet) Validate() error {
+	if len(m.Spec.Metrics) == 0 {
+		return fmt.Errorf("one or more metrics are required")
+	}
+	if m.Spec.Pods < 1 {
+		return fmt.Errorf("pods must be >= 1")
+	}
+	if m.Spec.Logging.DebugLines < 0 || m.Spec.Logging.DebugHoldSeconds < 0 {
+		return fmt.Errorf("debug lines and hold seconds cannot be negative")
+	}
+	if m.Spec.Results != nil && m.Spec.Results.Size != "" {
+		_, err := resource.ParseQuantity(m.Spec.Results.Size)
+		if err != nil {
+			return fmt.Errorf("results size %s is not a valid quantity: %s", m.Spec.Results.Size, err)
+		}
+	}
+	names := map[string]bool{}
+	for _, metric := range m.Spec.Metrics {
+		if names[metric.Name] {
+			return fmt.Errorf("metric %s is defined more than once", metric.Name)
+		}
+		names[metric.Name] = true
+		if metric.Pods < 0 || metric.LauncherPods < 0 || metric.WorkerPods < 0 {
+			return fmt.Errorf("pods for metric %s cannot be negative", metric.Name)
+		}
+		if metric.Timeout < 0 {
+			return fmt.Errorf("timeout for metric %s cannot be negative", metric.Name)
+		}
+		for _, t := range metric.Templates {
+			err := t.Validate()
+			if err != nil {
+				return fmt.Errorf("metric %s: %s", metric.Name, err)
+			}
+		}
+		for key, value := range metric.Options {
+			if IsTypedOption(key, value) {
+				return fmt.Errorf("option %s for metric %s must be set as a field", key, metric.Name)
+			}
+		}
+	}
+	for _, addon := range m.Spec.Addons {
+		if len(addon.Metrics) == 0 {
+			return fmt.Errorf("addon %s must reference one or more metrics", addon.Name)
+		}
+		for _, name := range addon.Metrics {
+			if !names[name] {
+				return fmt.Errorf("addon %s references unknown metric %s", addon.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+//+kubebuilder:object:root=true
+
+// MetricSetList contains a list of MetricSet
+type MetricSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MetricSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MetricSet{}, &MetricSetList{})
+}
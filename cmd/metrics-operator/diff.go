@@ -0,0 +1,132 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/converged-computing/metrics-operator/pkg/results"
+)
+
+var diffUsage = `Usage: metrics-operator diff [options] <runA> <runB>
+
+Compare the results of two runs, aligned by metric, options, field, and labels. A run
+is a path (a saved log, or a copy of the results volume), or else the name of a
+MetricSet in the cluster. A change is significant if it is at least the thresholds, and
+(when both runs have more than one value, e.g., from pods or timepoints) the difference
+of the means is at least --sigma standard errors. The exit code is 2 if a result regressed.
+
+Options:
+`
+
+// exitRegressed is the exit code when a result regressed
+const exitRegressed = 2
+
+// diffCommand prints the differences of two runs
+func diffCommand(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), diffUsage)
+		flags.PrintDefaults()
+	}
+	namespace := flags.String("namespace", "default", "Namespace of MetricSets in the cluster")
+	relative := flags.Float64("threshold", results.DefaultThresholds.Relative, "Relative change to be significant (0.05 is 5%)")
+	absolute := flags.Float64("absolute", results.DefaultThresholds.Absolute, "Absolute change to be significant, in the unit of a result")
+	sigma := flags.Float64("sigma", results.DefaultThresholds.Sigma, "Standard errors to be significant, for results with repetitions")
+	format := flags.String("format", "text", "Format of the differences: text or json")
+	changes := flags.Bool("changes", false, "Only print results that are not unchanged")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("provide two runs to compare")
+	}
+
+	runs := [][]results.Result{}
+	for _, run := range flags.Args() {
+		var found []results.Result
+		var err error
+		if _, statErr := os.Stat(run); statErr == nil {
+			found, err = readResults("", *namespace, []string{run})
+		} else {
+			found, err = readResults(run, *namespace, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s", run, err)
+		}
+		runs = append(runs, found)
+	}
+	thresholds := results.Thresholds{Relative: *relative, Absolute: *absolute, Sigma: *sigma}
+	differences := []results.Difference{}
+	counts := map[string]int{}
+	for _, difference := range results.Diff(runs[0], runs[1], thresholds) {
+		counts[difference.Status]++
+		if !*changes || difference.Status != results.StatusUnchanged {
+			differences = append(differences, difference)
+		}
+	}
+
+	switch *format {
+	case "json":
+		rows := []map[string]interface{}{}
+		for _, d := range differences {
+			rows = append(rows, map[string]interface{}{
+				"status": d.Status, "metric": d.Metric, "options": d.Options, "field": d.Field,
+				"labels": d.Labels, "unit": d.Unit, "better": d.Better, "repetitions": d.Repetitions,
+				"a": finite(d.A), "b": finite(d.B), "delta": finite(d.Delta),
+				"relative": finite(d.Relative), "sigma": finite(d.Sigma),
+			})
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(rows)
+		if err != nil {
+			return err
+		}
+	case "text":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "STATUS\tMETRIC\tOPTIONS\tFIELD\tLABELS\tA\tB\tDELTA\tRELATIVE\tSIGMA\tUNIT")
+		for _, d := range differences {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				d.Status, d.Metric, d.Options, d.Field, results.FormatLabels(d.Labels),
+				formatFloat(d.A), formatFloat(d.B), formatFloat(d.Delta), formatPercent(d.Relative), formatFloat(d.Sigma), d.Unit)
+		}
+		w.Flush()
+		fmt.Printf("\n%d regressed, %d improved, %d changed, %d unchanged, %d removed, %d added\n",
+			counts[results.StatusRegressed], counts[results.StatusImproved], counts[results.StatusChanged],
+			counts[results.StatusUnchanged], counts[results.StatusRemoved], counts[results.StatusAdded])
+	default:
+		return fmt.Errorf("%s is not a known format, choose text or json", *format)
+	}
+
+	if counts[results.StatusRegressed] > 0 {
+		return exitError{code: exitRegressed, message: fmt.Sprintf("%d results regressed", counts[results.StatusRegressed])}
+	}
+	return nil
+}
+
+// finite is a value for JSON, which doesn't have infinity (e.g., a change from zero)
+func finite(value float64) interface{} {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return nil
+	}
+	return value
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', 6, 64)
+}
+
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(value*100, 'f', 1, 64) + "%"
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	// Metrics are registered here! Importing registers once
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/app"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/io"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/network"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/perf"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/sys"
+	//
+	// +kubebuilder:scaffold:imports
+)
+
+var usage = `Usage: metrics-operator <command> [options]
+
+Commands:
+  results export   Parse results of a MetricSet, and write them as a table
+  report           Write an HTML or Markdown report of one or more runs
+  diff             Compare the results of two runs, and find regressions
+`
+
+// A command runs with the arguments after its name
+type command func(args []string) error
+
+var commands = map[string]command{
+	"results": resultsCommand,
+	"report":  reportCommand,
+	"diff":    diffCommand,
+}
+
+// An exitError is a result (e.g., a regression) with an exit code other than 1
+type exitError struct {
+	code    int
+	message string
+}
+
+func (e exitError) Error() string {
+	return e.message
+}
+
+func main() {
+	if len(os.Args) <= 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s is not a known command\n\n%s", os.Args[1], usage)
+		os.Exit(1)
+	}
+	err := run(os.Args[2:])
+	if exit, ok := err.(exitError); ok {
+		fmt.Fprintln(os.Stderr, exit.message)
+		os.Exit(exit.code)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+}
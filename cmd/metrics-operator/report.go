@@ -0,0 +1,92 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/converged-computing/metrics-operator/pkg/report"
+)
+
+var reportUsage = `Usage: metrics-operator report [options] [path...]
+
+Write a report of one or more runs, with a table and charts for each metric and the
+metadata of each run. Each MetricSet in the cluster (--metricset, which can be repeated)
+and each path (a saved log, or a copy of the results volume) is a run.
+
+Options:
+`
+
+// names is a flag that can be repeated
+type names []string
+
+func (n *names) String() string {
+	return strings.Join(*n, ",")
+}
+
+func (n *names) Set(value string) error {
+	*n = append(*n, value)
+	return nil
+}
+
+// reportCommand writes a report as html or markdown
+func reportCommand(args []string) error {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), reportUsage)
+		flags.PrintDefaults()
+	}
+	metricsets := names{}
+	flags.Var(&metricsets, "metricset", "Name of a MetricSet in the cluster to read logs from")
+	namespace := flags.String("namespace", "default", "Namespace of the MetricSets")
+	title := flags.String("title", "Metrics Operator Report", "Title of the report")
+	format := flags.String("format", "", "Format of the report: html or markdown (defaults to markdown for an output ending in .md, or html)")
+	output := flags.String("output", "", "File to write (defaults to stdout)")
+	flags.Parse(args)
+
+	runs := []report.Run{}
+	for _, metricset := range metricsets {
+		found, err := readResults(metricset, *namespace, nil)
+		if err != nil {
+			return fmt.Errorf("%s: %s", metricset, err)
+		}
+		runs = append(runs, report.Run{Name: metricset, Results: found})
+	}
+	for _, path := range flags.Args() {
+		found, err := readResults("", *namespace, []string{path})
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		runs = append(runs, report.Run{Name: filepath.Base(filepath.Clean(path)), Results: found})
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("provide a --metricset or paths to saved logs")
+	}
+	if *format == "" {
+		*format = report.FormatHTML
+		if filepath.Ext(*output) == ".md" {
+			*format = report.FormatMarkdown
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	return report.New(*title, runs).Write(w, *format)
+}
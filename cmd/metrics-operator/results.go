@@ -0,0 +1,121 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/converged-computing/metrics-operator/pkg/results"
+)
+
+var exportUsage = `Usage: metrics-operator results export [options] [path...]
+
+Parse the results of a MetricSet with the parser of each metric, and write a table
+with one row for each sample. Logs are read from the cluster (--metricset), or from
+saved logs and copies of the results volume (paths).
+
+Options:
+`
+
+// resultsCommand runs a subcommand of results
+func resultsCommand(args []string) error {
+	if len(args) == 0 || args[0] != "export" {
+		return fmt.Errorf("choose a results command: export")
+	}
+	return exportCommand(args[1:])
+}
+
+// exportCommand writes the table of results as csv, jsonl, or parquet
+func exportCommand(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), exportUsage)
+		flags.PrintDefaults()
+	}
+	metricset := flags.String("metricset", "", "Name of a MetricSet in the cluster to read logs from")
+	namespace := flags.String("namespace", "default", "Namespace of the MetricSet")
+	format := flags.String("format", "", "Format of the table: csv, jsonl, or parquet (defaults to the extension of the output, or csv)")
+	output := flags.String("output", "", "File to write (defaults to stdout)")
+	flags.Parse(args)
+
+	found, err := readResults(*metricset, *namespace, flags.Args())
+	if err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*output), ".")
+	}
+	if *format == "" {
+		*format = results.FormatCSV
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	return results.NewTable(found).Write(w, *format)
+}
+
+// readResults parses the logs of a MetricSet in the cluster, or saved logs at paths.
+// A log that cannot be parsed is a warning, since a MetricSet can have metrics without parsers.
+func readResults(metricset, namespace string, paths []string) ([]results.Result, error) {
+	var logs []results.Log
+	var err error
+	switch {
+	case metricset != "" && len(paths) > 0:
+		return nil, fmt.Errorf("read from a --metricset or from paths, not both")
+	case metricset != "":
+		config, err := ctrl.GetConfig()
+		if err != nil {
+			return nil, err
+		}
+		clientset, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		logs, err = results.ReadCluster(context.Background(), clientset, namespace, metricset)
+		if err != nil {
+			return nil, err
+		}
+	case len(paths) > 0:
+		logs, err = results.ReadFiles(paths)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("provide a --metricset or paths to saved logs")
+	}
+
+	found := []results.Result{}
+	for _, log := range logs {
+		result, err := results.ParseLog(log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+			continue
+		}
+		found = append(found, result)
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no results were found in %d logs", len(logs))
+	}
+	return found, nil
+}
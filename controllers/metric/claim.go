@@ -0,0 +1,44 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+ SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	mctrl "github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// ensureResultsClaim creates the claim for results, unless the MetricSet uses an
+// existing one. The claim is not owned by the MetricSet, so results are kept when
+// it is deleted (and shared by the next run of the same name).
+func (r *MetricSetReconciler) ensureResultsClaim(
+	ctx context.Context,
+	spec *api.MetricSet,
+) error {
+
+	claim, err := mctrl.GetResultsClaim(spec)
+	if claim == nil || err != nil {
+		return err
+	}
+	existing := &corev1.PersistentVolumeClaim{}
+	err = r.Get(ctx, types.NamespacedName{Name: claim.Name, Namespace: claim.Namespace}, existing)
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+	r.Log.Info("💾️ Creating results PersistentVolumeClaim", "Namespace", claim.Namespace, "Name", claim.Name)
+	err = r.Client.Create(ctx, claim)
+	if err != nil {
+		r.Log.Error(err, "🔴 Create PersistentVolumeClaim", "PersistentVolumeClaim", claim.Name)
+	}
+	return err
+}
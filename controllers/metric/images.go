@@ -0,0 +1,60 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+ SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	mctrl "github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// Images are resolved together, and the reconcile waits at most this long for the registries
+var imageDigestsDeadline = 10 * time.Second
+
+// resolveImages looks up the digests of the metric and addon images before the
+// JobSet is generated, so the metadata records exactly what runs. They are saved
+// in the status, and images that can't be resolved (e.g., private, or a registry
+// that doesn't answer before the deadline) have no digest.
+func (r *MetricSetReconciler) resolveImages(
+	ctx context.Context,
+	spec *api.MetricSet,
+	set *mctrl.MetricSet,
+) {
+	if r.Registry == nil || len(spec.Status.Images) > 0 {
+		return
+	}
+	lookup, cancel := context.WithTimeout(ctx, imageDigestsDeadline)
+	defer cancel()
+
+	images := []api.ImageStatus{}
+	for _, image := range mctrl.Images(set) {
+		images = append(images, api.ImageStatus{Image: image})
+	}
+	var wg sync.WaitGroup
+	for i := range images {
+		wg.Add(1)
+		go func(status *api.ImageStatus) {
+			defer wg.Done()
+			digest, err := r.Registry.Digest(lookup, status.Image)
+			if err != nil {
+				r.Log.Info(fmt.Sprintf("🟨️ Cannot resolve the digest of %s: %s", status.Image, err))
+			}
+			status.Digest = digest
+		}(&images[i])
+	}
+	wg.Wait()
+	spec.Status.Images = images
+	err := r.Status().Update(ctx, spec)
+	if err != nil {
+		r.Log.Info(fmt.Sprintf("🟨️ Cannot save image digests to the status: %s", err))
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	mctrl "github.com/converged-computing/metrics-operator/pkg/metrics"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/perf"
+	"github.com/converged-computing/metrics-operator/pkg/registry"
+)
+
+// TestResolveImagesDeadline checks that a registry that doesn't answer
+// holds the reconcile for the deadline, and not a timeout for each image
+func TestResolveImagesDeadline(t *testing.T) {
+	release := make(chan bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	spec := &api.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+		Spec: api.MetricSetSpec{
+			Pods:        1,
+			ServiceName: "ms",
+			Metrics: []api.Metric{
+				{Name: "perf-sysstat", Image: host + "/one:latest"},
+				{Name: "perf-stream", Image: host + "/two:latest"},
+			},
+		},
+	}
+	spec.Validate()
+	set := mctrl.MetricSet{}
+	for i := range spec.Spec.Metrics {
+		m, err := mctrl.GetMetric(&spec.Spec.Metrics[i], spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set.Add(&m)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := api.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spec).WithStatusSubresource(spec).Build()
+	resolver := registry.NewResolver(time.Minute)
+	resolver.Scheme = "http"
+	r := &MetricSetReconciler{Client: c, Log: ctrl.Log, Registry: resolver}
+
+	deadline := imageDigestsDeadline
+	imageDigestsDeadline = 200 * time.Millisecond
+	defer func() { imageDigestsDeadline = deadline }()
+
+	start := time.Now()
+	r.resolveImages(context.Background(), spec, &set)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected images to resolve within the deadline, took %s", elapsed)
+	}
+	if len(spec.Status.Images) != 2 {
+		t.Fatalf("expected two images in the status, found %v", spec.Status.Images)
+	}
+	for _, image := range spec.Status.Images {
+		if image.Digest != "" {
+			t.Errorf("expected no digest for %s, found %s", image.Image, image.Digest)
+		}
+	}
+}
@@ -33,6 +33,18 @@ func (r *MetricSetReconciler) ensureMetricSet(
 		return result, err
 	}
 
+	// Metrics that ssh between pods or run in Flux need keys before the pods start
+	err = r.ensureSecrets(ctx, spec, set)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Results are saved to a claim, which needs to exist before the pods start
+	err = r.ensureResultsClaim(ctx, spec)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Now create config maps...
 	// The config maps need to exist before the jobsets, etc.
 	_, result, err = r.ensureConfigMaps(ctx, spec, set, cs)
@@ -98,6 +110,12 @@ func (r *MetricSetReconciler) getJobSet(
 			"Name:", spec.Name,
 		)
 
+		// Warn about metrics the pod security level of the namespace won't admit
+		r.warnPodSecurity(ctx, spec)
+
+		// Digests of images are included in the metadata of the entrypoints
+		r.resolveImages(ctx, spec, set)
+
 		// Get one JobSet and container specs to create config maps
 		js, cs, err := mctrl.GetJobSet(spec, set)
 
@@ -10,6 +10,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -17,6 +18,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -24,9 +26,14 @@ import (
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
 	mctrl "github.com/converged-computing/metrics-operator/pkg/metrics"
+	"github.com/converged-computing/metrics-operator/pkg/registry"
+	"github.com/converged-computing/metrics-operator/pkg/results"
 	"github.com/go-logr/logr"
 )
 
+// Seconds to wait before checking for pods held for debugging
+const debugRequeueSeconds = 30
+
 // MetricReconciler reconciles a Metric object
 type MetricSetReconciler struct {
 	client.Client
@@ -34,6 +41,15 @@ type MetricSetReconciler struct {
 	Log        logr.Logger
 	RESTClient rest.Interface
 	RESTConfig *rest.Config
+
+	// Parsed results of completed metrics (not collected when nil)
+	Results *results.Collector
+
+	// Resolves image digests for metadata (not resolved when nil)
+	Registry *registry.Resolver
+
+	// Records events on the MetricSet (e.g., pod security warnings)
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=flux-framework.org,resources=metricsets,verbs=get;list;watch;create;update;patch;delete
@@ -91,6 +107,11 @@ func (r *MetricSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		if errors.IsNotFound(err) {
 			r.Log.Info("🟥️ MetricSet not found. Ignoring since object must be deleted.")
 
+			// Its pods are deleted too, so their containers won't be read again
+			if r.Results != nil {
+				r.Results.Forget(req.Namespace, req.Name, nil)
+			}
+
 			// This should not be necessary, but the config map isn't owned by the operator
 			return ctrl.Result{}, nil
 		}
@@ -104,6 +125,13 @@ func (r *MetricSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
+	// Templates in ConfigMaps are read before the metrics render them
+	err = r.resolveTemplates(ctx, &spec)
+	if err != nil {
+		r.Log.Error(err, "🟥️ Issue reading entrypoint templates")
+		return ctrl.Result{Requeue: true}, err
+	}
+
 	// A MetricSet creates one or more JobSets (right now we just do 1)
 	set := mctrl.MetricSet{}
 	for _, metric := range spec.Spec.Metrics {
@@ -136,6 +164,23 @@ func (r *MetricSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return result, err
 	}
 
+	// Report metrics that timed out or failed, and pods held for debugging
+	requeue, err := r.updateStatus(ctx, &spec)
+	if err != nil {
+		r.Log.Error(err, "🟥️ Issue updating MetricSet status")
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	// Parse results of metrics that completed, to serve them as Prometheus gauges
+	err = r.collectResults(ctx, &spec, &set)
+	if err != nil {
+		r.Log.Error(err, "🟨️ Issue collecting MetricSet results")
+	}
+	if requeue {
+		r.Log.Info("🐛️ MetricSet is in debug mode, checking pods again soon")
+		return ctrl.Result{RequeueAfter: debugRequeueSeconds * time.Second}, nil
+	}
+
 	// By the time we get here we have a Job + pods + config maps!
 	// What else do we want to do?
 	r.Log.Info("🧀️ MetricSet is Ready!")
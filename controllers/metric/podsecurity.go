@@ -0,0 +1,83 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+ SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	mctrl "github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// Namespace labels used by Pod Security Admission
+var (
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	podSecurityWarnLabel    = "pod-security.kubernetes.io/warn"
+)
+
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// warnPodSecurity logs and records an event for each metric that is not compatible
+// with the pod security level of the namespace. The webhook returns the same warnings
+// at admission, but it is only served with --enable-webhooks.
+func (r *MetricSetReconciler) warnPodSecurity(ctx context.Context, spec *api.MetricSet) {
+	for _, warning := range podSecurityWarnings(ctx, r.Client, spec.DeepCopy()) {
+		r.Log.Info(fmt.Sprintf("🟨️ %s", warning))
+		if r.Recorder != nil {
+			r.Recorder.Event(spec, corev1.EventTypeWarning, "PodSecurity", warning)
+		}
+	}
+}
+
+// podSecurityWarnings generates the JobSet for each metric and checks it against
+// the pod security level of the namespace
+func podSecurityWarnings(ctx context.Context, c client.Client, spec *api.MetricSet) []string {
+	warnings := []string{}
+	level, label := getPodSecurityLevel(ctx, c, spec.Namespace)
+	if level == "" || level == mctrl.PodSecurityPrivileged {
+		return warnings
+	}
+
+	// Each metric is assembled on its own so warnings can be attributed
+	for _, metric := range spec.Spec.Metrics {
+		m, err := mctrl.GetMetric(&metric, spec)
+		if err != nil {
+			continue
+		}
+		single := mctrl.MetricSet{}
+		single.Add(&m)
+		js, _, err := mctrl.GetJobSet(spec, &single)
+		if err != nil {
+			continue
+		}
+		for _, violation := range mctrl.CheckPodSecurity(js, level) {
+			warning := fmt.Sprintf("metric %s is not compatible with %s=%s: %s", metric.Name, label, level, violation)
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// getPodSecurityLevel returns the enforced (or warned) level for the namespace
+func getPodSecurityLevel(ctx context.Context, c client.Client, namespace string) (string, string) {
+	ns := &corev1.Namespace{}
+	err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns)
+	if err != nil {
+		return "", ""
+	}
+	level, ok := ns.Labels[podSecurityEnforceLabel]
+	if ok {
+		return level, podSecurityEnforceLabel
+	}
+	return ns.Labels[podSecurityWarnLabel], podSecurityWarnLabel
+}
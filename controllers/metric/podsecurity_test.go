@@ -0,0 +1,81 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/perf"
+)
+
+func TestWarnPodSecurity(t *testing.T) {
+	namespaces := []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "restricted", Labels: map[string]string{podSecurityEnforceLabel: "restricted"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "baseline", Labels: map[string]string{podSecurityWarnLabel: "baseline"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "open"}},
+	}
+	c := fake.NewClientBuilder().WithObjects(namespaces[0], namespaces[1], namespaces[2]).Build()
+
+	// A metric that adds SYS_ADMIN is allowed by neither level
+	tests := []struct {
+		namespace string
+		reason    string
+	}{
+		{namespace: "restricted", reason: "pod-security.kubernetes.io/enforce=restricted"},
+		{namespace: "baseline", reason: "pod-security.kubernetes.io/warn=baseline"},
+		{namespace: "open"},
+	}
+	for _, test := range tests {
+		spec := &api.MetricSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: test.namespace},
+			Spec: api.MetricSetSpec{
+				Pods:        1,
+				ServiceName: "ms",
+				Metrics: []api.Metric{{
+					Name:       "perf-sysstat",
+					Attributes: api.ContainerSpec{SecurityContext: api.SecurityContext{AllowAdmin: true}},
+				}},
+			},
+		}
+		spec.Validate()
+		recorder := record.NewFakeRecorder(100)
+		r := &MetricSetReconciler{Client: c, Log: ctrl.Log, Recorder: recorder}
+		r.warnPodSecurity(context.Background(), spec)
+		close(recorder.Events)
+
+		events := []string{}
+		for event := range recorder.Events {
+			events = append(events, event)
+		}
+		if test.reason == "" {
+			if len(events) != 0 {
+				t.Errorf("%s: expected no events, found %v", test.namespace, events)
+			}
+			continue
+		}
+		found := false
+		for _, event := range events {
+			if !strings.HasPrefix(event, "Warning PodSecurity metric perf-sysstat is not compatible with "+test.reason) {
+				t.Errorf("%s: unexpected event %s", test.namespace, event)
+			}
+			found = found || strings.Contains(event, "adds capability SYS_ADMIN")
+		}
+		if !found {
+			t.Errorf("%s: expected a warning for SYS_ADMIN, found %v", test.namespace, events)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+ SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	mctrl "github.com/converged-computing/metrics-operator/pkg/metrics"
+	"github.com/converged-computing/metrics-operator/pkg/results"
+)
+
+// collectResults parses the logs of metric containers that completed, for
+// metrics with a parser. This includes every pod of every replicated job (not
+// only the success jobs), tagged with the node and pod index, so results can be
+// summarized for each node and the cluster. Each container is read once (while its
+// pod exists, even after its results expire), and when there are new results they
+// are pushed (if a pushgateway is set).
+func (r *MetricSetReconciler) collectResults(
+	ctx context.Context,
+	spec *api.MetricSet,
+	set *mctrl.MetricSet,
+) error {
+	if r.Results == nil || r.RESTClient == nil {
+		return nil
+	}
+	parsers := map[string]mctrl.ResultParser{}
+	for _, metric := range set.Metrics() {
+		parser, ok := mctrl.GetResultParser(*metric)
+		if ok {
+			parsers[(*metric).Name()] = parser
+		}
+	}
+	if len(parsers) == 0 {
+		return nil
+	}
+	options := map[string]string{}
+	for i, metric := range spec.Spec.Metrics {
+		options[metric.Name] = results.Options(&spec.Spec.Metrics[i])
+	}
+
+	pods := &corev1.PodList{}
+	err := r.List(
+		ctx,
+		pods,
+		client.InNamespace(spec.Namespace),
+		client.MatchingLabels{"metricset-name": spec.Name},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Containers of pods that are gone won't be read again
+	containers := []string{}
+	for _, pod := range pods.Items {
+		for _, container := range pod.Status.ContainerStatuses {
+			containers = append(containers, fmt.Sprintf("%s/%s", pod.UID, container.Name))
+		}
+	}
+	r.Results.Forget(spec.Namespace, spec.Name, containers)
+
+	recorded := 0
+	for _, pod := range pods.Items {
+		name := pod.Labels[mctrl.MetricLabel]
+		parser, ok := parsers[name]
+		if !ok {
+			continue
+		}
+		for _, container := range pod.Status.ContainerStatuses {
+			terminated := container.State.Terminated
+			id := fmt.Sprintf("%s/%s", pod.UID, container.Name)
+			if terminated == nil || terminated.ExitCode != 0 || r.Results.Has(spec.Namespace, spec.Name, id) {
+				continue
+			}
+			logs, err := r.RESTClient.Get().
+				Namespace(pod.Namespace).
+				Resource("pods").
+				Name(pod.Name).
+				SubResource("log").
+				Param("container", container.Name).
+				Do(ctx).
+				Raw()
+			if err != nil {
+				r.Log.Info(fmt.Sprintf("🟨️ Cannot read logs for %s/%s: %s", pod.Name, container.Name, err))
+				continue
+			}
+			samples := parser.ParseResults(string(logs))
+			r.Results.Record(results.Result{
+				MetricSet: spec.Name,
+				Namespace: spec.Namespace,
+				Metric:    name,
+				Options:   options[name],
+				Node:      pod.Spec.NodeName,
+				Pod:       pod.Name,
+				Container: id,
+				Samples:   samples,
+
+				ReplicatedJob: pod.Labels[jobset.ReplicatedJobNameKey],
+				PodIndex:      pod.Annotations[batchv1.JobCompletionIndexAnnotation],
+			})
+			r.Log.Info(fmt.Sprintf("📈️ Parsed %d results for metric %s from %s/%s", len(samples), name, pod.Name, container.Name))
+			recorded += len(samples)
+		}
+	}
+	if recorded == 0 {
+		return nil
+	}
+	return r.Results.Push(spec.Namespace, spec.Name)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+ SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	mctrl "github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// ensureSecrets creates the ssh keys for metrics that use ssh between pods,
+// and the curve certificate for metrics that run in a Flux instance.
+// The Secrets are owned by the MetricSet, so they are deleted with it.
+func (r *MetricSetReconciler) ensureSecrets(
+	ctx context.Context,
+	spec *api.MetricSet,
+	set *mctrl.MetricSet,
+) error {
+
+	hosts := mctrl.SSHHosts(spec, set)
+	if len(hosts) > 0 {
+		err := r.ensureSecret(ctx, spec, mctrl.SSHSecretName(spec), func() (map[string][]byte, error) {
+			return mctrl.GenerateSSHKeys(hosts)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if mctrl.UsesFlux(set) {
+		return r.ensureSecret(ctx, spec, mctrl.FluxSecretName(spec), mctrl.GenerateCurveCert)
+	}
+	return nil
+}
+
+// ensureSecret creates a Secret with generated data, if it does not exist
+func (r *MetricSetReconciler) ensureSecret(
+	ctx context.Context,
+	spec *api.MetricSet,
+	name string,
+	generate func() (map[string][]byte, error),
+) error {
+	existing := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: spec.Namespace}, existing)
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+	_, err = r.createSecret(ctx, spec, name, generate)
+	return err
+}
+
+// createSecret generates the data (e.g., new keys) and creates the Secret
+func (r *MetricSetReconciler) createSecret(
+	ctx context.Context,
+	spec *api.MetricSet,
+	name string,
+	generate func() (map[string][]byte, error),
+) (*corev1.Secret, error) {
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: spec.Namespace},
+		Type:       corev1.SecretTypeOpaque,
+	}
+	data, err := generate()
+	if err != nil {
+		r.Log.Error(err, "🔴 Generate Secret data", "Secret", secret.Name)
+		return secret, err
+	}
+	secret.Data = data
+
+	r.Log.Info("🔑️ Creating Secret", "Namespace", secret.Namespace, "Name", secret.Name)
+	ctrl.SetControllerReference(spec, secret, r.Scheme)
+	err = r.Client.Create(ctx, secret)
+	if err != nil {
+		r.Log.Error(err, "🔴 Create Secret", "Secret", secret.Name)
+	}
+	return secret, err
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	mctrl "github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// updateStatus reports metrics with containers that timed out or failed,
+// and pods held for debugging. Holding a container doesn't change the pod,
+// so we ask to check again while a debug MetricSet has running pods.
+func (r *MetricSetReconciler) updateStatus(ctx context.Context, spec *api.MetricSet) (bool, error) {
+	pods := &corev1.PodList{}
+	err := r.List(
+		ctx,
+		pods,
+		client.InNamespace(spec.Namespace),
+		client.MatchingLabels{"metricset-name": spec.Name},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	statuses := metricStatuses(spec, pods.Items)
+	held, running := r.heldPods(ctx, spec, pods.Items)
+	requeue := spec.Spec.Logging.Debug != "" && running
+	if equality.Semantic.DeepEqual(statuses, spec.Status.Metrics) &&
+		equality.Semantic.DeepEqual(held, spec.Status.HeldPods) {
+		return requeue, nil
+	}
+	for _, status := range statuses {
+		r.Log.Info(fmt.Sprintf("🟥️ Metric %s: %s (exit code %d) in pods %v", status.Name, status.Reason, status.ExitCode, status.Pods))
+	}
+	if len(held) > 0 {
+		r.Log.Info(fmt.Sprintf("🐛️ Pods held for debugging: %v", held))
+	}
+	spec.Status.Metrics = statuses
+	spec.Status.HeldPods = held
+	return requeue, r.Status().Update(ctx, spec)
+}
+
+// heldPods finds running metric containers that print the debug hold marker
+// as their last line, and returns if any metric pods are still running.
+func (r *MetricSetReconciler) heldPods(ctx context.Context, spec *api.MetricSet, pods []corev1.Pod) ([]string, bool) {
+	running := false
+	held := []string{}
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if _, ok := pod.Labels[mctrl.MetricLabel]; !ok {
+			continue
+		}
+		running = true
+		if spec.Spec.Logging.Debug == "" || r.RESTClient == nil {
+			continue
+		}
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.State.Running == nil {
+				continue
+			}
+			logs, err := r.RESTClient.Get().
+				Namespace(pod.Namespace).
+				Resource("pods").
+				Name(pod.Name).
+				SubResource("log").
+				Param("container", container.Name).
+				Param("tailLines", "1").
+				Do(ctx).
+				Raw()
+			if err != nil {
+				r.Log.Info(fmt.Sprintf("🟨️ Cannot read logs for %s/%s: %s", pod.Name, container.Name, err))
+				continue
+			}
+			if strings.Contains(string(logs), metadata.DebugHold) {
+				held = append(held, pod.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(held)
+	if len(held) == 0 {
+		return nil, running
+	}
+	return held, running
+}
+
+// metricStatuses groups the pods of each metric by the reason they did not complete
+func metricStatuses(spec *api.MetricSet, pods []corev1.Pod) []api.MetricStatus {
+	lookup := map[string]*api.MetricStatus{}
+	for _, pod := range pods {
+		name, ok := pod.Labels[mctrl.MetricLabel]
+		if !ok {
+			continue
+		}
+		for _, container := range pod.Status.ContainerStatuses {
+			reason, exitCode := terminatedReason(container)
+			if reason == "" {
+				continue
+			}
+			key := name + "/" + reason
+			status, ok := lookup[key]
+			if !ok {
+				status = &api.MetricStatus{Name: name, Reason: reason, ExitCode: exitCode}
+				lookup[key] = status
+			}
+			if len(status.Pods) == 0 || status.Pods[len(status.Pods)-1] != pod.Name {
+				status.Pods = append(status.Pods, pod.Name)
+			}
+		}
+	}
+
+	// Keep the order of the metrics in the spec
+	statuses := []api.MetricStatus{}
+	for _, metric := range spec.Spec.Metrics {
+		for _, reason := range []string{api.MetricReasonTimeout, api.MetricReasonFailed} {
+			status, ok := lookup[metric.Name+"/"+reason]
+			if !ok {
+				continue
+			}
+			sort.Strings(status.Pods)
+			statuses = append(statuses, *status)
+			delete(lookup, metric.Name+"/"+reason)
+		}
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+	return statuses
+}
+
+// terminatedReason returns Timeout or Failed for a container that exited
+// with an error, including a previous run of a restarted container.
+func terminatedReason(container corev1.ContainerStatus) (string, int32) {
+	terminated := container.State.Terminated
+	if terminated == nil || terminated.ExitCode == 0 {
+		terminated = container.LastTerminationState.Terminated
+	}
+	if terminated == nil || terminated.ExitCode == 0 {
+		return "", 0
+	}
+	if terminated.ExitCode == metadata.TimeoutExitCode || terminated.Message == "timeout" {
+		return api.MetricReasonTimeout, terminated.ExitCode
+	}
+	return api.MetricReasonFailed, terminated.ExitCode
+}
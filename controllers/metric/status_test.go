@@ -0,0 +1,103 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	mctrl "github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// terminated returns a container status that exited with a code and message
+func terminated(exitCode int32, message string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Message: message},
+		},
+	}
+}
+
+func TestTerminatedReason(t *testing.T) {
+	restarted := corev1.ContainerStatus{
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		LastTerminationState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{ExitCode: metadata.TimeoutExitCode},
+		},
+	}
+	tests := []struct {
+		name      string
+		container corev1.ContainerStatus
+		reason    string
+		exitCode  int32
+	}{
+		{name: "running", container: corev1.ContainerStatus{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+		{name: "waiting", container: corev1.ContainerStatus{}},
+		{name: "success", container: terminated(0, "")},
+		{name: "failed", container: terminated(2, ""), reason: api.MetricReasonFailed, exitCode: 2},
+		{name: "timeout", container: terminated(metadata.TimeoutExitCode, ""), reason: api.MetricReasonTimeout, exitCode: metadata.TimeoutExitCode},
+		{name: "timeout message", container: terminated(137, "timeout"), reason: api.MetricReasonTimeout, exitCode: 137},
+		{name: "restarted", container: restarted, reason: api.MetricReasonTimeout, exitCode: metadata.TimeoutExitCode},
+	}
+	for _, test := range tests {
+		reason, exitCode := terminatedReason(test.container)
+		if reason != test.reason || exitCode != test.exitCode {
+			t.Errorf("%s: expected %q (%d), found %q (%d)", test.name, test.reason, test.exitCode, reason, exitCode)
+		}
+	}
+}
+
+func TestMetricStatuses(t *testing.T) {
+	spec := &api.MetricSet{
+		Spec: api.MetricSetSpec{
+			Metrics: []api.Metric{{Name: "perf-stream"}, {Name: "network-osu-benchmark"}},
+		},
+	}
+	pod := func(name, metric string, containers ...corev1.ContainerStatus) corev1.Pod {
+		labels := map[string]string{}
+		if metric != "" {
+			labels[mctrl.MetricLabel] = metric
+		}
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status:     corev1.PodStatus{ContainerStatuses: containers},
+		}
+	}
+
+	// No failed containers, or containers of pods that aren't metrics
+	statuses := metricStatuses(spec, []corev1.Pod{
+		pod("ms-m-0-0", "perf-stream", terminated(0, "")),
+		pod("ms-l-0-0", "", terminated(1, "")),
+	})
+	if statuses != nil {
+		t.Errorf("expected no statuses, found %v", statuses)
+	}
+
+	// Statuses are in the order of the spec, with timeout first, and sorted pods
+	pods := []corev1.Pod{
+		pod("ms-w-0-1", "network-osu-benchmark", terminated(1, "")),
+		pod("ms-w-0-0", "network-osu-benchmark", terminated(1, ""), terminated(1, "")),
+		pod("ms-m-0-1", "perf-stream", terminated(3, "")),
+		pod("ms-m-0-0", "perf-stream", terminated(metadata.TimeoutExitCode, "")),
+		pod("ms-l-0-0", "network-osu-benchmark", terminated(0, "")),
+	}
+	expected := []api.MetricStatus{
+		{Name: "perf-stream", Reason: api.MetricReasonTimeout, ExitCode: metadata.TimeoutExitCode, Pods: []string{"ms-m-0-0"}},
+		{Name: "perf-stream", Reason: api.MetricReasonFailed, ExitCode: 3, Pods: []string{"ms-m-0-1"}},
+		{Name: "network-osu-benchmark", Reason: api.MetricReasonFailed, ExitCode: 1, Pods: []string{"ms-w-0-0", "ms-w-0-1"}},
+	}
+	statuses = metricStatuses(spec, pods)
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected statuses %v, found %v", expected, statuses)
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+)
+
+// resolveTemplates reads entrypoint templates that reference a ConfigMap into
+// the MetricSet (in memory only) so they are rendered like inline templates
+func (r *MetricSetReconciler) resolveTemplates(ctx context.Context, spec *api.MetricSet) error {
+	for i := range spec.Spec.Metrics {
+		metric := &spec.Spec.Metrics[i]
+		for j := range metric.Templates {
+			t := &metric.Templates[j]
+			if t.ConfigMap == nil {
+				continue
+			}
+			cm := &corev1.ConfigMap{}
+			err := r.Get(ctx, types.NamespacedName{Name: t.ConfigMap.Name, Namespace: spec.Namespace}, cm)
+			if err != nil {
+				return fmt.Errorf("template for metric %s: %s", metric.Name, err)
+			}
+			template, ok := cm.Data[t.ConfigMap.Key]
+			if !ok {
+				return fmt.Errorf("template for metric %s: ConfigMap %s does not have key %s", metric.Name, t.ConfigMap.Name, t.ConfigMap.Key)
+			}
+			t.Template = template
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+ SPDX-License-Identifier: MIT
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+)
+
+//+kubebuilder:webhook:path=/validate-flux-framework-org-v1alpha2-metricset,mutating=false,failurePolicy=ignore,sideEffects=None,groups=flux-framework.org,resources=metricsets,verbs=create;update,versions=v1alpha2,name=vmetricset.flux-framework.org,admissionReviewVersions=v1
+
+// MetricSetValidator warns when a MetricSet is not compatible with the
+// pod security level of the namespace it is created in
+type MetricSetValidator struct {
+	Client client.Client
+}
+
+// SetupWebhookWithManager registers the validating webhook
+func (v *MetricSetValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&api.MetricSet{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate warns about pod security issues on create
+func (v *MetricSetValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate warns about pod security issues on update
+func (v *MetricSetValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete does not check anything
+func (v *MetricSetValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks each metric against the pod security level of the namespace
+func (v *MetricSetValidator) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	set, ok := obj.(*api.MetricSet)
+	if !ok {
+		return nil, fmt.Errorf("expected a MetricSet but got a %T", obj)
+	}
+
+	// We don't want to change the object being admitted
+	spec := set.DeepCopy()
+	if !spec.Validate() {
+		return nil, nil
+	}
+	warnings := podSecurityWarnings(ctx, v.Client, spec)
+	if len(warnings) == 0 {
+		return nil, nil
+	}
+	return admission.Warnings(warnings), nil
+}
@@ -0,0 +1,66 @@
+#!/bin/bash
+
+# Generate the typed clientset, listers, informers and apply configurations
+# for the MetricSet API under pkg/client. Run with "make client".
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+ROOT=$(cd $(dirname ${BASH_SOURCE[0]})/.. && pwd)
+BIN=${LOCALBIN:-${ROOT}/bin}
+MODULE=github.com/converged-computing/metrics-operator
+OUTPUT=${MODULE}/pkg/client
+HEADER=${ROOT}/hack/boilerplate.go.txt
+
+# The generators take the group from the parent directory of the version, and
+# treat "api" as the legacy core group. We point them at a link named for the group
+# and fix the imports afterwards.
+LINK=${ROOT}/hack/codegen/flux
+APIS=${MODULE}/hack/codegen/flux/v1alpha2
+mkdir -p ${LINK}
+ln -sfn ../../../api/v1alpha2 ${LINK}/v1alpha2
+
+# The generators write into a GOPATH layout, so we generate in a temporary
+# directory and copy the result into the repository
+TMPDIR=$(mktemp -d)
+trap "rm -rf ${TMPDIR} ${ROOT}/hack/codegen" EXIT
+
+echo "Generating apply configurations"
+${BIN}/applyconfiguration-gen \
+  --go-header-file ${HEADER} \
+  --input-dirs ${APIS} \
+  --output-package ${OUTPUT}/applyconfiguration \
+  --output-base ${TMPDIR}
+
+echo "Generating clientset"
+${BIN}/client-gen \
+  --go-header-file ${HEADER} \
+  --clientset-name versioned \
+  --input-base "" \
+  --input ${APIS} \
+  --apply-configuration-package ${OUTPUT}/applyconfiguration \
+  --output-package ${OUTPUT}/clientset \
+  --output-base ${TMPDIR}
+
+echo "Generating listers"
+${BIN}/lister-gen \
+  --go-header-file ${HEADER} \
+  --input-dirs ${APIS} \
+  --output-package ${OUTPUT}/listers \
+  --output-base ${TMPDIR}
+
+echo "Generating informers"
+${BIN}/informer-gen \
+  --go-header-file ${HEADER} \
+  --input-dirs ${APIS} \
+  --versioned-clientset-package ${OUTPUT}/clientset/versioned \
+  --listers-package ${OUTPUT}/listers \
+  --output-package ${OUTPUT}/informers \
+  --output-base ${TMPDIR}
+
+grep -rl ${APIS} ${TMPDIR} | xargs sed -i "s|${APIS}|${MODULE}/api/v1alpha2|g"
+for dir in applyconfiguration clientset informers listers; do
+  rm -rf ${ROOT}/pkg/client/${dir}
+  cp -R ${TMPDIR}/${OUTPUT}/${dir} ${ROOT}/pkg/client/${dir}
+done
@@ -10,6 +10,7 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -25,10 +26,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/api/v1beta1"
 	controllers "github.com/converged-computing/metrics-operator/controllers/metric"
+	"github.com/converged-computing/metrics-operator/pkg/metrics/hosts"
+	"github.com/converged-computing/metrics-operator/pkg/registry"
+	"github.com/converged-computing/metrics-operator/pkg/results"
 
 	// Metrics are registered here! Importing registers once
 	_ "github.com/converged-computing/metrics-operator/pkg/metrics/app"
@@ -49,6 +55,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(api.AddToScheme(scheme))
+	utilruntime.Must(v1beta1.AddToScheme(scheme))
 	utilruntime.Must(jobset.AddToScheme(scheme))
 
 	//+kubebuilder:scaffold:scheme
@@ -58,11 +65,30 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableWebhooks bool
+	var clusterDomain string
+	var resultsRetention time.Duration
+	var resultsPushgateway string
+	var resultsOptionsLabel bool
+	var imageDigests bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the admission webhook that warns about pod security issues, and the v1beta1 conversion webhook. "+
+			"This requires serving certificates (e.g., from cert-manager).")
+	flag.StringVar(&clusterDomain, "cluster-domain", "",
+		"The DNS domain of the cluster for pod hostnames (detected from /etc/resolv.conf when not set, defaulting to cluster.local).")
+	flag.DurationVar(&resultsRetention, "results-retention", results.DefaultRetention,
+		"How long parsed results of completed MetricSets are served on the metrics endpoint (0 keeps them).")
+	flag.BoolVar(&resultsOptionsLabel, "results-options-label", false,
+		"Label parsed results with the options of the metric (each distinct set of options is a new series).")
+	flag.StringVar(&resultsPushgateway, "results-pushgateway", "",
+		"A Prometheus pushgateway URL to push parsed results to, e.g., for short-lived clusters.")
+	flag.BoolVar(&imageDigests, "image-digests", false,
+		"Resolve the digests of metric and addon images in their registries for the metadata (the registries must be reachable).")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -71,6 +97,13 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	// Hostnames of pods use the cluster domain
+	if clusterDomain == "" {
+		clusterDomain = hosts.DetectClusterDomain("/etc/resolv.conf")
+	}
+	hosts.SetClusterDomain(clusterDomain)
+	setupLog.Info("Using cluster domain", "domain", hosts.ClusterDomain())
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -111,6 +144,17 @@ func main() {
 		setupLog.Error(err, "unable to create REST client", "controller", restClient)
 	}
 
+	// Parsed results are served with the controller metrics
+	collector := results.NewCollector(resultsRetention, resultsPushgateway)
+	collector.OptionsLabel = resultsOptionsLabel
+	ctrlmetrics.Registry.MustRegister(collector)
+
+	// Image digests are resolved from registries, when enabled
+	var resolver *registry.Resolver
+	if imageDigests {
+		resolver = registry.NewResolver(5 * time.Second)
+	}
+
 	// Create the new reconciler
 	if err = (&controllers.MetricSetReconciler{
 		Log:        ctrl.Log.WithName("metric-reconciler"),
@@ -118,10 +162,21 @@ func main() {
 		Scheme:     mgr.GetScheme(),
 		RESTConfig: mgr.GetConfig(),
 		RESTClient: restClient,
+		Results:    collector,
+		Registry:   resolver,
+		Recorder:   mgr.GetEventRecorderFor("metrics-operator"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Hyperqueue")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&controllers.MetricSetValidator{
+			Client: mgr.GetClient(),
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MetricSet")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -55,6 +55,11 @@ type Addon interface {
 	Validate() bool
 }
 
+// A MetricAddon is told about the metric it customizes (e.g., to describe its output)
+type MetricAddon interface {
+	SetMetric(*api.Metric)
+}
+
 // Shared based of metadata and functions
 type AddonBase struct {
 	Identifier string
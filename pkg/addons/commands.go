@@ -135,6 +135,11 @@ func (a *CommandAddon) SetSharedCommandOptions(metric *api.MetricAddon) {
 	}
 }
 
+// Exported options
+func (a *CommandAddon) Options() map[string]intstr.IntOrString {
+	return a.DefaultOptions()
+}
+
 // Underlying function that can be shared
 func (a *CommandAddon) DefaultOptions() map[string]intstr.IntOrString {
 	return map[string]intstr.IntOrString{
@@ -13,6 +13,7 @@ import (
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
 	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics/hosts"
 	"github.com/converged-computing/metrics-operator/pkg/specs"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
@@ -38,6 +39,7 @@ type FluxFramework struct {
 	pods      int32
 	jobname   string
 	namespace string
+	hosts     hosts.Generator
 
 	// flux user and id need to match between containers (both are created)
 	fluxUser      string
@@ -58,8 +60,9 @@ type FluxFramework struct {
 	serviceName    string
 	launcherLetter string
 	workerLetter   string
-	workerIndex    string
-	launcherIndex  string
+	workerIndex    int32
+	launcherIndex  int32
+	launcherPods   int32
 }
 
 func (m FluxFramework) Family() string {
@@ -101,12 +104,14 @@ func (a *FluxFramework) SetOptions(metric *api.MetricAddon, set *api.MetricSet)
 	a.jobname = set.Name
 	a.namespace = set.Namespace
 	a.serviceName = set.Spec.ServiceName
+	a.hosts = hosts.NewGenerator(set)
 	a.queuePolicy = "fcfs"
 	a.SpackViewContainer = "flux-framework"
-	a.launcherIndex = "0"
-	a.workerIndex = "0"
+	a.launcherIndex = 0
+	a.workerIndex = 0
 	a.launcherLetter = "l"
 	a.workerLetter = "w"
+	a.launcherPods = 1
 	a.quorum = fmt.Sprintf("%d", a.pods)
 	a.submitCommand = "submit"
 
@@ -116,11 +121,11 @@ func (a *FluxFramework) SetOptions(metric *api.MetricAddon, set *api.MetricSet)
 	}
 	wi, ok := metric.Options["workerIndex"]
 	if ok {
-		a.workerIndex = wi.StrVal
+		a.workerIndex = int32(wi.IntValue())
 	}
 	li, ok := metric.Options["launcherIndex"]
 	if ok {
-		a.launcherIndex = li.StrVal
+		a.launcherIndex = int32(li.IntValue())
 	}
 	mount, ok := metric.Options["mount"]
 	if ok {
@@ -192,15 +197,12 @@ func (a *FluxFramework) SetOptions(metric *api.MetricAddon, set *api.MetricSet)
 	a.setSetup()
 }
 
-// generateRange is a shared function to generate a range string
-func generateRange(size int32, start int32) string {
-	var rangeString string
-	if size == 1 {
-		rangeString = fmt.Sprintf("%d", start)
-	} else {
-		rangeString = fmt.Sprintf("%d-%d", start, (start+size)-1)
+// SetMetric sets the launcher pods of the metric, and the hosts of the setup
+func (a *FluxFramework) SetMetric(metric *api.Metric) {
+	if metric.LauncherPods > 0 {
+		a.launcherPods = metric.LauncherPods
 	}
-	return rangeString
+	a.setSetup()
 }
 
 // setSetup assumes flux installed in the view (/opt/view/bin)) and runs additional setup
@@ -210,11 +212,13 @@ func (a *FluxFramework) setSetup() {
 	// fluxRoot for the view is in /opt/view/lib
 	fluxRoot := "/opt/view"
 
-	// Generate hostlists, this is the lead broker
-	leadBroker := fmt.Sprintf("%s-%s-%s-0", a.jobname, a.launcherLetter, a.launcherIndex)
-	workers := fmt.Sprintf("%s-%s-%s-[%s]", a.jobname, a.workerLetter, a.workerIndex, generateRange(a.pods-1, 0))
-	hosts := fmt.Sprintf("%s,%s", leadBroker, workers)
-	fqdn := fmt.Sprintf("%s.%s.svc.cluster.local", a.serviceName, a.namespace)
+	// Generate hostlists, the first launcher pod is the lead broker
+	leadBroker := a.hosts.Hostname(a.launcherLetter, a.launcherIndex, 0)
+	hosts := a.hosts.HostRange(a.launcherLetter, a.launcherIndex, a.launcherPods)
+	if a.pods > a.launcherPods {
+		hosts += "," + a.hosts.HostRange(a.workerLetter, a.workerIndex, a.pods-a.launcherPods)
+	}
+	fqdn := a.hosts.Domain()
 
 	// These shouldn't be formatted in block
 	defaultBind := "tcp://eth0:%p"
@@ -356,15 +360,16 @@ func (a *FluxFramework) Options() map[string]intstr.IntOrString {
 	options["fluxUid"] = intstr.FromString(a.fluxUid)
 	options["fluxUid"] = intstr.FromString(a.fluxUid)
 	options["pods"] = intstr.FromInt(int(a.pods))
+	options["launcherPods"] = intstr.FromInt(int(a.launcherPods))
 	options["connectTimeout"] = intstr.FromString(a.connectTimeout)
 	options["logLevel"] = intstr.FromString(a.logLevel)
 	options["jobname"] = intstr.FromString(a.jobname)
 	options["namespace"] = intstr.FromString(a.namespace)
 	options["serviceName"] = intstr.FromString(a.serviceName)
 	options["queuePolicy"] = intstr.FromString(a.queuePolicy)
-	options["launcherIndex"] = intstr.FromString(a.launcherIndex)
+	options["launcherIndex"] = intstr.FromInt(int(a.launcherIndex))
 	options["launcherLetter"] = intstr.FromString(a.launcherLetter)
-	options["workerIndex"] = intstr.FromString(a.workerIndex)
+	options["workerIndex"] = intstr.FromInt(int(a.workerIndex))
 	options["workerLetter"] = intstr.FromString(a.workerLetter)
 	options["submitCommand"] = intstr.FromString(a.submitCommand)
 	return options
@@ -407,7 +412,7 @@ func (a *FluxFramework) customizeEntrypoint(
 
 	// This assumes a certain launcher letter for now
 	// TODO allow to customize letter
-	leadBroker := fmt.Sprintf("%s-%s-%s-0", a.jobname, a.launcherLetter, a.launcherIndex)
+	leadBroker := a.hosts.Hostname(a.launcherLetter, a.launcherIndex, 0)
 
 	// Watch only works with submit
 	watch := ""
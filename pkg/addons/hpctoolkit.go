@@ -9,7 +9,6 @@ package addons
 
 import (
 	"fmt"
-	"strings"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
 	"github.com/converged-computing/metrics-operator/pkg/metadata"
@@ -284,13 +283,7 @@ cd ${workdir}
 
 // update a post command to not end in sleep
 func deriveUpdatedPost(post string) (bool, string) {
-	if strings.HasSuffix(post, "sleep infinity\n") {
-		updated := strings.Split(post, "\n")
-		// This is actually two lines
-		updated = updated[:len(updated)-2]
-		return true, strings.Join(updated, "\n")
-	}
-	return false, post
+	return metadata.SplitInteractive(post)
 }
 
 func init() {
@@ -27,6 +27,8 @@ var (
 func Metadata(a Addon) string {
 
 	export := metadata.MetricExport{
+		Version:           metadata.Version,
+		OperatorVersion:   metadata.OperatorVersion,
 		MetricName:        a.Name(),
 		MetricDescription: a.Description(),
 		MetricOptions:     a.Options(),
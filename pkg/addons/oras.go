@@ -0,0 +1,247 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package addons
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+	"github.com/converged-computing/metrics-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+)
+
+const (
+	orasIdentifier    = "output-oras"
+	orasVolumeName    = "metrics-operator-oras"
+	orasMountPath     = "/metrics_operator_oras"
+	orasAuthName      = "metrics-operator-oras-auth"
+	orasAuthMountPath = "/metrics_operator_oras_auth"
+	orasStageDir      = "/tmp/metrics-operator-oras"
+
+	// Media types and annotations of the artifacts
+	orasMediaTypePrefix  = "application/vnd.converged-computing.metrics-operator"
+	orasAnnotationPrefix = "org.converged-computing.metrics-operator"
+)
+
+// OrasOutput pushes the output of metric containers (with the metric metadata,
+// and files from addon volumes) as an OCI artifact to a registry after the
+// collection ends. An init container copies oras into a shared volume, so the
+// metric container doesn't need it.
+type OrasOutput struct {
+	OutputBase
+
+	// The repository (e.g., registry:5000/metrics) and tag for artifacts
+	registry string
+	tag      string
+
+	// The image with oras at /bin/oras
+	image string
+
+	// An optional secret with a docker config for authentication
+	secretName string
+	plainHTTP  bool
+}
+
+// Validate we have a registry
+func (a *OrasOutput) Validate() bool {
+	if a.registry == "" {
+		logger.Error("🟥️ The output-oras addon requires a 'registry' (repository) to push to.")
+		return false
+	}
+	return true
+}
+
+// Set custom options / attributes for the addon
+func (a *OrasOutput) SetOptions(addon *api.MetricAddon, set *api.MetricSet) {
+	a.Identifier = orasIdentifier
+	a.image = "ghcr.io/oras-project/oras:v1.1.0"
+	a.tag = set.Name
+	a.SetOutputOptions(addon, set)
+
+	registry, ok := addon.Options["registry"]
+	if ok {
+		a.registry = strings.TrimSuffix(registry.StrVal, "/")
+	}
+	tag, ok := addon.Options["tag"]
+	if ok {
+		a.tag = tag.StrVal
+	}
+	image, ok := addon.Options["image"]
+	if ok {
+		a.image = image.StrVal
+	}
+	secretName, ok := addon.Options["secretName"]
+	if ok {
+		a.secretName = secretName.StrVal
+	}
+	plainHTTP, ok := addon.Options["plainHttp"]
+	if ok && (plainHTTP.StrVal == "true" || plainHTTP.StrVal == "yes") {
+		a.plainHTTP = true
+	}
+}
+
+// Exported options and list options
+func (a *OrasOutput) Options() map[string]intstr.IntOrString {
+	options := a.OutputOptions()
+	delete(options, "prefix")
+	options["registry"] = intstr.FromString(a.registry)
+	options["tag"] = intstr.FromString(a.tag)
+	options["image"] = intstr.FromString(a.image)
+	options["secretName"] = intstr.FromString(a.secretName)
+	options["plainHttp"] = intstr.FromString(fmt.Sprintf("%t", a.plainHTTP))
+	return options
+}
+
+// AssembleContainers adds an init container to copy oras to the shared volume
+func (a *OrasOutput) AssembleContainers() []specs.ContainerSpec {
+	return []specs.ContainerSpec{{
+		Image:         a.image,
+		Name:          "oras",
+		InitContainer: true,
+		Command:       []string{"cp", "/bin/oras", orasMountPath + "/oras"},
+		Resources:     &api.ContainerResources{},
+		Attributes:    &api.ContainerSpec{},
+	}}
+}
+
+// AssembleVolumes provides the shared volume for oras, and the docker config
+func (a *OrasOutput) AssembleVolumes() []specs.VolumeSpec {
+	volumes := []specs.VolumeSpec{{
+		Volume: corev1.Volume{
+			Name:         orasVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+		Path:  orasMountPath,
+		Mount: true,
+	}}
+	if a.secretName != "" {
+		volumes = append(volumes, specs.VolumeSpec{
+			Volume: corev1.Volume{
+				Name: orasAuthName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: a.secretName},
+				},
+			},
+			Path:     orasAuthMountPath,
+			ReadOnly: true,
+			Mount:    true,
+		})
+	}
+	return volumes
+}
+
+// CustomizeEntrypoints adds the push after the collection ends
+func (a *OrasOutput) CustomizeEntrypoints(
+	cs []*specs.ContainerSpec,
+	rjs []*jobset.ReplicatedJob,
+) {
+	a.customizeOutput(cs, rjs, a.pushScript)
+}
+
+// mediaType is specific to the metric, e.g.,
+// application/vnd.converged-computing.metrics-operator.app-lammps.output.v1+text
+func (a *OrasOutput) mediaType(kind string) string {
+	metric := a.metric
+	if metric == "" {
+		metric = "metric"
+	}
+	return fmt.Sprintf("%s.%s.%s", orasMediaTypePrefix, metric, kind)
+}
+
+// pushScript stages the output, metadata, and paths of one container,
+// and pushes them with annotations to describe the metric
+func (a *OrasOutput) pushScript(container string, images []string) string {
+	meta := Metadata(a)
+
+	flags := []string{}
+	if a.plainHTTP {
+		flags = append(flags, "--plain-http")
+	}
+	if a.secretName != "" {
+		flags = append(flags, "--registry-config", orasAuthMountPath+"/"+corev1.DockerConfigJsonKey)
+	}
+	annotations := ""
+	for _, annotation := range [][]string{
+		{"metric", a.metric},
+		{"options", a.options},
+		{"pods", fmt.Sprintf("%d", a.pods)},
+		{"metricset", a.metricset},
+		{"uid", a.uid},
+		{"container", container},
+	} {
+		annotations += fmt.Sprintf(" \\\n  --annotation %s", utils.ShellQuote(orasAnnotationPrefix+"."+annotation[0]+"="+annotation[1]))
+	}
+	quoted := []string{}
+	for _, image := range images {
+		quoted = append(quoted, utils.ShellQuote(image))
+	}
+
+	template := `
+echo "%[1]s"
+# Push output as an OCI artifact to %[2]s:%[3]s-<pod>-%[4]s
+metrics_operator_oras_reference="%[2]s:%[3]s-${HOSTNAME%%%%.*}-%[4]s"
+rm -rf %[5]s
+mkdir -p %[5]s
+metrics_operator_oras_stage() {
+	mkdir -p "$(dirname "%[5]s/${2}")"
+	cp "${1}" "%[5]s/${2}"
+}
+%[6]s
+sed -n 's/^METADATA START //p' %[5]s/output.txt | head -n 1 > %[5]s/metadata.json
+
+# Image digests are resolved from the registry when possible
+metrics_operator_oras_images=""
+for image in %[7]s; do
+	digest=$(%[8]s/oras manifest fetch --descriptor ${image} 2>/dev/null | sed -n 's/.*"digest":"\([^"]*\)".*/\1/p')
+	metrics_operator_oras_images="${metrics_operator_oras_images}${metrics_operator_oras_images:+,}${image%%%%@*}${digest:+@${digest}}"
+done
+metrics_operator_oras_layers="output.txt:%[9]s metadata.json:%[10]s"
+if [[ -d %[5]s/files ]]; then
+	metrics_operator_oras_layers="${metrics_operator_oras_layers} files:%[11]s"
+fi
+cd %[5]s
+%[8]s/oras push %[12]s "${metrics_operator_oras_reference}" \
+  --artifact-type %[13]s%[14]s \
+  --annotation "%[15]s.pod=${HOSTNAME%%%%.*}" \
+  --annotation "%[15]s.images=${metrics_operator_oras_images}" \
+  ${metrics_operator_oras_layers} || echo "🟥️ Failed to push output to ${metrics_operator_oras_reference}"
+cd - > /dev/null
+`
+	return fmt.Sprintf(
+		template,
+		meta,
+		a.registry,
+		a.tag,
+		container,
+		orasStageDir,
+		saveOutputs("metrics_operator_oras_stage", a.outputPaths()),
+		strings.Join(quoted, " "),
+		orasMountPath,
+		a.mediaType("output.v1+text"),
+		orasMediaTypePrefix+".metadata.v1+json",
+		a.mediaType("files.v1.tar+gzip"),
+		strings.Join(flags, " "),
+		a.mediaType("v1"),
+		annotations,
+		orasAnnotationPrefix,
+	)
+}
+
+func init() {
+	base := AddonBase{
+		Identifier: orasIdentifier,
+		Summary:    "push metric output and files to an OCI registry as an artifact",
+	}
+	output := OutputBase{AddonBase: base}
+	oras := OrasOutput{OutputBase: output}
+	Register(&oras)
+}
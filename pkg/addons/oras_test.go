@@ -0,0 +1,124 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package addons
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+)
+
+// A stand-in for oras that records the push (arguments and staged files)
+var fakeOras = `#!/bin/bash
+if [[ "${1}" == "manifest" ]]; then
+	echo '{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:abcd","size":1}'
+	exit 0
+fi
+printf '%s\n' "$@" > "$(dirname ${0})/args.txt"
+find . -type f | sort > "$(dirname ${0})/files.txt"
+cp metadata.json "$(dirname ${0})/metadata.json"
+`
+
+// TestOrasOutput runs the push with a stand-in for oras
+func TestOrasOutput(t *testing.T) {
+	tmp := t.TempDir()
+	data := filepath.Join(tmp, "data")
+	for path, content := range map[string]string{
+		filepath.Join(tmp, "oras"):                 fakeOras,
+		filepath.Join(data, "trace", "rank-0.txt"): "trace",
+	} {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(content), 0755)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	set := &api.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default", UID: "1234"},
+		Spec:       api.MetricSetSpec{Pods: 2},
+	}
+	addon, err := GetAddon(&api.MetricAddon{
+		Name: orasIdentifier,
+		Options: map[string]intstr.IntOrString{
+			"registry":  intstr.FromString("registry:5000/metrics/"),
+			"plainHttp": intstr.FromString("true"),
+		},
+		ListOptions: map[string][]intstr.IntOrString{"paths": {intstr.FromString(filepath.Join(data, "trace"))}},
+	}, set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addon.(MetricAddon).SetMetric(&api.Metric{
+		Name:    "network-osu-benchmark",
+		Options: map[string]intstr.IntOrString{"tasks": intstr.FromInt(2)},
+	})
+	cs := &specs.ContainerSpec{
+		JobName: "l",
+		Name:    "launcher",
+		Image:   "ghcr.io/converged-computing/metric-osu-benchmark:latest",
+		EntrypointScript: specs.EntrypointScript{
+			Pre:     "echo " + metadata.CollectionStart,
+			Command: `echo 'METADATA START {"metricName":"network-osu-benchmark"}'`,
+			Post:    "echo " + metadata.CollectionEnd,
+		},
+	}
+	rj := &jobset.ReplicatedJob{Name: "l"}
+	addon.CustomizeEntrypoints([]*specs.ContainerSpec{cs}, []*jobset.ReplicatedJob{rj})
+
+	script := strings.ReplaceAll(cs.EntrypointScript.WriteScript(), orasMountPath, tmp)
+	script = strings.ReplaceAll(script, outputFile, filepath.Join(tmp, "output.txt"))
+	script = strings.ReplaceAll(script, orasStageDir, filepath.Join(tmp, "stage"))
+	command := exec.Command("bash", "-c", script)
+	command.Env = append(os.Environ(), "HOSTNAME=ms-l-0-0.ms.default.svc.cluster.local")
+	out, err := command.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+
+	args, err := os.ReadFile(filepath.Join(tmp, "args.txt"))
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	for _, expected := range []string{
+		"push\n--plain-http\nregistry:5000/metrics:ms-ms-l-0-0-launcher\n",
+		"--artifact-type\napplication/vnd.converged-computing.metrics-operator.network-osu-benchmark.v1\n",
+		"org.converged-computing.metrics-operator.metric=network-osu-benchmark\n",
+		"org.converged-computing.metrics-operator.options=tasks=2\n",
+		"org.converged-computing.metrics-operator.pods=2\n",
+		"org.converged-computing.metrics-operator.pod=ms-l-0-0\n",
+		"org.converged-computing.metrics-operator.images=ghcr.io/converged-computing/metric-osu-benchmark:latest@sha256:abcd\n",
+		"output.txt:application/vnd.converged-computing.metrics-operator.network-osu-benchmark.output.v1+text\n",
+		"metadata.json:application/vnd.converged-computing.metrics-operator.metadata.v1+json\n",
+		"files:application/vnd.converged-computing.metrics-operator.network-osu-benchmark.files.v1.tar+gzip\n",
+	} {
+		if !strings.Contains(string(args), expected) {
+			t.Errorf("expected %q in the arguments of oras push:\n%s", expected, args)
+		}
+	}
+	files, _ := os.ReadFile(filepath.Join(tmp, "files.txt"))
+	if string(files) != "./files/trace/rank-0.txt\n./metadata.json\n./output.txt\n" {
+		t.Errorf("unexpected staged files:\n%s", files)
+	}
+	meta, _ := os.ReadFile(filepath.Join(tmp, "metadata.json"))
+	if strings.TrimSpace(string(meta)) != `{"metricName":"network-osu-benchmark"}` {
+		t.Errorf("unexpected metadata.json %q", meta)
+	}
+}
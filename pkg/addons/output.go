@@ -0,0 +1,220 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package addons
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+	"github.com/converged-computing/metrics-operator/pkg/utils"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+)
+
+var (
+	AddonFamilyOutput = "output"
+
+	// The output of a metric container is saved here for output addons
+	outputFile = "/tmp/metrics-operator-output.txt"
+)
+
+// OutputBase is shared by addons that save the output of metric containers
+// (and files from addon volumes) after the collection ends. Objects are named
+// <prefix>/<metricset>/<uid>/<pod>/<container>/, where the pod is the hostname.
+type OutputBase struct {
+	AddonBase
+
+	// Paths (files or directories) to save in addition to the output
+	paths []string
+
+	// Optional prefix for names
+	prefix string
+
+	// The MetricSet and metric the output belongs to
+	metricset string
+	uid       string
+	pods      int32
+	metric    string
+	options   string
+
+	// job name and container name targets
+	target          string
+	containerTarget string
+}
+
+func (a OutputBase) Family() string {
+	return AddonFamilyOutput
+}
+
+// SetOutputOptions sets the options shared by output addons
+func (a *OutputBase) SetOutputOptions(addon *api.MetricAddon, set *api.MetricSet) {
+	a.metricset = set.Name
+	a.uid = string(set.UID)
+	a.pods = set.Spec.Pods
+	a.paths = []string{}
+
+	prefix, ok := addon.Options["prefix"]
+	if ok {
+		a.prefix = strings.Trim(prefix.StrVal, "/")
+	}
+	target, ok := addon.Options["target"]
+	if ok {
+		a.target = target.StrVal
+	}
+	ctarget, ok := addon.Options["containerTarget"]
+	if ok {
+		a.containerTarget = ctarget.StrVal
+	}
+	paths, ok := addon.ListOptions["paths"]
+	if ok {
+		for _, p := range paths {
+			a.paths = append(a.paths, p.StrVal)
+		}
+	}
+}
+
+// SetMetric saves the name and options of the metric, to describe the output
+func (a *OutputBase) SetMetric(metric *api.Metric) {
+	a.metric = metric.Name
+	a.options = metadata.FormatOptions(metric.Options, metric.ListOptions)
+}
+
+// OutputOptions are exported options shared by output addons
+func (a *OutputBase) OutputOptions() map[string]intstr.IntOrString {
+	return map[string]intstr.IntOrString{
+		"prefix":          intstr.FromString(a.prefix),
+		"target":          intstr.FromString(a.target),
+		"containerTarget": intstr.FromString(a.containerTarget),
+	}
+}
+
+func (a *OutputBase) ListOptions() map[string][]intstr.IntOrString {
+	paths := []intstr.IntOrString{}
+	for _, p := range a.paths {
+		paths = append(paths, intstr.FromString(p))
+	}
+	return map[string][]intstr.IntOrString{
+		"paths": paths,
+	}
+}
+
+// outputName is the shell expression of the name for a container's output,
+// without a trailing slash. The pod is known at runtime.
+func (a *OutputBase) outputName(container string) string {
+	uid := a.uid
+	if uid == "" {
+		uid = "unknown"
+	}
+	return path.Join(a.prefix, a.metricset, uid) + "/${HOSTNAME%%.*}/" + container
+}
+
+// outputPaths are the paths to save, quoted for the shell
+func (a *OutputBase) outputPaths() string {
+	paths := []string{}
+	for _, p := range a.paths {
+		paths = append(paths, utils.ShellQuote(p))
+	}
+	return strings.Join(paths, " ")
+}
+
+// customizeOutput saves the output of the targeted metric containers, and adds
+// a block after the post block (the end of the collection) to save it. The block
+// is generated for each container name, with the images of the metric containers
+// of the same replicated job.
+func (a *OutputBase) customizeOutput(
+	cs []*specs.ContainerSpec,
+	rjs []*jobset.ReplicatedJob,
+	block func(container string, images []string) string,
+) {
+	for _, rj := range rjs {
+
+		// Only customize if the replicated job name matches the target
+		if a.target != "" && a.target != rj.Name {
+			continue
+		}
+		images := []string{}
+		for _, containerSpec := range cs {
+			if containerSpec.JobName == rj.Name && containerSpec.Image != "" {
+				images = append(images, containerSpec.Image)
+			}
+		}
+		for _, containerSpec := range cs {
+
+			// Containers with a command (e.g., sidecars) don't use the entrypoint
+			if containerSpec.JobName != rj.Name || len(containerSpec.Command) > 0 {
+				continue
+			}
+			if a.containerTarget != "" && containerSpec.Name != "" && a.containerTarget != containerSpec.Name {
+				continue
+			}
+			containerSpec.EntrypointScript.OutputFile = outputFile
+
+			// If the post command ends with sleep infinity, tweak it
+			isInteractive, updatedPost := deriveUpdatedPost(containerSpec.EntrypointScript.Post)
+			containerSpec.EntrypointScript.Post = updatedPost + "\n" + block(containerSpec.Name, images)
+
+			// If is interactive, add back sleep infinity
+			if isInteractive {
+				containerSpec.EntrypointScript.Post += "\nsleep infinity\n"
+			}
+		}
+	}
+}
+
+// outputManifest writes a shell snippet that defines a function to add an
+// object (file and name) to a manifest, and a function to write the manifest
+// as json for a container.
+func (a *OutputBase) outputManifest(manifest string) string {
+	template := `metrics_operator_objects=""
+metrics_operator_add_object() {
+	local size=$(stat -c %%s "${1}")
+	local sha=$(sha256sum "${1}" | awk '{ print $1 }')
+	metrics_operator_objects="${metrics_operator_objects}${metrics_operator_objects:+,}
+    {\"name\": \"${2}\", \"size\": ${size}, \"sha256\": \"${sha}\"}"
+}
+metrics_operator_write_manifest() {
+	cat <<EOT > %[1]s
+{
+  "metricset": "%[2]s",
+  "uid": "%[3]s",
+  "pod": "${HOSTNAME%%%%.*}",
+  "container": "${1}",
+  "objects": [${metrics_operator_objects}
+  ]
+}
+EOT
+}
+`
+	return fmt.Sprintf(template, manifest, a.metricset, a.uid)
+}
+
+// saveOutputs writes a shell snippet that flushes the output, and calls a save
+// function (with the file and relative name) for it and each file under the paths.
+// Directories keep their structure under their base name.
+func saveOutputs(save, paths string) string {
+	template := `
+metrics_operator_flush
+%[1]s %[2]s output.txt
+for path in %[3]s; do
+	if [[ -d "${path}" ]]; then
+		while IFS= read -r file; do
+			%[1]s "${file}" "files/$(basename ${path})/${file#${path}/}"
+		done < <(find "${path}" -type f)
+	elif [[ -f "${path}" ]]; then
+		%[1]s "${path}" "files/$(basename ${path})"
+	else
+		echo "🟨️ Output path ${path} does not exist, skipping it"
+	fi
+done
+`
+	return fmt.Sprintf(template, save, outputFile, paths)
+}
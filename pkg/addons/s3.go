@@ -0,0 +1,182 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package addons
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+)
+
+const (
+	s3Identifier = "output-s3"
+	s3VolumeName = "metrics-operator-s3"
+	s3MountPath  = "/metrics_operator_s3"
+)
+
+// S3Output uploads the output of metric containers (and files from addon
+// volumes) to a bucket of S3 compatible storage (e.g., AWS, MinIO) after the
+// collection ends. Uploads use curl with AWS signatures (curl 7.75 or later).
+type S3Output struct {
+	OutputBase
+
+	// Endpoint URL (e.g., http://minio.default.svc:9000) and bucket
+	endpoint string
+	bucket   string
+	region   string
+
+	// An existing secret with credentials, and the keys for them
+	secretName      string
+	accessKeyID     string
+	secretAccessKey string
+}
+
+// Validate we have the storage and the credentials
+func (a *S3Output) Validate() bool {
+	if a.endpoint == "" || a.bucket == "" {
+		logger.Error("🟥️ The output-s3 addon requires an 'endpoint' and a 'bucket'.")
+		return false
+	}
+	if a.secretName == "" {
+		logger.Error("🟥️ The output-s3 addon requires a 'secretName' for an existing secret with credentials.")
+		return false
+	}
+	return true
+}
+
+// Set custom options / attributes for the addon
+func (a *S3Output) SetOptions(addon *api.MetricAddon, set *api.MetricSet) {
+	a.Identifier = s3Identifier
+	a.region = "us-east-1"
+	a.accessKeyID = "AWS_ACCESS_KEY_ID"
+	a.secretAccessKey = "AWS_SECRET_ACCESS_KEY"
+	a.SetOutputOptions(addon, set)
+
+	endpoint, ok := addon.Options["endpoint"]
+	if ok {
+		a.endpoint = strings.TrimSuffix(endpoint.StrVal, "/")
+	}
+	bucket, ok := addon.Options["bucket"]
+	if ok {
+		a.bucket = bucket.StrVal
+	}
+	region, ok := addon.Options["region"]
+	if ok {
+		a.region = region.StrVal
+	}
+	secretName, ok := addon.Options["secretName"]
+	if ok {
+		a.secretName = secretName.StrVal
+	}
+	accessKeyID, ok := addon.Options["accessKeyIdKey"]
+	if ok {
+		a.accessKeyID = accessKeyID.StrVal
+	}
+	secretAccessKey, ok := addon.Options["secretAccessKeyKey"]
+	if ok {
+		a.secretAccessKey = secretAccessKey.StrVal
+	}
+}
+
+// Exported options and list options
+func (a *S3Output) Options() map[string]intstr.IntOrString {
+	options := a.OutputOptions()
+	options["endpoint"] = intstr.FromString(a.endpoint)
+	options["bucket"] = intstr.FromString(a.bucket)
+	options["region"] = intstr.FromString(a.region)
+	options["secretName"] = intstr.FromString(a.secretName)
+	options["accessKeyIdKey"] = intstr.FromString(a.accessKeyID)
+	options["secretAccessKeyKey"] = intstr.FromString(a.secretAccessKey)
+	return options
+}
+
+// AssembleVolumes mounts the secret with credentials (read only)
+func (a *S3Output) AssembleVolumes() []specs.VolumeSpec {
+	volume := corev1.Volume{
+		Name: s3VolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: a.secretName,
+			},
+		},
+	}
+	return []specs.VolumeSpec{{
+		Volume:   volume,
+		Path:     s3MountPath,
+		ReadOnly: true,
+		Mount:    true,
+	}}
+}
+
+// CustomizeEntrypoints adds the upload after the collection ends
+func (a *S3Output) CustomizeEntrypoints(
+	cs []*specs.ContainerSpec,
+	rjs []*jobset.ReplicatedJob,
+) {
+	a.customizeOutput(cs, rjs, a.uploadScript)
+}
+
+// uploadScript uploads the output and paths of one container, and then a
+// manifest.json with the objects that were uploaded
+func (a *S3Output) uploadScript(container string, images []string) string {
+	meta := Metadata(a)
+	template := `
+echo "%[1]s"
+# Upload output to s3 compatible storage at %[2]s/%[3]s/%[4]s/
+# Credentials are given to curl in a config on stdin, so they aren't in the process list
+metrics_operator_s3_config="$(cat %[5]s/%[6]s):$(cat %[5]s/%[7]s)"
+metrics_operator_s3_config="${metrics_operator_s3_config//\\/\\\\}"
+metrics_operator_s3_config="user = \"${metrics_operator_s3_config//\"/\\\"}\""
+metrics_operator_s3_upload() {
+	local name="%[4]s/${2}"
+	if ! curl -sS --fail --aws-sigv4 "aws:amz:%[8]s:s3" -K - -T "${1}" "%[2]s/%[3]s/${name}" <<< "${metrics_operator_s3_config}"; then
+		echo "🟥️ Failed to upload ${1} to %[3]s/${name}"
+		return 1
+	fi
+	echo "Uploaded ${1} to %[3]s/${name}"
+	metrics_operator_add_object "${1}" "${name}"
+}
+%[9]s
+if command -v curl > /dev/null; then
+%[10]s
+metrics_operator_write_manifest %[11]s
+metrics_operator_s3_upload /tmp/metrics-operator-manifest.json manifest.json
+else
+	echo "🟥️ The output-s3 addon requires curl in the metric container"
+fi
+`
+	return fmt.Sprintf(
+		template,
+		meta,
+		a.endpoint,
+		a.bucket,
+		a.outputName(container),
+		s3MountPath,
+		a.accessKeyID,
+		a.secretAccessKey,
+		a.region,
+		a.outputManifest("/tmp/metrics-operator-manifest.json"),
+		saveOutputs("metrics_operator_s3_upload", a.outputPaths()),
+		container,
+	)
+}
+
+func init() {
+	base := AddonBase{
+		Identifier: s3Identifier,
+		Summary:    "upload metric output and files to S3 compatible storage",
+	}
+	output := OutputBase{AddonBase: base}
+	s3 := S3Output{OutputBase: output}
+	Register(&s3)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package addons
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+)
+
+// TestS3Output runs the upload against a stand-in for S3 (e.g., MinIO)
+func TestS3Output(t *testing.T) {
+	if exec.Command("curl", "--help", "all").Run() != nil {
+		t.Skip("curl is not available")
+	}
+	objects := map[string]string{}
+	var mutex sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPut || !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mutex.Lock()
+		objects[r.URL.Path] = string(body)
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	// Credentials and an output directory of an addon volume
+	tmp := t.TempDir()
+	credentials := filepath.Join(tmp, "credentials")
+	data := filepath.Join(tmp, "data")
+	for path, content := range map[string]string{
+		filepath.Join(credentials, "AWS_ACCESS_KEY_ID"):     "minio",
+		filepath.Join(credentials, "AWS_SECRET_ACCESS_KEY"): `minio"1\23`,
+		filepath.Join(data, "trace", "rank-0.txt"):          "trace",
+	} {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	set := &api.MetricSet{ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default", UID: "1234"}}
+	addon, err := GetAddon(&api.MetricAddon{
+		Name: s3Identifier,
+		Options: map[string]intstr.IntOrString{
+			"endpoint":   intstr.FromString(server.URL),
+			"bucket":     intstr.FromString("results"),
+			"secretName": intstr.FromString("minio-credentials"),
+		},
+		ListOptions: map[string][]intstr.IntOrString{"paths": {intstr.FromString(filepath.Join(data, "trace"))}},
+	}, set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := &specs.ContainerSpec{
+		JobName: "l",
+		Name:    "launcher",
+		EntrypointScript: specs.EntrypointScript{
+			Pre:     "echo " + metadata.CollectionStart,
+			Command: "echo hello",
+			Post:    "echo " + metadata.CollectionEnd,
+		},
+	}
+	rj := &jobset.ReplicatedJob{Name: "l"}
+	addon.CustomizeEntrypoints([]*specs.ContainerSpec{cs}, []*jobset.ReplicatedJob{rj})
+
+	script := strings.ReplaceAll(cs.EntrypointScript.WriteScript(), s3MountPath, credentials)
+	if strings.Contains(script, "--user") {
+		t.Errorf("expected credentials to not be given on the command line:\n%s", script)
+	}
+	script = strings.ReplaceAll(script, outputFile, filepath.Join(tmp, "output.txt"))
+	script = strings.ReplaceAll(script, "/tmp/metrics-operator-manifest.json", filepath.Join(tmp, "manifest.json"))
+	command := exec.Command("bash", "-c", script)
+	command.Env = append(os.Environ(), "HOSTNAME=ms-l-0-0.ms.default.svc.cluster.local")
+	out, err := command.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+
+	base := "/results/ms/1234/ms-l-0-0/launcher/"
+	if !strings.Contains(objects[base+"output.txt"], "hello") {
+		t.Errorf("expected the output to be uploaded, found %v\n%s", objects, out)
+	}
+	if objects[base+"files/trace/rank-0.txt"] != "trace" {
+		t.Errorf("expected the trace to be uploaded, found %v", objects)
+	}
+	manifest := struct {
+		MetricSet string `json:"metricset"`
+		Pod       string `json:"pod"`
+		Container string `json:"container"`
+		Objects   []struct {
+			Name string `json:"name"`
+			Size int    `json:"size"`
+		} `json:"objects"`
+	}{}
+	err = json.Unmarshal([]byte(objects[base+"manifest.json"]), &manifest)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, objects[base+"manifest.json"])
+	}
+	if manifest.Pod != "ms-l-0-0" || manifest.Container != "launcher" || len(manifest.Objects) != 2 ||
+		manifest.Objects[1].Name != "ms/1234/ms-l-0-0/launcher/files/trace/rank-0.txt" || manifest.Objects[1].Size != 5 {
+		t.Errorf("unexpected manifest %v", manifest)
+	}
+}
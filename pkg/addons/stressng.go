@@ -0,0 +1,129 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package addons
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// stress-ng is an application addon that runs a synthetic load next to a metric,
+// e.g., as the application that perf-sysstat monitors, or as interference.
+// https://github.com/ColinIanKing/stress-ng
+const (
+	stressNgIdentifier = "stress-ng"
+	StressNgContainer  = "ghcr.io/converged-computing/metric-stress-ng:latest"
+)
+
+// Defaults for stress-ng, shared with the perf-stress-ng metric
+var (
+	StressNgStressors = []string{"cpu"}
+	StressNgTimeout   = int32(60)
+)
+
+// StressNgCommand returns the stress-ng command for stressors (e.g., cpu, vm, cache),
+// each with a number of workers (0 is one per processor), for a timeout in seconds
+func StressNgCommand(stressors []string, workers, timeout int32, args string) string {
+	command := "stress-ng"
+	for _, stressor := range stressors {
+		command += fmt.Sprintf(" --%s %d", stressor, workers)
+	}
+	command += fmt.Sprintf(" --timeout %ds --metrics-brief", timeout)
+	if args != "" {
+		command += " " + args
+	}
+	return command
+}
+
+// StressNgStressorList parses a comma separated list of stressors
+func StressNgStressorList(value string) []string {
+	stressors := []string{}
+	for _, stressor := range strings.Split(value, ",") {
+		stressor = strings.TrimSpace(stressor)
+		if stressor != "" {
+			stressors = append(stressors, stressor)
+		}
+	}
+	return stressors
+}
+
+// StressNgAddon is an application addon with a stress-ng command
+type StressNgAddon struct {
+	ApplicationAddon
+
+	stressors []string
+	workers   int32
+	timeout   int32
+	args      string
+}
+
+// SetOptions sets the application options, and then the command from the stressors
+func (a *StressNgAddon) SetOptions(addon *api.MetricAddon, metric *api.MetricSet) {
+	a.Identifier = stressNgIdentifier
+	a.SetDefaultOptions(addon)
+	if a.image == "" {
+		a.image = StressNgContainer
+	}
+	a.name = stressNgIdentifier
+
+	a.stressors = StressNgStressors
+	a.timeout = StressNgTimeout
+	stressors, ok := addon.Options["stressors"]
+	if ok {
+		a.stressors = StressNgStressorList(stressors.StrVal)
+	}
+	workers, ok := addon.Options["workers"]
+	if ok {
+		a.workers = workers.IntVal
+	}
+	timeout, ok := addon.Options["timeout"]
+	if ok && timeout.IntVal > 0 {
+		a.timeout = timeout.IntVal
+	}
+	args, ok := addon.Options["args"]
+	if ok {
+		a.args = args.StrVal
+	}
+
+	// A command is only set for the user, to run stress-ng differently
+	if a.command == "" {
+		a.command = StressNgCommand(a.stressors, a.workers, a.timeout, a.args)
+	}
+}
+
+// Validate there is at least one stressor
+func (a *StressNgAddon) Validate() bool {
+	if len(a.stressors) == 0 {
+		logger.Error("The stress-ng addon requires one or more 'stressors'.")
+		return false
+	}
+	return a.ApplicationAddon.Validate()
+}
+
+// Exported options and list options
+func (a *StressNgAddon) Options() map[string]intstr.IntOrString {
+	values := a.DefaultOptions()
+	values["stressors"] = intstr.FromString(strings.Join(a.stressors, ","))
+	values["workers"] = intstr.FromInt(int(a.workers))
+	values["timeout"] = intstr.FromInt(int(a.timeout))
+	values["args"] = intstr.FromString(a.args)
+	return values
+}
+
+func init() {
+	base := AddonBase{
+		Identifier: stressNgIdentifier,
+		Summary:    "stress-ng synthetic CPU, memory, and cache load (application) container",
+	}
+	app := ApplicationAddon{AddonBase: base}
+	stress := StressNgAddon{ApplicationAddon: app}
+	Register(&stress)
+}
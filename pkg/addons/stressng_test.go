@@ -0,0 +1,74 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package addons
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+)
+
+func TestStressNgCommand(t *testing.T) {
+	tests := []struct {
+		stressors []string
+		workers   int32
+		timeout   int32
+		args      string
+		expected  string
+	}{
+		{stressors: []string{"cpu"}, timeout: 60, expected: "stress-ng --cpu 0 --timeout 60s --metrics-brief"},
+		{
+			stressors: []string{"cpu", "vm", "cache"},
+			workers:   4,
+			timeout:   30,
+			args:      "--vm-bytes 1G",
+			expected:  "stress-ng --cpu 4 --vm 4 --cache 4 --timeout 30s --metrics-brief --vm-bytes 1G",
+		},
+	}
+	for _, test := range tests {
+		command := StressNgCommand(test.stressors, test.workers, test.timeout, test.args)
+		if command != test.expected {
+			t.Errorf("expected %q, found %q", test.expected, command)
+		}
+	}
+
+	stressors := StressNgStressorList(" cpu, vm,,cache ")
+	if !reflect.DeepEqual(stressors, []string{"cpu", "vm", "cache"}) {
+		t.Errorf("unexpected stressors %v", stressors)
+	}
+}
+
+func TestStressNgAddon(t *testing.T) {
+	addon := &api.MetricAddon{
+		Name: stressNgIdentifier,
+		Options: map[string]intstr.IntOrString{
+			"stressors": intstr.FromString("vm"),
+			"workers":   intstr.FromInt(2),
+			"timeout":   intstr.FromInt(0),
+		},
+	}
+	stress := StressNgAddon{}
+	stress.SetOptions(addon, &api.MetricSet{})
+
+	// A timeout of 0 keeps the default, and the command is derived from the options
+	expected := "stress-ng --vm 2 --timeout 60s --metrics-brief"
+	if stress.command != expected || stress.image != StressNgContainer || !stress.Validate() {
+		t.Errorf("expected command %q with image %s, found %q with %s", expected, StressNgContainer, stress.command, stress.image)
+	}
+
+	// A command given by the user is not replaced
+	addon.Options["command"] = intstr.FromString("stress-ng --cpu 1 --timeout 5s")
+	stress = StressNgAddon{}
+	stress.SetOptions(addon, &api.MetricSet{})
+	if stress.command != "stress-ng --cpu 1 --timeout 5s" {
+		t.Errorf("expected the command of the user, found %q", stress.command)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	v1alpha2 "github.com/converged-computing/metrics-operator/api/v1alpha2"
+)
+
+// ContainerResourcesApplyConfiguration represents an declarative configuration of the ContainerResources type for use
+// with apply.
+type ContainerResourcesApplyConfiguration struct {
+	Limits   *v1alpha2.ContainerResource `json:"limits,omitempty"`
+	Requests *v1alpha2.ContainerResource `json:"requests,omitempty"`
+}
+
+// ContainerResourcesApplyConfiguration constructs an declarative configuration of the ContainerResources type for use with
+// apply.
+func ContainerResources() *ContainerResourcesApplyConfiguration {
+	return &ContainerResourcesApplyConfiguration{}
+}
+
+// WithLimits sets the Limits field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Limits field is set to the value of the last call.
+func (b *ContainerResourcesApplyConfiguration) WithLimits(value v1alpha2.ContainerResource) *ContainerResourcesApplyConfiguration {
+	b.Limits = &value
+	return b
+}
+
+// WithRequests sets the Requests field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Requests field is set to the value of the last call.
+func (b *ContainerResourcesApplyConfiguration) WithRequests(value v1alpha2.ContainerResource) *ContainerResourcesApplyConfiguration {
+	b.Requests = &value
+	return b
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha2
+
+// EntrypointTemplateApplyConfiguration represents an declarative configuration of the EntrypointTemplate type for use
+// with apply.
+type EntrypointTemplateApplyConfiguration struct {
+	Block     *string                              `json:"block,omitempty"`
+	Job       *string                              `json:"job,omitempty"`
+	Template  *string                              `json:"template,omitempty"`
+	ConfigMap *TemplateConfigMapApplyConfiguration `json:"configMap,omitempty"`
+}
+
+// EntrypointTemplateApplyConfiguration constructs an declarative configuration of the EntrypointTemplate type for use with
+// apply.
+func EntrypointTemplate() *EntrypointTemplateApplyConfiguration {
+	return &EntrypointTemplateApplyConfiguration{}
+}
+
+// WithBlock sets the Block field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Block field is set to the value of the last call.
+func (b *EntrypointTemplateApplyConfiguration) WithBlock(value string) *EntrypointTemplateApplyConfiguration {
+	b.Block = &value
+	return b
+}
+
+// WithJob sets the Job field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Job field is set to the value of the last call.
+func (b *EntrypointTemplateApplyConfiguration) WithJob(value string) *EntrypointTemplateApplyConfiguration {
+	b.Job = &value
+	return b
+}
+
+// WithTemplate sets the Template field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Template field is set to the value of the last call.
+func (b *EntrypointTemplateApplyConfiguration) WithTemplate(value string) *EntrypointTemplateApplyConfiguration {
+	b.Template = &value
+	return b
+}
+
+// WithConfigMap sets the ConfigMap field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ConfigMap field is set to the value of the last call.
+func (b *EntrypointTemplateApplyConfiguration) WithConfigMap(value *TemplateConfigMapApplyConfiguration) *EntrypointTemplateApplyConfiguration {
+	b.ConfigMap = value
+	return b
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha2
+
+// ImageStatusApplyConfiguration represents an declarative configuration of the ImageStatus type for use
+// with apply.
+type ImageStatusApplyConfiguration struct {
+	Image  *string `json:"image,omitempty"`
+	Digest *string `json:"digest,omitempty"`
+}
+
+// ImageStatusApplyConfiguration constructs an declarative configuration of the ImageStatus type for use with
+// apply.
+func ImageStatus() *ImageStatusApplyConfiguration {
+	return &ImageStatusApplyConfiguration{}
+}
+
+// WithImage sets the Image field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Image field is set to the value of the last call.
+func (b *ImageStatusApplyConfiguration) WithImage(value string) *ImageStatusApplyConfiguration {
+	b.Image = &value
+	return b
+}
+
+// WithDigest sets the Digest field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Digest field is set to the value of the last call.
+func (b *ImageStatusApplyConfiguration) WithDigest(value string) *ImageStatusApplyConfiguration {
+	b.Digest = &value
+	return b
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha2
+
+// LoggingApplyConfiguration represents an declarative configuration of the Logging type for use
+// with apply.
+type LoggingApplyConfiguration struct {
+	Interactive      *bool   `json:"interactive,omitempty"`
+	Debug            *string `json:"debug,omitempty"`
+	DebugLines       *int32  `json:"debugLines,omitempty"`
+	DebugHoldSeconds *int32  `json:"debugHoldSeconds,omitempty"`
+}
+
+// LoggingApplyConfiguration constructs an declarative configuration of the Logging type for use with
+// apply.
+func Logging() *LoggingApplyConfiguration {
+	return &LoggingApplyConfiguration{}
+}
+
+// WithInteractive sets the Interactive field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Interactive field is set to the value of the last call.
+func (b *LoggingApplyConfiguration) WithInteractive(value bool) *LoggingApplyConfiguration {
+	b.Interactive = &value
+	return b
+}
+
+// WithDebug sets the Debug field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Debug field is set to the value of the last call.
+func (b *LoggingApplyConfiguration) WithDebug(value string) *LoggingApplyConfiguration {
+	b.Debug = &value
+	return b
+}
+
+// WithDebugLines sets the DebugLines field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DebugLines field is set to the value of the last call.
+func (b *LoggingApplyConfiguration) WithDebugLines(value int32) *LoggingApplyConfiguration {
+	b.DebugLines = &value
+	return b
+}
+
+// WithDebugHoldSeconds sets the DebugHoldSeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DebugHoldSeconds field is set to the value of the last call.
+func (b *LoggingApplyConfiguration) WithDebugHoldSeconds(value int32) *LoggingApplyConfiguration {
+	b.DebugHoldSeconds = &value
+	return b
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// MetricApplyConfiguration represents an declarative configuration of the Metric type for use
+// with apply.
+type MetricApplyConfiguration struct {
+	Name         *string                                  `json:"name,omitempty"`
+	Pods         *int32                                   `json:"pods,omitempty"`
+	LauncherPods *int32                                   `json:"launcherPods,omitempty"`
+	WorkerPods   *int32                                   `json:"workerPods,omitempty"`
+	Timeout      *int32                                   `json:"timeout,omitempty"`
+	Templates    []EntrypointTemplateApplyConfiguration   `json:"templates,omitempty"`
+	Options      map[string]intstr.IntOrString            `json:"options,omitempty"`
+	Image        *string                                  `json:"image,omitempty"`
+	Addons       []MetricAddonApplyConfiguration          `json:"addons,omitempty"`
+	ListOptions  map[string][]intstr.IntOrString          `json:"listOptions,omitempty"`
+	MapOptions   map[string]map[string]intstr.IntOrString `json:"mapOptions,omitempty"`
+	Attributes   *ContainerSpecApplyConfiguration         `json:"attributes,omitempty"`
+	Resources    *ContainerResourcesApplyConfiguration    `json:"resources,omitempty"`
+}
+
+// MetricApplyConfiguration constructs an declarative configuration of the Metric type for use with
+// apply.
+func Metric() *MetricApplyConfiguration {
+	return &MetricApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *MetricApplyConfiguration) WithName(value string) *MetricApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithPods sets the Pods field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Pods field is set to the value of the last call.
+func (b *MetricApplyConfiguration) WithPods(value int32) *MetricApplyConfiguration {
+	b.Pods = &value
+	return b
+}
+
+// WithLauncherPods sets the LauncherPods field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LauncherPods field is set to the value of the last call.
+func (b *MetricApplyConfiguration) WithLauncherPods(value int32) *MetricApplyConfiguration {
+	b.LauncherPods = &value
+	return b
+}
+
+// WithWorkerPods sets the WorkerPods field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WorkerPods field is set to the value of the last call.
+func (b *MetricApplyConfiguration) WithWorkerPods(value int32) *MetricApplyConfiguration {
+	b.WorkerPods = &value
+	return b
+}
+
+// WithTimeout sets the Timeout field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Timeout field is set to the value of the last call.
+func (b *MetricApplyConfiguration) WithTimeout(value int32) *MetricApplyConfiguration {
+	b.Timeout = &value
+	return b
+}
+
+// WithTemplates adds the given value to the Templates field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Templates field.
+func (b *MetricApplyConfiguration) WithTemplates(values ...*EntrypointTemplateApplyConfiguration) *MetricApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithTemplates")
+		}
+		b.Templates = append(b.Templates, *values[i])
+	}
+	return b
+}
+
+// WithOptions puts the entries into the Options field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Options field,
+// overwriting an existing map entries in Options field with the same key.
+func (b *MetricApplyConfiguration) WithOptions(entries map[string]intstr.IntOrString) *MetricApplyConfiguration {
+	if b.Options == nil && len(entries) > 0 {
+		b.Options = make(map[string]intstr.IntOrString, len(entries))
+	}
+	for k, v := range entries {
+		b.Options[k] = v
+	}
+	return b
+}
+
+// WithImage sets the Image field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Image field is set to the value of the last call.
+func (b *MetricApplyConfiguration) WithImage(value string) *MetricApplyConfiguration {
+	b.Image = &value
+	return b
+}
+
+// WithAddons adds the given value to the Addons field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Addons field.
+func (b *MetricApplyConfiguration) WithAddons(values ...*MetricAddonApplyConfiguration) *MetricApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithAddons")
+		}
+		b.Addons = append(b.Addons, *values[i])
+	}
+	return b
+}
+
+// WithListOptions puts the entries into the ListOptions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the ListOptions field,
+// overwriting an existing map entries in ListOptions field with the same key.
+func (b *MetricApplyConfiguration) WithListOptions(entries map[string][]intstr.IntOrString) *MetricApplyConfiguration {
+	if b.ListOptions == nil && len(entries) > 0 {
+		b.ListOptions = make(map[string][]intstr.IntOrString, len(entries))
+	}
+	for k, v := range entries {
+		b.ListOptions[k] = v
+	}
+	return b
+}
+
+// WithMapOptions puts the entries into the MapOptions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the MapOptions field,
+// overwriting an existing map entries in MapOptions field with the same key.
+func (b *MetricApplyConfiguration) WithMapOptions(entries map[string]map[string]intstr.IntOrString) *MetricApplyConfiguration {
+	if b.MapOptions == nil && len(entries) > 0 {
+		b.MapOptions = make(map[string]map[string]intstr.IntOrString, len(entries))
+	}
+	for k, v := range entries {
+		b.MapOptions[k] = v
+	}
+	return b
+}
+
+// WithAttributes sets the Attributes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Attributes field is set to the value of the last call.
+func (b *MetricApplyConfiguration) WithAttributes(value *ContainerSpecApplyConfiguration) *MetricApplyConfiguration {
+	b.Attributes = value
+	return b
+}
+
+// WithResources sets the Resources field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Resources field is set to the value of the last call.
+func (b *MetricApplyConfiguration) WithResources(value *ContainerResourcesApplyConfiguration) *MetricApplyConfiguration {
+	b.Resources = value
+	return b
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// MetricAddonApplyConfiguration represents an declarative configuration of the MetricAddon type for use
+// with apply.
+type MetricAddonApplyConfiguration struct {
+	Name        *string                                  `json:"name,omitempty"`
+	Options     map[string]intstr.IntOrString            `json:"options,omitempty"`
+	ListOptions map[string][]intstr.IntOrString          `json:"listOptions,omitempty"`
+	MapOptions  map[string]map[string]intstr.IntOrString `json:"mapOptions,omitempty"`
+}
+
+// MetricAddonApplyConfiguration constructs an declarative configuration of the MetricAddon type for use with
+// apply.
+func MetricAddon() *MetricAddonApplyConfiguration {
+	return &MetricAddonApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *MetricAddonApplyConfiguration) WithName(value string) *MetricAddonApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithOptions puts the entries into the Options field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Options field,
+// overwriting an existing map entries in Options field with the same key.
+func (b *MetricAddonApplyConfiguration) WithOptions(entries map[string]intstr.IntOrString) *MetricAddonApplyConfiguration {
+	if b.Options == nil && len(entries) > 0 {
+		b.Options = make(map[string]intstr.IntOrString, len(entries))
+	}
+	for k, v := range entries {
+		b.Options[k] = v
+	}
+	return b
+}
+
+// WithListOptions puts the entries into the ListOptions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the ListOptions field,
+// overwriting an existing map entries in ListOptions field with the same key.
+func (b *MetricAddonApplyConfiguration) WithListOptions(entries map[string][]intstr.IntOrString) *MetricAddonApplyConfiguration {
+	if b.ListOptions == nil && len(entries) > 0 {
+		b.ListOptions = make(map[string][]intstr.IntOrString, len(entries))
+	}
+	for k, v := range entries {
+		b.ListOptions[k] = v
+	}
+	return b
+}
+
+// WithMapOptions puts the entries into the MapOptions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the MapOptions field,
+// overwriting an existing map entries in MapOptions field with the same key.
+func (b *MetricAddonApplyConfiguration) WithMapOptions(entries map[string]map[string]intstr.IntOrString) *MetricAddonApplyConfiguration {
+	if b.MapOptions == nil && len(entries) > 0 {
+		b.MapOptions = make(map[string]map[string]intstr.IntOrString, len(entries))
+	}
+	for k, v := range entries {
+		b.MapOptions[k] = v
+	}
+	return b
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	fluxv1alpha2 "github.com/converged-computing/metrics-operator/api/v1alpha2"
+)
+
+// MetricSetSpecApplyConfiguration represents an declarative configuration of the MetricSetSpec type for use
+// with apply.
+type MetricSetSpecApplyConfiguration struct {
+	Metrics         []MetricApplyConfiguration      `json:"metrics,omitempty"`
+	DontSetFQDN     *bool                           `json:"dontSetFQDN,omitempty"`
+	ServiceName     *string                         `json:"serviceName,omitempty"`
+	DeadlineSeconds *int64                          `json:"deadlineSeconds,omitempty"`
+	Pod             *PodApplyConfiguration          `json:"pod,omitempty"`
+	Pods            *int32                          `json:"pods,omitempty"`
+	Resources       *fluxv1alpha2.ContainerResource `json:"resources,omitempty"`
+	Logging         *LoggingApplyConfiguration      `json:"logging,omitempty"`
+	Results         *ResultsApplyConfiguration      `json:"results,omitempty"`
+}
+
+// MetricSetSpecApplyConfiguration constructs an declarative configuration of the MetricSetSpec type for use with
+// apply.
+func MetricSetSpec() *MetricSetSpecApplyConfiguration {
+	return &MetricSetSpecApplyConfiguration{}
+}
+
+// WithMetrics adds the given value to the Metrics field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Metrics field.
+func (b *MetricSetSpecApplyConfiguration) WithMetrics(values ...*MetricApplyConfiguration) *MetricSetSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithMetrics")
+		}
+		b.Metrics = append(b.Metrics, *values[i])
+	}
+	return b
+}
+
+// WithDontSetFQDN sets the DontSetFQDN field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DontSetFQDN field is set to the value of the last call.
+func (b *MetricSetSpecApplyConfiguration) WithDontSetFQDN(value bool) *MetricSetSpecApplyConfiguration {
+	b.DontSetFQDN = &value
+	return b
+}
+
+// WithServiceName sets the ServiceName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ServiceName field is set to the value of the last call.
+func (b *MetricSetSpecApplyConfiguration) WithServiceName(value string) *MetricSetSpecApplyConfiguration {
+	b.ServiceName = &value
+	return b
+}
+
+// WithDeadlineSeconds sets the DeadlineSeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DeadlineSeconds field is set to the value of the last call.
+func (b *MetricSetSpecApplyConfiguration) WithDeadlineSeconds(value int64) *MetricSetSpecApplyConfiguration {
+	b.DeadlineSeconds = &value
+	return b
+}
+
+// WithPod sets the Pod field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Pod field is set to the value of the last call.
+func (b *MetricSetSpecApplyConfiguration) WithPod(value *PodApplyConfiguration) *MetricSetSpecApplyConfiguration {
+	b.Pod = value
+	return b
+}
+
+// WithPods sets the Pods field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Pods field is set to the value of the last call.
+func (b *MetricSetSpecApplyConfiguration) WithPods(value int32) *MetricSetSpecApplyConfiguration {
+	b.Pods = &value
+	return b
+}
+
+// WithResources sets the Resources field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Resources field is set to the value of the last call.
+func (b *MetricSetSpecApplyConfiguration) WithResources(value fluxv1alpha2.ContainerResource) *MetricSetSpecApplyConfiguration {
+	b.Resources = &value
+	return b
+}
+
+// WithLogging sets the Logging field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Logging field is set to the value of the last call.
+func (b *MetricSetSpecApplyConfiguration) WithLogging(value *LoggingApplyConfiguration) *MetricSetSpecApplyConfiguration {
+	b.Logging = value
+	return b
+}
+
+// WithResults sets the Results field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Results field is set to the value of the last call.
+func (b *MetricSetSpecApplyConfiguration) WithResults(value *ResultsApplyConfiguration) *MetricSetSpecApplyConfiguration {
+	b.Results = value
+	return b
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha2
+
+// MetricSetStatusApplyConfiguration represents an declarative configuration of the MetricSetStatus type for use
+// with apply.
+type MetricSetStatusApplyConfiguration struct {
+	Metrics  []MetricStatusApplyConfiguration `json:"metrics,omitempty"`
+	HeldPods []string                         `json:"heldPods,omitempty"`
+	Images   []ImageStatusApplyConfiguration  `json:"images,omitempty"`
+}
+
+// MetricSetStatusApplyConfiguration constructs an declarative configuration of the MetricSetStatus type for use with
+// apply.
+func MetricSetStatus() *MetricSetStatusApplyConfiguration {
+	return &MetricSetStatusApplyConfiguration{}
+}
+
+// WithMetrics adds the given value to the Metrics field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Metrics field.
+func (b *MetricSetStatusApplyConfiguration) WithMetrics(values ...*MetricStatusApplyConfiguration) *MetricSetStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithMetrics")
+		}
+		b.Metrics = append(b.Metrics, *values[i])
+	}
+	return b
+}
+
+// WithHeldPods adds the given value to the HeldPods field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the HeldPods field.
+func (b *MetricSetStatusApplyConfiguration) WithHeldPods(values ...string) *MetricSetStatusApplyConfiguration {
+	for i := range values {
+		b.HeldPods = append(b.HeldPods, values[i])
+	}
+	return b
+}
+
+// WithImages adds the given value to the Images field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Images field.
+func (b *MetricSetStatusApplyConfiguration) WithImages(values ...*ImageStatusApplyConfiguration) *MetricSetStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithImages")
+		}
+		b.Images = append(b.Images, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha2
+
+// MetricStatusApplyConfiguration represents an declarative configuration of the MetricStatus type for use
+// with apply.
+type MetricStatusApplyConfiguration struct {
+	Name     *string  `json:"name,omitempty"`
+	Reason   *string  `json:"reason,omitempty"`
+	ExitCode *int32   `json:"exitCode,omitempty"`
+	Pods     []string `json:"pods,omitempty"`
+}
+
+// MetricStatusApplyConfiguration constructs an declarative configuration of the MetricStatus type for use with
+// apply.
+func MetricStatus() *MetricStatusApplyConfiguration {
+	return &MetricStatusApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *MetricStatusApplyConfiguration) WithName(value string) *MetricStatusApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithReason sets the Reason field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Reason field is set to the value of the last call.
+func (b *MetricStatusApplyConfiguration) WithReason(value string) *MetricStatusApplyConfiguration {
+	b.Reason = &value
+	return b
+}
+
+// WithExitCode sets the ExitCode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ExitCode field is set to the value of the last call.
+func (b *MetricStatusApplyConfiguration) WithExitCode(value int32) *MetricStatusApplyConfiguration {
+	b.ExitCode = &value
+	return b
+}
+
+// WithPods adds the given value to the Pods field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Pods field.
+func (b *MetricStatusApplyConfiguration) WithPods(values ...string) *MetricStatusApplyConfiguration {
+	for i := range values {
+		b.Pods = append(b.Pods, values[i])
+	}
+	return b
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha2
+
+// SecurityContextApplyConfiguration represents an declarative configuration of the SecurityContext type for use
+// with apply.
+type SecurityContextApplyConfiguration struct {
+	Privileged               *bool                           `json:"privileged,omitempty"`
+	AllowPtrace              *bool                           `json:"allowPtrace,omitempty"`
+	AllowAdmin               *bool                           `json:"allowAdmin,omitempty"`
+	RunAsUser                *int64                          `json:"runAsUser,omitempty"`
+	RunAsGroup               *int64                          `json:"runAsGroup,omitempty"`
+	FSGroup                  *int64                          `json:"fsGroup,omitempty"`
+	RunAsNonRoot             *bool                           `json:"runAsNonRoot,omitempty"`
+	AllowPrivilegeEscalation *bool                           `json:"allowPrivilegeEscalation,omitempty"`
+	ReadOnlyRootFilesystem   *bool                           `json:"readOnlyRootFilesystem,omitempty"`
+	Capabilities             *CapabilitiesApplyConfiguration `json:"capabilities,omitempty"`
+	SeccompProfile           *ProfileApplyConfiguration      `json:"seccompProfile,omitempty"`
+	AppArmorProfile          *ProfileApplyConfiguration      `json:"appArmorProfile,omitempty"`
+}
+
+// SecurityContextApplyConfiguration constructs an declarative configuration of the SecurityContext type for use with
+// apply.
+func SecurityContext() *SecurityContextApplyConfiguration {
+	return &SecurityContextApplyConfiguration{}
+}
+
+// WithPrivileged sets the Privileged field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Privileged field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithPrivileged(value bool) *SecurityContextApplyConfiguration {
+	b.Privileged = &value
+	return b
+}
+
+// WithAllowPtrace sets the AllowPtrace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AllowPtrace field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithAllowPtrace(value bool) *SecurityContextApplyConfiguration {
+	b.AllowPtrace = &value
+	return b
+}
+
+// WithAllowAdmin sets the AllowAdmin field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AllowAdmin field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithAllowAdmin(value bool) *SecurityContextApplyConfiguration {
+	b.AllowAdmin = &value
+	return b
+}
+
+// WithRunAsUser sets the RunAsUser field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RunAsUser field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithRunAsUser(value int64) *SecurityContextApplyConfiguration {
+	b.RunAsUser = &value
+	return b
+}
+
+// WithRunAsGroup sets the RunAsGroup field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RunAsGroup field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithRunAsGroup(value int64) *SecurityContextApplyConfiguration {
+	b.RunAsGroup = &value
+	return b
+}
+
+// WithFSGroup sets the FSGroup field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FSGroup field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithFSGroup(value int64) *SecurityContextApplyConfiguration {
+	b.FSGroup = &value
+	return b
+}
+
+// WithRunAsNonRoot sets the RunAsNonRoot field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RunAsNonRoot field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithRunAsNonRoot(value bool) *SecurityContextApplyConfiguration {
+	b.RunAsNonRoot = &value
+	return b
+}
+
+// WithAllowPrivilegeEscalation sets the AllowPrivilegeEscalation field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AllowPrivilegeEscalation field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithAllowPrivilegeEscalation(value bool) *SecurityContextApplyConfiguration {
+	b.AllowPrivilegeEscalation = &value
+	return b
+}
+
+// WithReadOnlyRootFilesystem sets the ReadOnlyRootFilesystem field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReadOnlyRootFilesystem field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithReadOnlyRootFilesystem(value bool) *SecurityContextApplyConfiguration {
+	b.ReadOnlyRootFilesystem = &value
+	return b
+}
+
+// WithCapabilities sets the Capabilities field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Capabilities field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithCapabilities(value *CapabilitiesApplyConfiguration) *SecurityContextApplyConfiguration {
+	b.Capabilities = value
+	return b
+}
+
+// WithSeccompProfile sets the SeccompProfile field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SeccompProfile field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithSeccompProfile(value *ProfileApplyConfiguration) *SecurityContextApplyConfiguration {
+	b.SeccompProfile = value
+	return b
+}
+
+// WithAppArmorProfile sets the AppArmorProfile field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AppArmorProfile field is set to the value of the last call.
+func (b *SecurityContextApplyConfiguration) WithAppArmorProfile(value *ProfileApplyConfiguration) *SecurityContextApplyConfiguration {
+	b.AppArmorProfile = value
+	return b
+}
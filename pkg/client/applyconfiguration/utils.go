@@ -0,0 +1,69 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	v1alpha2 "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	fluxv1alpha2 "github.com/converged-computing/metrics-operator/pkg/client/applyconfiguration/flux/v1alpha2"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=flux-framework.org, Version=v1alpha2
+	case v1alpha2.SchemeGroupVersion.WithKind("Capabilities"):
+		return &fluxv1alpha2.CapabilitiesApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("ContainerResources"):
+		return &fluxv1alpha2.ContainerResourcesApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("ContainerSpec"):
+		return &fluxv1alpha2.ContainerSpecApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("EntrypointTemplate"):
+		return &fluxv1alpha2.EntrypointTemplateApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("ImageStatus"):
+		return &fluxv1alpha2.ImageStatusApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("Logging"):
+		return &fluxv1alpha2.LoggingApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("Metric"):
+		return &fluxv1alpha2.MetricApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("MetricAddon"):
+		return &fluxv1alpha2.MetricAddonApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("MetricSet"):
+		return &fluxv1alpha2.MetricSetApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("MetricSetSpec"):
+		return &fluxv1alpha2.MetricSetSpecApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("MetricSetStatus"):
+		return &fluxv1alpha2.MetricSetStatusApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("MetricStatus"):
+		return &fluxv1alpha2.MetricStatusApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("Pod"):
+		return &fluxv1alpha2.PodApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("Profile"):
+		return &fluxv1alpha2.ProfileApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("Results"):
+		return &fluxv1alpha2.ResultsApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("SecurityContext"):
+		return &fluxv1alpha2.SecurityContextApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("TemplateConfigMap"):
+		return &fluxv1alpha2.TemplateConfigMapApplyConfiguration{}
+
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/client/clientset/versioned/fake"
+	"github.com/converged-computing/metrics-operator/pkg/client/informers/externalversions"
+)
+
+// TestFakeClientset shows how to test code that creates and watches MetricSets
+func TestFakeClientset(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := fake.NewSimpleClientset()
+	set := &api.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "metricset-sample", Namespace: "default"},
+		Spec: api.MetricSetSpec{
+			Pods:    2,
+			Metrics: []api.Metric{{Name: "network-osu-benchmark"}},
+		},
+	}
+	_, err := client.FluxV1alpha2().MetricSets("default").Create(ctx, set, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("creating MetricSet: %s", err)
+	}
+
+	// The informer and lister see objects created with the fake clientset
+	factory := externalversions.NewSharedInformerFactory(client, 0)
+	informer := factory.Flux().V1alpha2().MetricSets()
+	lister := informer.Lister()
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced) {
+		t.Fatalf("informer cache did not sync")
+	}
+	found, err := lister.MetricSets("default").Get("metricset-sample")
+	if err != nil {
+		t.Fatalf("listing MetricSet: %s", err)
+	}
+	if found.Spec.Pods != 2 || len(found.Spec.Metrics) != 1 {
+		t.Errorf("unexpected MetricSet spec: %+v", found.Spec)
+	}
+}
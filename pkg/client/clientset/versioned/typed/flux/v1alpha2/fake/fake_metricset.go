@@ -0,0 +1,190 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+
+	v1alpha2 "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	fluxv1alpha2 "github.com/converged-computing/metrics-operator/pkg/client/applyconfiguration/flux/v1alpha2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeMetricSets implements MetricSetInterface
+type FakeMetricSets struct {
+	Fake *FakeFluxV1alpha2
+	ns   string
+}
+
+var metricsetsResource = schema.GroupVersionResource{Group: "flux-framework.org", Version: "v1alpha2", Resource: "metricsets"}
+
+var metricsetsKind = schema.GroupVersionKind{Group: "flux-framework.org", Version: "v1alpha2", Kind: "MetricSet"}
+
+// Get takes name of the metricSet, and returns the corresponding metricSet object, and an error if there is any.
+func (c *FakeMetricSets) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha2.MetricSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(metricsetsResource, c.ns, name), &v1alpha2.MetricSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.MetricSet), err
+}
+
+// List takes label and field selectors, and returns the list of MetricSets that match those selectors.
+func (c *FakeMetricSets) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha2.MetricSetList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(metricsetsResource, metricsetsKind, c.ns, opts), &v1alpha2.MetricSetList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha2.MetricSetList{ListMeta: obj.(*v1alpha2.MetricSetList).ListMeta}
+	for _, item := range obj.(*v1alpha2.MetricSetList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested metricSets.
+func (c *FakeMetricSets) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(metricsetsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a metricSet and creates it.  Returns the server's representation of the metricSet, and an error, if there is any.
+func (c *FakeMetricSets) Create(ctx context.Context, metricSet *v1alpha2.MetricSet, opts v1.CreateOptions) (result *v1alpha2.MetricSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(metricsetsResource, c.ns, metricSet), &v1alpha2.MetricSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.MetricSet), err
+}
+
+// Update takes the representation of a metricSet and updates it. Returns the server's representation of the metricSet, and an error, if there is any.
+func (c *FakeMetricSets) Update(ctx context.Context, metricSet *v1alpha2.MetricSet, opts v1.UpdateOptions) (result *v1alpha2.MetricSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(metricsetsResource, c.ns, metricSet), &v1alpha2.MetricSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.MetricSet), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeMetricSets) UpdateStatus(ctx context.Context, metricSet *v1alpha2.MetricSet, opts v1.UpdateOptions) (*v1alpha2.MetricSet, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(metricsetsResource, "status", c.ns, metricSet), &v1alpha2.MetricSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.MetricSet), err
+}
+
+// Delete takes name of the metricSet and deletes it. Returns an error if one occurs.
+func (c *FakeMetricSets) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(metricsetsResource, c.ns, name, opts), &v1alpha2.MetricSet{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeMetricSets) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(metricsetsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha2.MetricSetList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched metricSet.
+func (c *FakeMetricSets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha2.MetricSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(metricsetsResource, c.ns, name, pt, data, subresources...), &v1alpha2.MetricSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.MetricSet), err
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied metricSet.
+func (c *FakeMetricSets) Apply(ctx context.Context, metricSet *fluxv1alpha2.MetricSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha2.MetricSet, err error) {
+	if metricSet == nil {
+		return nil, fmt.Errorf("metricSet provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(metricSet)
+	if err != nil {
+		return nil, err
+	}
+	name := metricSet.Name
+	if name == nil {
+		return nil, fmt.Errorf("metricSet.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(metricsetsResource, c.ns, *name, types.ApplyPatchType, data), &v1alpha2.MetricSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.MetricSet), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeMetricSets) ApplyStatus(ctx context.Context, metricSet *fluxv1alpha2.MetricSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha2.MetricSet, err error) {
+	if metricSet == nil {
+		return nil, fmt.Errorf("metricSet provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(metricSet)
+	if err != nil {
+		return nil, err
+	}
+	name := metricSet.Name
+	if name == nil {
+		return nil, fmt.Errorf("metricSet.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(metricsetsResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1alpha2.MetricSet{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.MetricSet), err
+}
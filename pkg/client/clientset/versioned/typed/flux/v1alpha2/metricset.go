@@ -0,0 +1,256 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+	"time"
+
+	v1alpha2 "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	fluxv1alpha2 "github.com/converged-computing/metrics-operator/pkg/client/applyconfiguration/flux/v1alpha2"
+	scheme "github.com/converged-computing/metrics-operator/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// MetricSetsGetter has a method to return a MetricSetInterface.
+// A group's client should implement this interface.
+type MetricSetsGetter interface {
+	MetricSets(namespace string) MetricSetInterface
+}
+
+// MetricSetInterface has methods to work with MetricSet resources.
+type MetricSetInterface interface {
+	Create(ctx context.Context, metricSet *v1alpha2.MetricSet, opts v1.CreateOptions) (*v1alpha2.MetricSet, error)
+	Update(ctx context.Context, metricSet *v1alpha2.MetricSet, opts v1.UpdateOptions) (*v1alpha2.MetricSet, error)
+	UpdateStatus(ctx context.Context, metricSet *v1alpha2.MetricSet, opts v1.UpdateOptions) (*v1alpha2.MetricSet, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha2.MetricSet, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha2.MetricSetList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha2.MetricSet, err error)
+	Apply(ctx context.Context, metricSet *fluxv1alpha2.MetricSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha2.MetricSet, err error)
+	ApplyStatus(ctx context.Context, metricSet *fluxv1alpha2.MetricSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha2.MetricSet, err error)
+	MetricSetExpansion
+}
+
+// metricSets implements MetricSetInterface
+type metricSets struct {
+	client rest.Interface
+	ns     string
+}
+
+// newMetricSets returns a MetricSets
+func newMetricSets(c *FluxV1alpha2Client, namespace string) *metricSets {
+	return &metricSets{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the metricSet, and returns the corresponding metricSet object, and an error if there is any.
+func (c *metricSets) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha2.MetricSet, err error) {
+	result = &v1alpha2.MetricSet{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("metricsets").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of MetricSets that match those selectors.
+func (c *metricSets) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha2.MetricSetList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha2.MetricSetList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("metricsets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested metricSets.
+func (c *metricSets) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("metricsets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a metricSet and creates it.  Returns the server's representation of the metricSet, and an error, if there is any.
+func (c *metricSets) Create(ctx context.Context, metricSet *v1alpha2.MetricSet, opts v1.CreateOptions) (result *v1alpha2.MetricSet, err error) {
+	result = &v1alpha2.MetricSet{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("metricsets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(metricSet).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a metricSet and updates it. Returns the server's representation of the metricSet, and an error, if there is any.
+func (c *metricSets) Update(ctx context.Context, metricSet *v1alpha2.MetricSet, opts v1.UpdateOptions) (result *v1alpha2.MetricSet, err error) {
+	result = &v1alpha2.MetricSet{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("metricsets").
+		Name(metricSet.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(metricSet).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *metricSets) UpdateStatus(ctx context.Context, metricSet *v1alpha2.MetricSet, opts v1.UpdateOptions) (result *v1alpha2.MetricSet, err error) {
+	result = &v1alpha2.MetricSet{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("metricsets").
+		Name(metricSet.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(metricSet).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the metricSet and deletes it. Returns an error if one occurs.
+func (c *metricSets) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("metricsets").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *metricSets) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("metricsets").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched metricSet.
+func (c *metricSets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha2.MetricSet, err error) {
+	result = &v1alpha2.MetricSet{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("metricsets").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied metricSet.
+func (c *metricSets) Apply(ctx context.Context, metricSet *fluxv1alpha2.MetricSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha2.MetricSet, err error) {
+	if metricSet == nil {
+		return nil, fmt.Errorf("metricSet provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(metricSet)
+	if err != nil {
+		return nil, err
+	}
+	name := metricSet.Name
+	if name == nil {
+		return nil, fmt.Errorf("metricSet.Name must be provided to Apply")
+	}
+	result = &v1alpha2.MetricSet{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("metricsets").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *metricSets) ApplyStatus(ctx context.Context, metricSet *fluxv1alpha2.MetricSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha2.MetricSet, err error) {
+	if metricSet == nil {
+		return nil, fmt.Errorf("metricSet provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(metricSet)
+	if err != nil {
+		return nil, err
+	}
+
+	name := metricSet.Name
+	if name == nil {
+		return nil, fmt.Errorf("metricSet.Name must be provided to Apply")
+	}
+
+	result = &v1alpha2.MetricSet{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("metricsets").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"context"
+	time "time"
+
+	fluxv1alpha2 "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	versioned "github.com/converged-computing/metrics-operator/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/converged-computing/metrics-operator/pkg/client/informers/externalversions/internalinterfaces"
+	v1alpha2 "github.com/converged-computing/metrics-operator/pkg/client/listers/flux/v1alpha2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// MetricSetInformer provides access to a shared informer and lister for
+// MetricSets.
+type MetricSetInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha2.MetricSetLister
+}
+
+type metricSetInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewMetricSetInformer constructs a new informer for MetricSet type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewMetricSetInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredMetricSetInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredMetricSetInformer constructs a new informer for MetricSet type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredMetricSetInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.FluxV1alpha2().MetricSets(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.FluxV1alpha2().MetricSets(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&fluxv1alpha2.MetricSet{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *metricSetInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredMetricSetInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *metricSetInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&fluxv1alpha2.MetricSet{}, f.defaultInformer)
+}
+
+func (f *metricSetInformer) Lister() v1alpha2.MetricSetLister {
+	return v1alpha2.NewMetricSetLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	v1alpha2 "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// MetricSetLister helps list MetricSets.
+// All objects returned here must be treated as read-only.
+type MetricSetLister interface {
+	// List lists all MetricSets in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha2.MetricSet, err error)
+	// MetricSets returns an object that can list and get MetricSets.
+	MetricSets(namespace string) MetricSetNamespaceLister
+	MetricSetListerExpansion
+}
+
+// metricSetLister implements the MetricSetLister interface.
+type metricSetLister struct {
+	indexer cache.Indexer
+}
+
+// NewMetricSetLister returns a new MetricSetLister.
+func NewMetricSetLister(indexer cache.Indexer) MetricSetLister {
+	return &metricSetLister{indexer: indexer}
+}
+
+// List lists all MetricSets in the indexer.
+func (s *metricSetLister) List(selector labels.Selector) (ret []*v1alpha2.MetricSet, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha2.MetricSet))
+	})
+	return ret, err
+}
+
+// MetricSets returns an object that can list and get MetricSets.
+func (s *metricSetLister) MetricSets(namespace string) MetricSetNamespaceLister {
+	return metricSetNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// MetricSetNamespaceLister helps list and get MetricSets.
+// All objects returned here must be treated as read-only.
+type MetricSetNamespaceLister interface {
+	// List lists all MetricSets in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha2.MetricSet, err error)
+	// Get retrieves the MetricSet from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha2.MetricSet, error)
+	MetricSetNamespaceListerExpansion
+}
+
+// metricSetNamespaceLister implements the MetricSetNamespaceLister
+// interface.
+type metricSetNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all MetricSets in the indexer for a given namespace.
+func (s metricSetNamespaceLister) List(selector labels.Selector) (ret []*v1alpha2.MetricSet, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha2.MetricSet))
+	})
+	return ret, err
+}
+
+// Get retrieves the MetricSet from the indexer for a given namespace and name.
+func (s metricSetNamespaceLister) Get(name string) (*v1alpha2.MetricSet, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha2.Resource("metricset"), name)
+	}
+	return obj.(*v1alpha2.MetricSet), nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metadata
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+)
+
+// The metadata is one line of JSON at the start of the log of a metric
+const metadataPrefix = "METADATA START "
+
+// ParseMetadata returns the metadata at the start of the log of a metric,
+// and false if the log doesn't have it
+func ParseMetadata(log string) (MetricExport, bool) {
+	export := MetricExport{}
+	for _, line := range strings.Split(log, "\n") {
+		if !strings.HasPrefix(line, metadataPrefix) {
+			continue
+		}
+		err := json.Unmarshal([]byte(strings.TrimPrefix(line, metadataPrefix)), &export)
+		return export, err == nil
+	}
+	return export, false
+}
+
+// Events the entrypoint writes after markers
+const (
+	EventStart     = "start"
+	EventTimepoint = "timepoint"
+	EventEnd       = "end"
+	EventTimeout   = "timeout"
+
+	eventPrefix = `{"event":`
+)
+
+// An Event is a line of JSON written after a marker, with the time in seconds.
+// Iteration is the number of timepoints before the event.
+type Event struct {
+	Event     string  `json:"event"`
+	Timestamp float64 `json:"ts"`
+	Metric    string  `json:"metric"`
+	Iteration int     `json:"iteration"`
+}
+
+// Time of the event
+func (e Event) Time() time.Time {
+	seconds := math.Floor(e.Timestamp)
+	return time.Unix(int64(seconds), int64((e.Timestamp-seconds)*float64(time.Second))).UTC()
+}
+
+// ParseEvent parses an event line, and returns false for any other line
+func ParseEvent(line string) (Event, bool) {
+	event := Event{}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, eventPrefix) {
+		return event, false
+	}
+	err := json.Unmarshal([]byte(line), &event)
+	return event, err == nil && event.Event != ""
+}
+
+// A Section is the output of one timepoint. With events, it has the times the
+// timepoint started and ended (at the next timepoint, the collection end, or a timeout).
+type Section struct {
+	Iteration int
+	Output    string
+	Start     time.Time
+	End       time.Time
+}
+
+// Duration of the section, or zero for a log without events
+func (s Section) Duration() time.Duration {
+	if s.Start.IsZero() || s.End.IsZero() {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// A Log is the log of a metric split at its markers. The start and end are
+// the times of the collection, and are zero for a log without events.
+type Log struct {
+	Sections []Section
+	Events   []Event
+	Start    time.Time
+	End      time.Time
+	TimedOut bool
+}
+
+// Duration of the collection, or zero for a log without events
+func (l Log) Duration() time.Duration {
+	if l.Start.IsZero() || l.End.IsZero() {
+		return 0
+	}
+	return l.End.Sub(l.Start)
+}
+
+// SplitLog splits the log of a metric into the output of each timepoint: the lines
+// after each separator, until the next one or the end of the collection. Lines before
+// the collection starts (e.g., metadata) are not included, and neither are events,
+// which are written after the collection end and give the times of the sections.
+// Logs with and without events are split the same.
+func SplitLog(log string) Log {
+	result := Log{Sections: []Section{}, Events: []Event{}}
+	started := false
+	ended := false
+	inSection := false
+	current := []string{}
+
+	closeSection := func() {
+		if inSection {
+			result.Sections[len(result.Sections)-1].Output = strings.Join(current, "\n")
+		}
+		current = []string{}
+	}
+
+	events := []Event{}
+	for _, line := range strings.Split(log, "\n") {
+		event, isEvent := ParseEvent(line)
+		marker := strings.TrimSpace(line)
+		switch {
+		case isEvent:
+			events = append(events, event)
+		case ended:
+			continue
+		case marker == CollectionStart:
+			started = true
+		case !started:
+			continue
+		case marker == Separator:
+			closeSection()
+			inSection = true
+			result.Sections = append(result.Sections, Section{Iteration: len(result.Sections)})
+		case marker == CollectionEnd:
+			closeSection()
+			inSection = false
+			ended = true
+		case inSection:
+			current = append(current, line)
+		}
+	}
+
+	// A log without the end (e.g., a metric that timed out) keeps the last section
+	closeSection()
+	for _, event := range events {
+		result.addEvent(event)
+	}
+	return result
+}
+
+// addEvent adds the time of an event to the log and the sections it starts or
+// ends, which are known from the number of timepoints before it
+func (l *Log) addEvent(event Event) {
+	l.Events = append(l.Events, event)
+	t := event.Time()
+	section := func(iteration int) *Section {
+		if iteration < 0 || iteration >= len(l.Sections) {
+			return nil
+		}
+		return &l.Sections[iteration]
+	}
+	switch event.Event {
+	case EventStart:
+		l.Start = t
+	case EventTimepoint:
+		if previous := section(event.Iteration - 1); previous != nil && previous.End.IsZero() {
+			previous.End = t
+		}
+		if next := section(event.Iteration); next != nil {
+			next.Start = t
+		}
+	case EventEnd, EventTimeout:
+		if last := section(event.Iteration - 1); last != nil && last.End.IsZero() {
+			last.End = t
+		}
+		if event.Event == EventTimeout {
+			l.TimedOut = true
+		} else {
+			l.End = t
+		}
+	}
+}
+
+// Sections returns the output of each timepoint in the log of a metric: the
+// lines after each separator, until the next one or the end of the collection.
+// Lines before the collection starts (e.g., metadata) are not included.
+func Sections(log string) []string {
+	sections := []string{}
+	for _, section := range SplitLog(log).Sections {
+		sections = append(sections, section.Output)
+	}
+	return sections
+}
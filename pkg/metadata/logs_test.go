@@ -0,0 +1,112 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metadata
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitLog(t *testing.T) {
+	plain := []string{
+		"METADATA START {}",
+		"METADATA END",
+		CollectionStart,
+		Separator,
+		"first",
+		"  " + Separator,
+		"second",
+		"",
+		CollectionEnd,
+		"after",
+	}
+	withEvents := []string{
+		"METADATA START {}",
+		"METADATA END",
+		CollectionStart,
+		Separator,
+		"first",
+		"  " + Separator,
+		"second",
+		"",
+		CollectionEnd,
+		`{"event":"start","ts":100.5,"metric":"m","iteration":0}`,
+		`{"event":"timepoint","ts":101,"metric":"m","iteration":0}`,
+		`{"event":"timepoint","ts":103.25,"metric":"m","iteration":1}`,
+		`{"event":"end","ts":104,"metric":"m","iteration":2}`,
+		"after",
+	}
+
+	// Both formats have the same output
+	expected := []string{"first", "second\n"}
+	for _, log := range [][]string{plain, withEvents} {
+		sections := Sections(strings.Join(log, "\n"))
+		if !reflect.DeepEqual(sections, expected) {
+			t.Errorf("expected sections %q, found %q", expected, sections)
+		}
+	}
+	if SplitLog(strings.Join(plain, "\n")).Sections[0].Duration() != 0 {
+		t.Errorf("expected no duration without events")
+	}
+
+	log := SplitLog(strings.Join(withEvents, "\n"))
+	if len(log.Events) != 4 || log.TimedOut {
+		t.Errorf("unexpected events %v", log.Events)
+	}
+	if log.Duration() != 3500*time.Millisecond {
+		t.Errorf("expected a collection of 3.5s, found %s", log.Duration())
+	}
+	if log.Sections[0].Duration() != 2250*time.Millisecond || log.Sections[1].Duration() != 750*time.Millisecond {
+		t.Errorf("unexpected section durations %s, %s", log.Sections[0].Duration(), log.Sections[1].Duration())
+	}
+	if log.Sections[1].Iteration != 1 || !log.Sections[1].End.Equal(time.Unix(104, 0)) {
+		t.Errorf("unexpected section %+v", log.Sections[1])
+	}
+}
+
+func TestSplitLogTimeout(t *testing.T) {
+	log := SplitLog(strings.Join([]string{
+		CollectionStart,
+		Separator,
+		"partial",
+		Timeout,
+		"Metric did not finish within 5 seconds",
+		CollectionEnd,
+		`{"event":"start","ts":10,"metric":"m","iteration":0}`,
+		`{"event":"timepoint","ts":10,"metric":"m","iteration":0}`,
+		`{"event":"timeout","ts":15,"metric":"m","iteration":1}`,
+		`{"event":"end","ts":16,"metric":"m","iteration":1}`,
+	}, "\n"))
+	if !log.TimedOut || len(log.Sections) != 1 || log.Sections[0].Duration() != 5*time.Second || log.Duration() != 6*time.Second {
+		t.Errorf("unexpected log %+v", log)
+	}
+	if !strings.HasPrefix(log.Sections[0].Output, "partial\n"+Timeout) {
+		t.Errorf("unexpected output %q", log.Sections[0].Output)
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	for line, expected := range map[string]bool{
+		`{"event":"end","ts":1697712345.123456,"metric":"m","iteration":2}`: true,
+		`  {"event":"start","ts":1,"metric":"m","iteration":0}`:             true,
+		`{"event":"end","ts":}`:      false,
+		`{"other":"json"}`:           false,
+		`METRICS OPERATOR TIMEPOINT`: false,
+	} {
+		_, ok := ParseEvent(line)
+		if ok != expected {
+			t.Errorf("%s: expected %v", line, expected)
+		}
+	}
+	event, _ := ParseEvent(`{"event":"end","ts":1697712345.5,"metric":"m","iteration":2}`)
+	if !event.Time().Equal(time.Unix(1697712345, 5e8)) || event.Iteration != 2 {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
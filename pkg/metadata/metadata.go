@@ -8,36 +8,67 @@ SPDX-License-Identifier: MIT
 package metadata
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Consistent logging identifiers that should be echoed to have newline after
 var (
+	MarkerPrefix    = "METRICS OPERATOR "
 	Separator       = "METRICS OPERATOR TIMEPOINT"
 	CollectionStart = "METRICS OPERATOR COLLECTION START"
 	CollectionEnd   = "METRICS OPERATOR COLLECTION END"
-	handle          *zap.Logger
-	logger          *zap.SugaredLogger
+	Timeout         = "METRICS OPERATOR TIMEOUT"
+	DebugHold       = "METRICS OPERATOR DEBUG HOLD"
+
+	// The rendezvous barrier waits for all hosts of a metric
+	Rendezvous       = "METRICS OPERATOR RENDEZVOUS"
+	RendezvousFailed = "METRICS OPERATOR RENDEZVOUS FAILED"
+
+	// The eventlog of a job run by a metric in a Flux instance
+	FluxEventlogStart = "METRICS OPERATOR FLUX EVENTLOG START"
+	FluxEventlogEnd   = "METRICS OPERATOR FLUX EVENTLOG END"
+
+	// Exit code of an entrypoint that did not finish within the metric timeout
+	TimeoutExitCode = int32(124)
+
+	// Debug mode saves the output and writes a summary to the debug file
+	DebugOutputFile  = "/tmp/metrics-operator-output.log"
+	DebugFile        = "/tmp/metrics-operator-debug.log"
+	DebugLines       = int32(100)
+	DebugHoldSeconds = int32(3600)
+	handle           *zap.Logger
+	logger           *zap.SugaredLogger
 )
 
-// Metric Export is a flattened structure with minimal required metadata for now
-// It would be nice if we could just dump everything.
+// Version of the metadata schema, increased when fields change meaning or are removed
+const Version = 2
+
+// OperatorVersion is set when the operator is built, e.g.,
+// go build -ldflags "-X github.com/converged-computing/metrics-operator/pkg/metadata.OperatorVersion=0.0.12"
+var OperatorVersion = "dev"
+
+// Metric Export is the metadata at the top of the log of each metric container.
+// Fields that are known when the container runs (e.g., the node) are filled by
+// the entrypoint, so they are shell expressions here.
 type MetricExport struct {
 
+	// Schema and operator versions
+	Version         int    `json:"version"`
+	OperatorVersion string `json:"operatorVersion,omitempty"`
+
 	// Global
 	Pods int32 `json:"pods"`
 
-	// Application
-	ApplicationImage   string `json:"applicationImage,omitempty"`
-	ApplicationCommand string `json:"applicationCommand,omitempty"`
-
-	// Storage
-	StorageVolumePath          string `json:"storageVolumePath,omitempty"`
-	StorageVolumeHostPath      string `json:"storageVolumeHostPath,omitempty"`
-	StorageVolumeSecretName    string `json:"storageVolumeSecretName,omitempty"`
-	StorageVolumeClaimName     string `json:"storageVolumeClaimName,omitempty"`
-	StorageVolumeConfigMapName string `json:"storageVolumeConfigMapName,omitempty"`
+	// The MetricSet, and a hash of its spec to find runs of the same spec
+	MetricSet string `json:"metricSet,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	UID       string `json:"uid,omitempty"`
+	SpecHash  string `json:"specHash,omitempty"`
 
 	// Metric
 	MetricName        string                          `json:"metricName,omitempty"`
@@ -45,6 +76,84 @@ type MetricExport struct {
 	MetricType        string                          `json:"metricType,omitempty"`
 	MetricOptions     map[string]intstr.IntOrString   `json:"metricOptions,omitempty"`
 	MetricListOptions map[string][]intstr.IntOrString `json:"metricListOptions,omitempty"`
+
+	// Addons of the metric, resources, and images (with digests when resolved)
+	Addons       []AddonExport     `json:"addons,omitempty"`
+	Resources    *ResourcesExport  `json:"resources,omitempty"`
+	PodResources map[string]string `json:"podResources,omitempty"`
+	Images       []ImageExport     `json:"images,omitempty"`
+
+	// Where the container runs, filled by the entrypoint
+	Node          string `json:"node,omitempty"`
+	Kernel        string `json:"kernel,omitempty"`
+	CPUModel      string `json:"cpuModel,omitempty"`
+	Pod           string `json:"pod,omitempty"`
+	PodUID        string `json:"podUid,omitempty"`
+	JobSet        string `json:"jobSet,omitempty"`
+	ReplicatedJob string `json:"replicatedJob,omitempty"`
+	JobIndex      string `json:"jobIndex,omitempty"`
+	PodIndex      string `json:"podIndex,omitempty"`
+}
+
+// AddonExport is an addon of a metric, with its options
+type AddonExport struct {
+	Name        string                          `json:"name"`
+	Options     map[string]intstr.IntOrString   `json:"options,omitempty"`
+	ListOptions map[string][]intstr.IntOrString `json:"listOptions,omitempty"`
+}
+
+// ResourcesExport has the limits and requests of the metric containers
+type ResourcesExport struct {
+	Limits   map[string]string `json:"limits,omitempty"`
+	Requests map[string]string `json:"requests,omitempty"`
+}
+
+// ImageExport is an image reference and the digest it resolved to
+type ImageExport struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// Environment variables (from the downward API) for the fields filled by the entrypoint
+const (
+	NodeEnv          = "METRICS_OPERATOR_NODE"
+	PodEnv           = "METRICS_OPERATOR_POD"
+	PodUIDEnv        = "METRICS_OPERATOR_POD_UID"
+	JobSetEnv        = "METRICS_OPERATOR_JOBSET"
+	ReplicatedJobEnv = "METRICS_OPERATOR_REPLICATED_JOB"
+	JobIndexEnv      = "METRICS_OPERATOR_JOB_INDEX"
+)
+
+// SetRuntime sets the fields filled by the entrypoint to shell expressions.
+// The metadata is echoed in double quotes, so they can't use quotes.
+func (e *MetricExport) SetRuntime() {
+	e.Node = "${" + NodeEnv + "}"
+	e.Pod = "${" + PodEnv + "}"
+	e.PodUID = "${" + PodUIDEnv + "}"
+	e.JobSet = "${" + JobSetEnv + "}"
+	e.ReplicatedJob = "${" + ReplicatedJobEnv + "}"
+	e.JobIndex = "${" + JobIndexEnv + "}"
+	e.PodIndex = "${JOB_COMPLETION_INDEX}"
+	e.Kernel = "$(uname -r)"
+	e.CPUModel = "$(grep -s -m 1 -i model.name /proc/cpuinfo | cut -d: -f2- | xargs)"
+}
+
+// FormatOptions writes options and list options on one line, sorted by name,
+// e.g., commands=osu_bw;osu_latency,tasks=2
+func FormatOptions(options map[string]intstr.IntOrString, listOptions map[string][]intstr.IntOrString) string {
+	formatted := []string{}
+	for name, value := range options {
+		formatted = append(formatted, fmt.Sprintf("%s=%s", name, value.String()))
+	}
+	for name, values := range listOptions {
+		items := []string{}
+		for _, value := range values {
+			items = append(items, value.String())
+		}
+		formatted = append(formatted, fmt.Sprintf("%s=%s", name, strings.Join(items, ";")))
+	}
+	sort.Strings(formatted)
+	return strings.Join(formatted, ",")
 }
 
 // Interactive returns a sleep infinity if interactive is true
@@ -54,3 +163,15 @@ func Interactive(interactive bool) string {
 	}
 	return ""
 }
+
+// SplitInteractive removes a trailing sleep infinity from a post block, so
+// more can be added before it. It returns true if it was removed.
+func SplitInteractive(post string) (bool, string) {
+	if strings.HasSuffix(post, "sleep infinity\n") {
+		updated := strings.Split(post, "\n")
+		// This is actually two lines
+		updated = updated[:len(updated)-2]
+		return true, strings.Join(updated, "\n")
+	}
+	return false, post
+}
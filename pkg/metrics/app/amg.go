@@ -43,7 +43,7 @@ func (m *AMG) SetOptions(metric *api.Metric) {
 	m.Container = amgContainer
 
 	// Set user defined values or fall back to defaults
-	m.Prefix = "mpirun --hostfile ./hostlist.txt"
+	m.Prefix = m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt"})
 	m.Command = "amg"
 	m.Workdir = "/opt/AMG"
 	m.SetDefaultOptions(metric)
@@ -51,7 +51,7 @@ func (m *AMG) SetOptions(metric *api.Metric) {
 
 // Validate that we can run AMG
 func (n AMG) Validate(spec *api.MetricSet) bool {
-	return spec.Spec.Pods >= 2
+	return n.Pods() >= 2
 }
 
 // Exported options and list options
@@ -47,7 +47,8 @@ func (m *BDAS) SetOptions(metric *api.Metric) {
 
 	// Set user defined values or fall back to defaults
 	m.Prefix = "/bin/bash"
-	m.Command = "mpirun --allow-run-as-root -np 4 --hostfile ./hostlist.txt Rscript /opt/bdas/benchmarks/r/princomp.r 250 50"
+	mpirun := m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt", NP: "4"})
+	m.Command = mpirun + " Rscript /opt/bdas/benchmarks/r/princomp.r 250 50"
 	m.Workdir = "/opt/bdas/benchmarks/r"
 
 	// Examples from guide
@@ -80,15 +81,7 @@ echo "%s"
 
 # We need ip addresses for openmpi
 mv ./hostlist.txt ./hostnames.txt
-for h in $(cat ./hostnames.txt); do
-  if [[ "${h}" != "" ]]; then
-	if [[ "${h}" == "$(hostname)" ]]; then
-		hostname -I | awk '{print $1}' >> hostlist.txt
-	else
-		host $h | cut -d ' ' -f 4 >> hostlist.txt
-	fi
-  fi  
-done
+%s
 echo "Hostlist"
 cat ./hostlist.txt
 `
@@ -99,7 +92,7 @@ echo "%s"
 `
 	command := fmt.Sprintf("%s ./problem.sh", m.Prefix)
 	interactive := metadata.Interactive(spec.Spec.Logging.Interactive)
-	preBlock = prefix + fmt.Sprintf(preBlock, metadata.Separator)
+	preBlock = prefix + fmt.Sprintf(preBlock, metadata.Separator, m.ConvertHostnames())
 	postBlock = fmt.Sprintf(postBlock, metadata.CollectionEnd, interactive)
 
 	// Entrypoint for the launcher
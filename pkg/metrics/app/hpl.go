@@ -9,6 +9,8 @@ package application
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -348,14 +350,15 @@ echo "%s"
 echo "%s"
 %s
 `
-	command := fmt.Sprintf("mpirun --allow-run-as-root --hostfile ./hostlist.txt -np $np %s xhpl", m.mpiargs)
+	mpirun := m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt", NP: "$np", Flags: m.mpiargs})
+	command := fmt.Sprintf("%s xhpl", mpirun)
 	interactive := metadata.Interactive(spec.Spec.Logging.Interactive)
 	preBlock = prefix + fmt.Sprintf(
 		preBlock,
 		m.memory,
 		memoryCmd,
 		m.tasks,
-		spec.Spec.Pods,
+		m.Pods(),
 		m.blocksize,
 		m.ratio,
 		m.row_or_colmajor_pmapping,
@@ -371,7 +374,7 @@ echo "%s"
 		m.utransposed,
 		m.memAlignment,
 		inputData,
-		metrics.TemplateConvertHostnames,
+		m.ConvertHostnames(),
 		metadata.Separator,
 	)
 	postBlock = fmt.Sprintf(postBlock, metadata.CollectionEnd, interactive)
@@ -402,6 +405,33 @@ echo "%s"
 
 }
 
+// A result line, e.g., "WR11C2R4  10000  192  1  4  16.05  4.1553e+01"
+// with the variant (T/V), N, NB, P, Q, time, and Gflops
+var hplResult = regexp.MustCompile(`(?m)^(W\S+)\s+(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s+(\S+)\s+(\S+)\s*$`)
+
+// ParseResults returns the Gflops and time of each problem
+func (m HPL) ParseResults(log string) []metrics.Sample {
+	samples := []metrics.Sample{}
+	for _, section := range metadata.Sections(log) {
+		for _, match := range hplResult.FindAllStringSubmatch(section, -1) {
+			seconds, err := strconv.ParseFloat(match[6], 64)
+			if err != nil {
+				continue
+			}
+			gflops, err := strconv.ParseFloat(match[7], 64)
+			if err != nil {
+				continue
+			}
+			labels := map[string]string{"variant": match[1], "n": match[2], "nb": match[3], "p": match[4], "q": match[5]}
+			samples = append(samples,
+				metrics.Sample{Name: "gflops", Labels: labels, Value: gflops, Unit: "Gflop/s", Better: metrics.BetterHigher},
+				metrics.Sample{Name: "time_seconds", Labels: labels, Value: seconds, Unit: "s", Merge: metrics.MergeMax, Better: metrics.BetterLower},
+			)
+		}
+	}
+	return samples
+}
+
 func init() {
 	base := metrics.BaseMetric{
 		Identifier: hplIdentifier,
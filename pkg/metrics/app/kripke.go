@@ -41,7 +41,7 @@ func (m *Kripke) SetOptions(metric *api.Metric) {
 	m.Container = kripkeContainer
 
 	// Set user defined values or fall back to defaults
-	m.Prefix = "mpirun --hostfile ./hostlist.txt"
+	m.Prefix = m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt"})
 	m.Command = "kripke"
 	m.Workdir = "/opt/kripke"
 	m.SetDefaultOptions(metric)
@@ -49,7 +49,7 @@ func (m *Kripke) SetOptions(metric *api.Metric) {
 
 // Validate that we can run Kripke
 func (n Kripke) Validate(spec *api.MetricSet) bool {
-	return spec.Spec.Pods >= 2
+	return n.Pods() >= 2
 }
 
 // Exported options and list options
@@ -42,7 +42,7 @@ func (m *Laghos) SetOptions(metric *api.Metric) {
 
 	// Set user defined values or fall back to defaults
 	m.Prefix = "/bin/bash"
-	m.Command = "mpirun -np 4 --hostfile ./hostlist.txt ./laghos"
+	m.Command = m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt", NP: "4"}) + " ./laghos"
 	m.Workdir = "/workflow/laghos"
 	m.SetDefaultOptions(metric)
 }
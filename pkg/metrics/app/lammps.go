@@ -54,21 +54,22 @@ func (m *Lammps) SetOptions(metric *api.Metric) {
 	// Set user defined values or fall back to defaults
 	// This is a more manual approach that puts the user in charge of determining the entire command
 	// This more closely matches what we might do on HPC :)
-	m.Command = "mpirun --hostfile ./hostlist.txt -np 2 --map-by socket lmp -v x 2 -v y 2 -v z 2 -in in.reaxc.hns -nocite"
+	mpirun := m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt", NP: "2", Map: "socket"})
+	m.Command = mpirun + " lmp -v x 2 -v y 2 -v z 2 -in in.reaxc.hns -nocite"
 	m.Workdir = "/opt/lammps/examples/reaxff/HNS"
 	m.SetDefaultOptions(metric)
 }
 
 // LAMMPS can be run on one node
 func (m Lammps) Validate(spec *api.MetricSet) bool {
-	return true
+	return m.ValidateMPI()
 }
 
 // Exported options and list options
 func (m Lammps) Options() map[string]intstr.IntOrString {
 	values := map[string]intstr.IntOrString{
-		"command":    intstr.FromString(m.Command),
-		"workdir":    intstr.FromString(m.Workdir),
+		"command":     intstr.FromString(m.Command),
+		"workdir":     intstr.FromString(m.Workdir),
 		"soleTenancy": intstr.FromString("false"),
 	}
 	if m.SoleTenancy {
@@ -40,7 +40,7 @@ func (m *Nekbone) SetOptions(metric *api.Metric) {
 	m.Summary = nekboneSummary
 	m.Container = nekboneContainer
 	m.Prefix = "/bin/bash"
-	m.Command = "mpiexec --hostfile ./hostlist.txt -np 2 ./nekbone"
+	m.Command = m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt", NP: "2"}) + " ./nekbone"
 	m.Workdir = "/root/nekbone-3.0/test/example2"
 	m.SetDefaultOptions(metric)
 }
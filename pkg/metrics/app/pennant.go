@@ -41,7 +41,7 @@ func (m *Pennant) SetOptions(metric *api.Metric) {
 	m.Summary = pennantSummary
 
 	// Set user defined values or fall back to defaults
-	m.Prefix = "mpirun --hostfile ./hostlist.txt"
+	m.Prefix = m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt"})
 	m.Command = "pennant /opt/pennant/test/sedovsmall/sedovsmall.pnt"
 	m.Workdir = "/opt/pennant/test"
 	m.SetDefaultOptions(metric)
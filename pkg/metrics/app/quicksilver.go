@@ -41,7 +41,7 @@ func (m *Quicksilver) SetOptions(metric *api.Metric) {
 	m.Container = qsContainer
 
 	// Set user defined values or fall back to defaults
-	m.Prefix = "mpirun --hostfile ./hostlist.txt"
+	m.Prefix = m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt"})
 	m.Command = "qs /opt/quicksilver/Examples/CORAL2_Benchmark/Problem1/Coral2_P1.inp"
 	m.Workdir = "/opt/quicksilver/Examples"
 	m.SetDefaultOptions(metric)
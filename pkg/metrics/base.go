@@ -31,6 +31,15 @@ type BaseMetric struct {
 	// If we ask for sole tenancy, we assign 1 pod / hostname
 	SoleTenancy bool
 
+	// Number of pods for the metric
+	pods int32
+
+	// Seconds the metric entrypoint can run (0 is no timeout)
+	timeout int32
+
+	// User templates that override entrypoint blocks
+	templates []api.EntrypointTemplate
+
 	// A metric can have one or more addons
 	Addons map[string]*addons.Addon
 }
@@ -71,6 +80,39 @@ func (m *BaseMetric) Image() string {
 	return m.Container
 }
 
+// SetPods sets the metric pods, falling back to the MetricSet pods
+func (m *BaseMetric) SetPods(metric *api.Metric, set *api.MetricSet) {
+	m.pods = set.Spec.Pods
+	if metric.Pods > 0 {
+		m.pods = metric.Pods
+	}
+}
+
+// Pods returns the number of pods for the metric
+func (m BaseMetric) Pods() int32 {
+	return m.pods
+}
+
+// SetTimeout sets the seconds the metric entrypoint can run
+func (m *BaseMetric) SetTimeout(metric *api.Metric) {
+	m.timeout = metric.Timeout
+}
+
+// Timeout returns the seconds the metric entrypoint can run
+func (m BaseMetric) Timeout() int32 {
+	return m.timeout
+}
+
+// SetTemplates sets user templates that override entrypoint blocks
+func (m *BaseMetric) SetTemplates(metric *api.Metric) {
+	m.templates = metric.Templates
+}
+
+// Templates returns user templates that override entrypoint blocks
+func (m BaseMetric) Templates() []api.EntrypointTemplate {
+	return m.templates
+}
+
 // Return container resources for the metric container
 func (m BaseMetric) Resources() *api.ContainerResources {
 	return m.ResourceSpec
@@ -107,7 +149,7 @@ func (m *BaseMetric) ReplicatedJobs(spec *api.MetricSet) ([]*jobset.ReplicatedJo
 	js := []*jobset.ReplicatedJob{}
 
 	// An empty jobname will default to "m" the ReplicatedJobName provided by the operator
-	rj, err := AssembleReplicatedJob(spec, false, spec.Spec.Pods, spec.Spec.Pods, "", m.SoleTenancy)
+	rj, err := AssembleReplicatedJob(spec, false, m.pods, m.pods, "", m.SoleTenancy)
 	if err != nil {
 		return js, err
 	}
@@ -197,6 +239,9 @@ func (m BaseMetric) AddAddons(
 		rj.Template.Spec.Template.Spec.Containers = rjContainers
 		rj.Template.Spec.Template.Spec.InitContainers = initContainers
 
+		// Pod level security depends on the containers for the replicated job
+		setPodSecurity(rj, containers)
+
 		// And volumes!
 		// containerSpecs are used to generate our metric entrypoint volumes
 		// volumes indicate existing volumes
@@ -8,10 +8,13 @@ SPDX-License-Identifier: MIT
 package metrics
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
 	"github.com/converged-computing/metrics-operator/pkg/specs"
 )
 
@@ -72,33 +75,18 @@ func getReplicatedJobContainers(
 			Stdin:           true,
 			TTY:             true,
 			Command:         command,
-			SecurityContext: &corev1.SecurityContext{
-				Privileged: &cs.Attributes.SecurityContext.Privileged,
-			},
-		}
-
-		// Add capabilities to the security context
-		caps := []corev1.Capability{}
-
-		// Should we allow sharing the process namespace?
-		if cs.Attributes.SecurityContext.AllowPtrace {
-			caps = append(caps, capPtrace)
-		}
-		if cs.Attributes.SecurityContext.AllowAdmin {
-			caps = append(caps, capAdmin)
+			SecurityContext: getSecurityContext(&cs.Attributes.SecurityContext),
 		}
-		newContainer.SecurityContext.Capabilities = &corev1.Capabilities{Add: caps}
 
 		// Only add the working directory if it's defined
 		if cs.WorkingDir != "" {
 			newContainer.WorkingDir = cs.WorkingDir
 		}
 
-		// Ports and environment (add when needed)
+		// Ports and environment, which has the pod identity for metadata
 		ports := []corev1.ContainerPort{}
-		envars := []corev1.EnvVar{}
 		newContainer.Ports = ports
-		newContainer.Env = envars
+		newContainer.Env = getIdentityEnv()
 		newContainer.Resources = resources
 
 		// Add as an init container, or a sidecar container
@@ -113,3 +101,132 @@ func getReplicatedJobContainers(
 	logger.Infof("🟪️ Adding %d containers\n", len(containers))
 	return containers, initContainers, nil
 }
+
+// getIdentityEnv provides the node and pod identity (from the downward API)
+// for the metadata that is filled when the entrypoint runs
+func getIdentityEnv() []corev1.EnvVar {
+	envars := []corev1.EnvVar{}
+	for _, field := range [][]string{
+		{metadata.NodeEnv, "spec.nodeName"},
+		{metadata.PodEnv, "metadata.name"},
+		{metadata.PodUIDEnv, "metadata.uid"},
+		{metadata.JobSetEnv, fmt.Sprintf("metadata.labels['%s']", jobset.JobSetNameKey)},
+		{metadata.ReplicatedJobEnv, fmt.Sprintf("metadata.labels['%s']", jobset.ReplicatedJobNameKey)},
+		{metadata.JobIndexEnv, fmt.Sprintf("metadata.labels['%s']", jobset.JobIndexKey)},
+	} {
+		envars = append(envars, corev1.EnvVar{
+			Name:      field[0],
+			ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: field[1]}},
+		})
+	}
+	return envars
+}
+
+// getSecurityContext maps the metric security context to the container
+func getSecurityContext(sc *api.SecurityContext) *corev1.SecurityContext {
+
+	securityContext := &corev1.SecurityContext{
+		Privileged:               &sc.Privileged,
+		RunAsUser:                sc.RunAsUser,
+		RunAsGroup:               sc.RunAsGroup,
+		RunAsNonRoot:             sc.RunAsNonRoot,
+		AllowPrivilegeEscalation: sc.AllowPrivilegeEscalation,
+		ReadOnlyRootFilesystem:   &sc.ReadOnlyRootFilesystem,
+	}
+
+	// Add capabilities to the security context
+	caps := []corev1.Capability{}
+
+	// Should we allow sharing the process namespace?
+	if sc.AllowPtrace {
+		caps = append(caps, capPtrace)
+	}
+	if sc.AllowAdmin {
+		caps = append(caps, capAdmin)
+	}
+	for _, c := range sc.Capabilities.Add {
+		capability := corev1.Capability(c)
+		if !hasCapability(caps, capability) {
+			caps = append(caps, capability)
+		}
+	}
+	drop := []corev1.Capability{}
+	for _, c := range sc.Capabilities.Drop {
+		drop = append(drop, corev1.Capability(c))
+	}
+	securityContext.Capabilities = &corev1.Capabilities{Add: caps, Drop: drop}
+
+	// Seccomp is a field on the security context, AppArmor is an annotation
+	if sc.SeccompProfile.Type != "" {
+		securityContext.SeccompProfile = &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileType(sc.SeccompProfile.Type),
+		}
+		if sc.SeccompProfile.Type == string(corev1.SeccompProfileTypeLocalhost) {
+			securityContext.SeccompProfile.LocalhostProfile = &sc.SeccompProfile.LocalhostProfile
+		}
+	}
+	return securityContext
+}
+
+// hasCapability determines if a capability is already in the list
+func hasCapability(caps []corev1.Capability, capability corev1.Capability) bool {
+	for _, c := range caps {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// getAppArmorAnnotation returns the annotation value for an AppArmor profile
+func getAppArmorAnnotation(profile api.Profile) string {
+	switch profile.Type {
+	case "RuntimeDefault":
+		return corev1.AppArmorBetaProfileRuntimeDefault
+	case "Unconfined":
+		return corev1.AppArmorBetaProfileNameUnconfined
+	case "Localhost":
+		return corev1.AppArmorBetaProfileNamePrefix + profile.LocalhostProfile
+	}
+	return ""
+}
+
+// setPodSecurity sets pod level security (fsGroup) and AppArmor annotations
+// for the containers that are part of a replicated job.
+func setPodSecurity(
+	rj *jobset.ReplicatedJob,
+	containerSpecs []specs.ContainerSpec,
+) {
+
+	// Annotations are shared across replicated jobs, so we need our own copy
+	annotations := map[string]string{}
+	for key, value := range rj.Template.Spec.Template.ObjectMeta.Annotations {
+		annotations[key] = value
+	}
+
+	var fsGroup *int64
+	for _, cs := range containerSpecs {
+		if cs.JobName != "" && cs.JobName != rj.Name {
+			continue
+		}
+		sc := cs.Attributes.SecurityContext
+		if sc.FSGroup != nil {
+			fsGroup = sc.FSGroup
+		}
+		profile := getAppArmorAnnotation(sc.AppArmorProfile)
+		if profile != "" {
+			annotations[corev1.AppArmorBetaContainerAnnotationKeyPrefix+cs.Name] = profile
+		}
+	}
+	rj.Template.Spec.Template.ObjectMeta.Annotations = annotations
+
+	// Keep any pod security context that is already set
+	if fsGroup != nil {
+		podSC := &corev1.PodSecurityContext{}
+		if rj.Template.Spec.Template.Spec.SecurityContext != nil {
+			podSC = rj.Template.Spec.Template.Spec.SecurityContext.DeepCopy()
+		}
+		podSC.FSGroup = fsGroup
+		rj.Template.Spec.Template.Spec.SecurityContext = podSC
+	}
+}
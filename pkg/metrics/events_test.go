@@ -0,0 +1,128 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/io"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+)
+
+// TestEvents runs an entrypoint that times out, and splits its log
+func TestEvents(t *testing.T) {
+	entrypoint := specs.EntrypointScript{
+		Pre: fmt.Sprintf("echo %s", metadata.CollectionStart),
+		Command: fmt.Sprintf(
+			"for i in 1 2; do echo '  %s'; echo \"run ${i}\"; sleep 0.2; done\necho %s\nsleep 10",
+			metadata.Separator, metadata.Separator,
+		),
+		Post:    fmt.Sprintf("echo %s", metadata.CollectionEnd),
+		Timeout: 2,
+		Metric:  "app-custom",
+	}
+	out, err := exec.Command("bash", "-c", entrypoint.WriteScript()).CombinedOutput()
+	exitError, ok := err.(*exec.ExitError)
+	if !ok || exitError.ExitCode() != int(metadata.TimeoutExitCode) {
+		t.Fatalf("expected the timeout exit code: %v\n%s", err, out)
+	}
+
+	log := metadata.SplitLog(string(out))
+	if len(log.Sections) != 3 || log.Sections[1].Output != "run 2" || !log.TimedOut {
+		t.Fatalf("unexpected sections %+v\n%s", log.Sections, out)
+	}
+	for _, event := range log.Events {
+		if event.Metric != "app-custom" {
+			t.Errorf("unexpected event %+v", event)
+		}
+	}
+	if log.Sections[0].Duration() < 100*time.Millisecond || log.Sections[2].Duration() < time.Second || log.Duration() < time.Second {
+		t.Errorf("unexpected durations %s, %s, %s\n%s", log.Sections[0].Duration(), log.Sections[2].Duration(), log.Duration(), out)
+	}
+	if !strings.Contains(string(out), `"event":"end"`) || log.Events[len(log.Events)-1].Iteration != 3 {
+		t.Errorf("expected the end event after the timeout:\n%s", out)
+	}
+}
+
+// sdkParser parses a log (on stdin) with the parser of a metric in the Python SDK,
+// without the Kubernetes client, which the parsers don't use
+var sdkParser = `
+import json, sys
+from unittest import mock
+for name in ["kubernetes", "kubernetes.client", "kubernetes.client.api", "kubernetes.client.exceptions",
+             "kubernetes.client.models", "kubernetes.client.models.v1_pod_list"]:
+    sys.modules[name] = mock.MagicMock()
+import metricsoperator.metrics as metrics
+parser = metrics.get_metric(sys.argv[1])()
+print(json.dumps(parser.parse_log(sys.stdin.read())["data"]))
+`
+
+// TestEventsSDK runs the entrypoint of io-sysstat (with events) and checks the
+// Python SDK still parses each timepoint
+func TestEventsSDK(t *testing.T) {
+	_, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 is needed to run the SDK parser")
+	}
+	spec := &api.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+		Spec: api.MetricSetSpec{
+			Pods: 1,
+			Metrics: []api.Metric{{
+				Name:    "io-sysstat",
+				Options: map[string]intstr.IntOrString{"completions": intstr.FromInt(2), "rate": intstr.FromInt(0)},
+			}},
+		},
+	}
+	spec.Validate()
+	m, err := metrics.GetMetric(&spec.Spec.Metrics[0], spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := metrics.MetricSet{}
+	set.Add(&m)
+	_, containerSpecs, err := metrics.GetJobSet(spec, &set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if containerSpecs[0].EntrypointScript.Metric != "io-sysstat" {
+		t.Fatalf("expected events for io-sysstat")
+	}
+
+	// iostat writes one JSON document for each timepoint
+	script := strings.ReplaceAll(containerSpecs[0].EntrypointScript.WriteScript(), "iostat -dxm -o JSON", `echo '{"sysstat": {"hosts": []}}'`)
+	out, err := exec.Command("bash", "-c", script).CombinedOutput()
+	if err != nil || !strings.Contains(string(out), `{"event":"timepoint"`) {
+		t.Fatalf("expected events in the output: %v\n%s", err, out)
+	}
+
+	parse := exec.Command("python3", "-c", sdkParser, "io-sysstat")
+	parse.Dir = filepath.Join("..", "..", "sdk", "python", "v1alpha2")
+	parse.Stdin = strings.NewReader(string(out))
+	parsed, err := parse.Output()
+	if err != nil {
+		t.Fatalf("the SDK parser failed: %v\n%s", err, out)
+	}
+	data := []map[string]interface{}{}
+	err = json.Unmarshal(parsed, &data)
+	if err != nil || len(data) != 3 {
+		t.Errorf("expected the three timepoints to parse: %v\n%s", err, parsed)
+	}
+}
@@ -0,0 +1,329 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics/hosts"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+)
+
+// Launchers for a LauncherWorker metric, chosen with the "launcher" option
+const (
+	LauncherMPI  = "mpi"
+	LauncherFlux = "flux"
+)
+
+var (
+	// FluxMountPath is where the curve certificate of the MetricSet is mounted
+	FluxMountPath  = "/metrics_operator_flux"
+	FluxCurveCert  = "curve.cert"
+	fluxVolumeName = "metrics-operator-flux"
+
+	// Brokers use a local config directory and socket
+	fluxConfigDir = "/tmp/metrics-operator-flux"
+	fluxURI       = "local:///tmp/metrics-operator-flux/local"
+	fluxPort      = 8050
+
+	// ZeroMQ base 85 alphabet (https://rfc.zeromq.org/spec/32/)
+	z85Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
+	// Flags of flux submit that metrics can be given, and if they take a value
+	fluxSubmitFlags = map[string]bool{
+		"-n":               true,
+		"--ntasks":         true,
+		"-N":               true,
+		"--nodes":          true,
+		"-c":               true,
+		"--cores-per-task": true,
+		"-g":               true,
+		"--gpus-per-task":  true,
+		"--tasks-per-node": true,
+		"--gpus-per-node":  true,
+		"-o":               true,
+		"--setopt":         true,
+		"-S":               true,
+		"--setattr":        true,
+		"-t":               true,
+		"--time-limit":     true,
+		"--env":            true,
+		"--requires":       true,
+		"--exclusive":      false,
+		"-u":               false,
+		"--unbuffered":     false,
+		"-l":               false,
+		"--label-io":       false,
+	}
+
+	// Flags of MPI launchers with the same meaning for flux submit
+	fluxTranslatedFlags = map[string]string{
+		"-np":        "-n",
+		"--np":       "-n",
+		"-ppn":       "--tasks-per-node",
+		"-npernode":  "--tasks-per-node",
+		"--npernode": "--tasks-per-node",
+	}
+)
+
+// A fluxMetric runs its command in a Flux instance across its pods
+type fluxMetric interface {
+	FluxEnabled() bool
+}
+
+// FluxSecretName is the name of the Secret with the curve certificate of a MetricSet
+func FluxSecretName(spec *api.MetricSet) string {
+	return spec.Name + "-flux"
+}
+
+// UsesFlux determines if any metric of the MetricSet runs in a Flux instance
+func UsesFlux(set *MetricSet) bool {
+	for _, metric := range set.Metrics() {
+		m, ok := (*metric).(fluxMetric)
+		if ok && m.FluxEnabled() {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateCurveCert generates the curve certificate shared by the brokers,
+// in the format written by flux keygen
+func GenerateCurveCert() (map[string][]byte, error) {
+	secret := make([]byte, curve25519.ScalarSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, err
+	}
+	public, err := curve25519.X25519(secret, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	cert := fmt.Sprintf(`#   ****  Generated by the metrics operator  ****
+#   ZeroMQ CURVE **Secret** Certificate
+#   DO NOT PROVIDE THIS FILE TO OTHER USERS nor change its permissions.
+
+metadata
+    name = "metrics-operator"
+curve
+    public-key = "%s"
+    secret-key = "%s"
+`, z85Encode(public), z85Encode(secret))
+	return map[string][]byte{FluxCurveCert: []byte(cert)}, nil
+}
+
+// z85Encode encodes data (a multiple of 4 bytes) with the ZeroMQ base 85 alphabet
+func z85Encode(data []byte) string {
+	var encoded strings.Builder
+	for i := 0; i+4 <= len(data); i += 4 {
+		value := binary.BigEndian.Uint32(data[i : i+4])
+		chunk := make([]byte, 5)
+		for j := 4; j >= 0; j-- {
+			chunk[j] = z85Alphabet[value%85]
+			value /= 85
+		}
+		encoded.Write(chunk)
+	}
+	return encoded.String()
+}
+
+// fluxBrokerConfig is the broker config for the pods of the jobs. The first
+// host (the launcher) is rank 0.
+func fluxBrokerConfig(generator hosts.Generator, jobs ...hosts.Job) string {
+	ranges := []string{}
+	for _, job := range jobs {
+		for i := int32(0); i < job.Replicas && job.Pods > 0; i++ {
+			ranges = append(ranges, generator.HostRange(job.Name, i, job.Pods))
+		}
+	}
+	return fmt.Sprintf(`[access]
+allow-guest-user = true
+allow-root-owner = true
+
+[bootstrap]
+curve_cert = "%s/%s"
+default_port = %d
+default_bind = "tcp://eth0:%%p"
+default_connect = "tcp://%%h.%s:%%p"
+hosts = [
+	{ host="%s" },
+]
+`, FluxMountPath, FluxCurveCert, fluxPort, generator.Domain(), strings.Join(ranges, ","))
+}
+
+// fluxBootstrapScript starts a broker on each pod with the config, and waits
+// (up to the timeout) for the instance to be ready. Flux must be installed in
+// the metric container. Resources are discovered by each broker.
+func fluxBootstrapScript(config string, nodes int32, timeout int32) string {
+	template := `
+# Start a Flux broker on each pod, and wait for the instance
+export FLUX_NODES=%[2]d
+mkdir -p %[3]s
+cat <<'EOT' > %[3]s/broker.toml
+%[1]sEOT
+echo "🌀 Starting Flux broker with config %[3]s/broker.toml"
+cat %[3]s/broker.toml
+flux broker --config-path=%[3]s \
+  -Sbroker.rc2_none=1 \
+  -Sbroker.quorum=%[2]d \
+  -Srundir=%[3]s \
+  -Sstatedir=%[3]s \
+  -Slocal-uri=%[4]s \
+  -Stbon.connect_timeout=5s \
+  -Slog-stderr-level=6 \
+  -Slog-stderr-mode=local &
+export FLUX_URI=%[4]s
+metrics_operator_start=$(date +%%s)
+until flux uptime > /dev/null 2>&1; do
+	if [[ $(( $(date +%%s) - metrics_operator_start )) -ge %[5]d ]]; then
+		echo "%[6]s: the Flux broker on $(hostname) did not start within %[5]d seconds"
+		exit 1
+	fi
+	sleep 1
+done
+flux uptime
+
+# Commands are submitted to the instance, and the job eventlog is saved in the output.
+# The first argument is flags for flux submit: nodes and tasks default to one task per node.
+function metrics_operator_flux_run() {
+	local flags="$1"
+	shift
+	if [[ " ${flags} " != *" -N "* ]] && [[ " ${flags} " != *" -n "* ]]; then
+		flags="-N ${FLUX_NODES} ${flags}"
+	fi
+	echo "flux submit ${flags} $@"
+	local jobid=$(flux submit ${flags} "$@")
+	flux job attach ${jobid}
+	local retval=$?
+	echo "%[7]s ${jobid}"
+	flux job eventlog ${jobid}
+	echo "%[8]s ${jobid}"
+	return ${retval}
+}
+export -f metrics_operator_flux_run
+`
+	return fmt.Sprintf(
+		template,
+		config,
+		nodes,
+		fluxConfigDir,
+		fluxURI,
+		timeout,
+		metadata.RendezvousFailed,
+		metadata.FluxEventlogStart,
+		metadata.FluxEventlogEnd,
+	)
+}
+
+// Flux submits commands to the Flux instance of the metric. It implements
+// MPILauncher so metrics build their commands the same way for Flux.
+type Flux struct{}
+
+func (l Flux) Name() string {
+	return LauncherFlux
+}
+
+// Hostfile is not needed, the instance knows its resources
+func (l Flux) Hostfile(job MPIJob) string {
+	return ""
+}
+
+// Command submits to the instance. Flags of MPI launchers are translated
+// (ValidateFlags rejects those that can't be).
+func (l Flux) Command(job MPIJob) string {
+	flags := []string{}
+	if job.NP != "" {
+		flags = append(flags, "-n", job.NP)
+	}
+	if job.PPN != "" {
+		flags = append(flags, fmt.Sprintf("--tasks-per-node=%s", job.PPN))
+	}
+	if job.Bind == "core" {
+		flags = append(flags, "--exclusive")
+	}
+	extra, err := FluxFlags(job.Flags)
+	if err != nil {
+		extra = job.Flags
+	}
+	return fmt.Sprintf("metrics_operator_flux_run \"%s\"", joinFlags(append(flags, extra)...))
+}
+
+// FluxFlags translates flags of MPI launchers (e.g., -np 4, or --map-by ppr:2:node)
+// to flags of flux submit. A flag that flux submit doesn't have is an error.
+func FluxFlags(flags string) (string, error) {
+	fields := strings.Fields(flags)
+	translated := []string{}
+	for i := 0; i < len(fields); i++ {
+		flag, value, hasValue := strings.Cut(fields[i], "=")
+		if !strings.HasPrefix(flag, "-") {
+			return "", fmt.Errorf("argument %s of flags %q is not a flag for flux submit", fields[i], flags)
+		}
+		if equivalent, ok := fluxTranslatedFlags[flag]; ok {
+			flag = equivalent
+		}
+		takesValue, ok := fluxSubmitFlags[flag]
+		if flag == "--map-by" {
+			takesValue, ok = true, true
+		}
+		if !ok {
+			return "", fmt.Errorf("flag %s of flags %q is not valid for flux submit", flag, flags)
+		}
+		if !takesValue {
+			if hasValue {
+				return "", fmt.Errorf("flag %s of flags %q does not take a value", flag, flags)
+			}
+			translated = append(translated, flag)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(fields) {
+				return "", fmt.Errorf("flag %s of flags %q needs a value", flag, flags)
+			}
+			i++
+			value = fields[i]
+		}
+
+		// Only a number of processes per node can be mapped, e.g., ppr:2:node
+		if flag == "--map-by" {
+			parts := strings.Split(value, ":")
+			if len(parts) != 3 || parts[0] != "ppr" || parts[2] != "node" {
+				return "", fmt.Errorf("--map-by %s of flags %q has no equivalent for flux submit", value, flags)
+			}
+			flag, value = "--tasks-per-node", parts[1]
+		}
+		translated = append(translated, flag, value)
+	}
+	return strings.Join(translated, " "), nil
+}
+
+// setFlux mounts the curve certificate into the containers of a metric that
+// runs in a Flux instance. Brokers find their rank by short hostname.
+func setFlux(
+	spec *api.MetricSet,
+	m Metric,
+	jobs []*jobset.ReplicatedJob,
+	containerSpecs []*specs.ContainerSpec,
+) {
+	fm, ok := m.(fluxMetric)
+	if !ok || !fm.FluxEnabled() {
+		return
+	}
+	mountSecret(jobs, containerSpecs, fluxVolumeName, FluxSecretName(spec), FluxMountPath)
+	setFQDN := false
+	for _, rj := range jobs {
+		rj.Template.Spec.Template.Spec.SetHostnameAsFQDN = &setFQDN
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+func TestGenerateCurveCert(t *testing.T) {
+	data, err := metrics.GenerateCurveCert()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Curve keys are 32 bytes, or 40 characters in base 85
+	cert := string(data[metrics.FluxCurveCert])
+	for _, key := range []string{"public-key", "secret-key"} {
+		pattern := regexp.MustCompile(key + ` = "[0-9a-zA-Z.\-:+=^!/*?&<>()\[\]{}@%$#]{40}"`)
+		if !pattern.MatchString(cert) {
+			t.Errorf("expected a %s in the certificate:\n%s", key, cert)
+		}
+	}
+}
+
+func TestFluxLauncher(t *testing.T) {
+	command := metrics.Flux{}.Command(metrics.MPIJob{Hosts: "./hostlist.txt", NP: "2", PPN: "1", Flags: "-o cpu-affinity=per-task"})
+	if command != `metrics_operator_flux_run "-n 2 --tasks-per-node=1 -o cpu-affinity=per-task"` {
+		t.Errorf("unexpected command %s", command)
+	}
+
+	spec := &api.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+		Spec: api.MetricSetSpec{
+			Pods:        2,
+			ServiceName: "ms",
+			Metrics: []api.Metric{{
+				Name:    "network-osu-benchmark",
+				Options: map[string]intstr.IntOrString{"launcher": intstr.FromString("flux")},
+			}},
+		},
+	}
+	spec.Validate()
+	m, err := metrics.GetMetric(&spec.Spec.Metrics[0], spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := metrics.MetricSet{}
+	set.Add(&m)
+	if !metrics.UsesFlux(&set) {
+		t.Errorf("expected the MetricSet to use flux")
+	}
+	js, containerSpecs, err := metrics.GetJobSet(spec, &set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := containerSpecs[0].EntrypointScript.WriteScript()
+	for _, expected := range []string{"flux broker --config-path", `metrics_operator_flux_run "-n 2 --tasks-per-node=1"`, "ms-l-0-0,ms-w-0-0"} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("expected %q in the entrypoint:\n%s", expected, script)
+		}
+	}
+
+	// The brokers wait for each other, so there is no sshd or rendezvous
+	for _, unexpected := range []string{"sshd", metadata.Rendezvous + " waiting", "getent hosts"} {
+		if strings.Contains(script, unexpected) {
+			t.Errorf("expected no %q in the entrypoint:\n%s", unexpected, script)
+		}
+	}
+	if len(metrics.SSHHosts(spec, &set)) != 0 {
+		t.Errorf("expected no ssh keys for flux")
+	}
+
+	// The curve certificate is mounted in every pod, and the ssh keys are not
+	for _, rj := range js.Spec.ReplicatedJobs {
+		found := false
+		for _, volume := range rj.Template.Spec.Template.Spec.Volumes {
+			found = found || (volume.Secret != nil && volume.Secret.SecretName == metrics.FluxSecretName(spec))
+			if volume.Secret != nil && volume.Secret.SecretName == metrics.SSHSecretName(spec) {
+				t.Errorf("expected the ssh Secret to not be mounted in %s", rj.Name)
+			}
+		}
+		if !found {
+			t.Errorf("expected the flux Secret to be mounted in %s", rj.Name)
+		}
+	}
+}
+
+func TestFluxFlags(t *testing.T) {
+	tests := []struct {
+		flags    string
+		expected string
+		invalid  bool
+	}{
+		{flags: "-n 4 --exclusive", expected: "-n 4 --exclusive"},
+		{flags: "-o cpu-affinity=per-task --setopt=gpu-affinity=off", expected: "-o cpu-affinity=per-task --setopt gpu-affinity=off"},
+		{flags: "-np 4 --map-by ppr:2:node", expected: "-n 4 --tasks-per-node 2"},
+		{flags: "-np=$np -ppn $tasks", expected: "-n $np --tasks-per-node $tasks"},
+		{flags: "--allow-run-as-root -np 2", invalid: true},
+		{flags: "--hostfile ./hostfile.mpi", invalid: true},
+		{flags: "--map-by socket", invalid: true},
+		{flags: "--exclusive=true", invalid: true},
+		{flags: "-n", invalid: true},
+		{flags: "./osu_latency", invalid: true},
+	}
+	for _, test := range tests {
+		flags, err := metrics.FluxFlags(test.flags)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("expected flags %q to not be valid, found %q", test.flags, flags)
+			}
+			continue
+		}
+		if err != nil || flags != test.expected {
+			t.Errorf("expected flags %q to be %q, found %q (%v)", test.flags, test.expected, flags, err)
+		}
+	}
+
+	// Flags that flux submit doesn't have fail validation of the metric
+	for flags, valid := range map[string]bool{"-np 2 --map-by ppr:1:node": true, "--allow-run-as-root -np 2": false} {
+		spec := &api.MetricSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+			Spec: api.MetricSetSpec{
+				Pods:        2,
+				ServiceName: "ms",
+				Metrics: []api.Metric{{
+					Name: "network-osu-benchmark",
+					Options: map[string]intstr.IntOrString{
+						"launcher": intstr.FromString("flux"),
+						"flags":    intstr.FromString(flags),
+					},
+				}},
+			},
+		}
+		spec.Validate()
+		_, err := metrics.GetMetric(&spec.Spec.Metrics[0], spec)
+		if (err == nil) != valid {
+			t.Errorf("expected flags %q to be valid to be %t, found %v", flags, valid, err)
+		}
+	}
+}
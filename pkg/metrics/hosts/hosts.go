@@ -0,0 +1,245 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+// Package hosts generates the hostnames of MetricSet pods, following JobSet
+// naming. It is shared by metrics and addons, so it does not import either.
+package hosts
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+)
+
+var (
+	// DefaultClusterDomain is used when the domain is not set or detected
+	DefaultClusterDomain = "cluster.local"
+	clusterDomain        = DefaultClusterDomain
+
+	// A hostname is a single DNS label
+	maxHostnameLength = 63
+)
+
+// SetClusterDomain sets the cluster DNS domain (e.g., from the operator flag)
+func SetClusterDomain(domain string) {
+	domain = strings.Trim(domain, ".")
+	if domain == "" {
+		domain = DefaultClusterDomain
+	}
+	clusterDomain = domain
+}
+
+// ClusterDomain returns the cluster DNS domain
+func ClusterDomain() string {
+	return clusterDomain
+}
+
+// DetectClusterDomain reads the cluster domain from the search domains of a
+// resolv.conf. In a pod these include svc.<domain>, and we fall back to the
+// default if that isn't found.
+func DetectClusterDomain(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return DefaultClusterDomain
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "search" {
+			continue
+		}
+		for _, domain := range fields[1:] {
+			if strings.HasPrefix(domain, "svc.") {
+				return strings.Trim(strings.TrimPrefix(domain, "svc."), ".")
+			}
+		}
+	}
+	return DefaultClusterDomain
+}
+
+// A Job is a replicated job with some number of jobs (replicas) and pods per job
+type Job struct {
+	Name     string
+	Replicas int32
+	Pods     int32
+}
+
+// NewJob describes the pods of a replicated job
+func NewJob(rj *jobset.ReplicatedJob) Job {
+	job := Job{Name: rj.Name, Replicas: int32(rj.Replicas), Pods: 1}
+	if rj.Template.Spec.Parallelism != nil {
+		job.Pods = *rj.Template.Spec.Parallelism
+	}
+	return job
+}
+
+// NewJobs describes the pods of replicated jobs
+func NewJobs(rjs []*jobset.ReplicatedJob) []Job {
+	jobs := []Job{}
+	for _, rj := range rjs {
+		jobs = append(jobs, NewJob(rj))
+	}
+	return jobs
+}
+
+// A Generator names pods of the JobSet for a MetricSet
+type Generator struct {
+	JobSet      string
+	ServiceName string
+	Namespace   string
+}
+
+// NewGenerator returns a generator for the MetricSet (and its JobSet)
+func NewGenerator(set *api.MetricSet) Generator {
+	return Generator{
+		JobSet:      set.Name,
+		ServiceName: set.Spec.ServiceName,
+		Namespace:   set.Namespace,
+	}
+}
+
+// JobName is the name of a job of a replicated job: <jobset>-<replicated job>-<job index>
+func (g Generator) JobName(job string, jobIndex int32) string {
+	return fmt.Sprintf("%s-%s-%d", g.JobSet, job, jobIndex)
+}
+
+// Hostname is the hostname of a pod: <job name>-<pod index>
+func (g Generator) Hostname(job string, jobIndex, podIndex int32) string {
+	return fmt.Sprintf("%s-%d", g.JobName(job, jobIndex), podIndex)
+}
+
+// Domain is the domain of the headless service of the JobSet
+func (g Generator) Domain() string {
+	return fmt.Sprintf("%s.%s.svc.%s", g.ServiceName, g.Namespace, clusterDomain)
+}
+
+// FQDN is the fully qualified hostname of a pod
+func (g Generator) FQDN(job string, jobIndex, podIndex int32) string {
+	return fmt.Sprintf("%s.%s", g.Hostname(job, jobIndex, podIndex), g.Domain())
+}
+
+// Hosts returns the fully qualified hostnames of all pods, in job order
+func (g Generator) Hosts(jobs ...Job) []string {
+	hosts := []string{}
+	for _, job := range jobs {
+		for i := int32(0); i < job.Replicas; i++ {
+			for j := int32(0); j < job.Pods; j++ {
+				hosts = append(hosts, g.FQDN(job.Name, i, j))
+			}
+		}
+	}
+	return hosts
+}
+
+// Hostlist returns the fully qualified hostnames, one per line
+func (g Generator) Hostlist(jobs ...Job) string {
+	hostlist := ""
+	for _, host := range g.Hosts(jobs...) {
+		hostlist += host + "\n"
+	}
+	return hostlist
+}
+
+// HostRange returns the hostnames of the pods of one job as a range
+// (e.g., <job name>-[0-3]) as used by Flux
+func (g Generator) HostRange(job string, jobIndex, pods int32) string {
+	if pods == 1 {
+		return g.Hostname(job, jobIndex, 0)
+	}
+	return fmt.Sprintf("%s-[0-%d]", g.JobName(job, jobIndex), pods-1)
+}
+
+// Validate that the hostnames of all pods are valid DNS labels
+func (g Generator) Validate(jobs ...Job) error {
+	for _, job := range jobs {
+		if job.Replicas < 1 || job.Pods < 1 {
+			continue
+		}
+		hostname := g.Hostname(job.Name, job.Replicas-1, job.Pods-1)
+		if len(hostname) > maxHostnameLength {
+			return fmt.Errorf("hostname %s is longer than %d characters, use a shorter MetricSet name", hostname, maxHostnameLength)
+		}
+	}
+	return nil
+}
+
+// Defaults for the rendezvous barrier
+var (
+	DefaultRendezvousPort    = int32(22)
+	DefaultRendezvousTimeout = int32(300)
+
+	// Maximum seconds to wait between attempts
+	rendezvousMaxDelay = 16
+)
+
+// BarrierScript writes a shell snippet that waits until every host in a file
+// (one per line) resolves and accepts connections on a port (e.g., sshd). It
+// retries with exponential backoff, and exits with a message naming the host
+// that is not ready when the timeout (in seconds) is reached.
+func BarrierScript(hostfile string, port, timeout int32) string {
+	template := `
+# Wait for all hosts to resolve and accept connections on port %[2]d
+echo "%[4]s waiting up to %[3]d seconds for hosts in %[1]s..."
+metrics_operator_start=$(date +%%s)
+for h in $(cat %[1]s); do
+	if [[ "$h" == "" ]]; then
+	  continue
+	fi
+	delay=1
+	while true; do
+		address=$(getent hosts $h | awk '{ print $1 }' | head -n 1)
+		if [[ "${address}" != "" ]] && timeout 5 bash -c "echo > /dev/tcp/${address}/%[2]d" 2>/dev/null; then
+			break
+		fi
+		elapsed=$(( $(date +%%s) - metrics_operator_start ))
+		if [[ ${elapsed} -ge %[3]d ]]; then
+			echo "%[5]s: host ${h} (address ${address:-not resolved}) did not accept connections on port %[2]d within %[3]d seconds"
+			exit 1
+		fi
+		sleep ${delay}
+		delay=$(( delay * 2 ))
+		if [[ ${delay} -gt %[6]d ]]; then
+			delay=%[6]d
+		fi
+	done
+done
+echo "%[4]s all hosts are ready after $(( $(date +%%s) - metrics_operator_start )) seconds"
+`
+	return fmt.Sprintf(template, hostfile, port, timeout, metadata.Rendezvous, metadata.RendezvousFailed, rendezvousMaxDelay)
+}
+
+// ResolveScript writes a shell snippet that converts a file of hostnames
+// (one per line) into a file of ip addresses, in the same order. This is
+// expected to run after the barrier, so every host should resolve.
+func ResolveScript(hostnames, addresses string) string {
+	template := `
+# Some launchers (e.g., openmpi) need ip addresses
+echo "Starting to look for ip addresses..."
+for h in $(cat %[1]s); do
+	if [[ "$h" == "" ]]; then
+	  continue
+	fi
+	address=$(getent hosts $h | awk '{ print $1 }' | head -n 1)
+	if [[ "${address}" == "" ]]; then
+		echo "%[3]s: host ${h} does not resolve to an ip address"
+		exit 1
+	fi
+	echo "${address}" >> %[2]s
+done
+num_address=$(cat %[2]s | wc -l)
+echo "Done finding ${num_address} ip addresses"
+`
+	return fmt.Sprintf(template, hostnames, addresses, metadata.RendezvousFailed)
+}
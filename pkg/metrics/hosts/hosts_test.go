@@ -0,0 +1,98 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package hosts
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+)
+
+func TestDetectClusterDomain(t *testing.T) {
+	dir := t.TempDir()
+	for content, expected := range map[string]string{
+		"search default.svc.example.org svc.example.org example.org\nnameserver 10.96.0.10\n": "example.org",
+		"nameserver 8.8.8.8\n": DefaultClusterDomain,
+	} {
+		path := filepath.Join(dir, "resolv.conf")
+		err := os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		domain := DetectClusterDomain(path)
+		if domain != expected {
+			t.Errorf("expected %s, got %s", expected, domain)
+		}
+	}
+	if DetectClusterDomain(filepath.Join(dir, "missing")) != DefaultClusterDomain {
+		t.Errorf("expected the default for a missing resolv.conf")
+	}
+}
+
+func TestHostnames(t *testing.T) {
+	defer SetClusterDomain("")
+	SetClusterDomain("example.org.")
+
+	g := Generator{JobSet: "ms", ServiceName: "svc", Namespace: "ns"}
+	hosts := g.Hosts(Job{Name: "l", Replicas: 1, Pods: 1}, Job{Name: "w", Replicas: 2, Pods: 2})
+	expected := []string{
+		"ms-l-0-0.svc.ns.svc.example.org",
+		"ms-w-0-0.svc.ns.svc.example.org",
+		"ms-w-0-1.svc.ns.svc.example.org",
+		"ms-w-1-0.svc.ns.svc.example.org",
+		"ms-w-1-1.svc.ns.svc.example.org",
+	}
+	if strings.Join(hosts, ",") != strings.Join(expected, ",") {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+	if g.HostRange("w", 0, 4) != "ms-w-0-[0-3]" || g.HostRange("w", 0, 1) != "ms-w-0-0" {
+		t.Errorf("unexpected host ranges")
+	}
+
+	long := Generator{JobSet: strings.Repeat("m", 60), ServiceName: "svc", Namespace: "ns"}
+	if long.Validate(Job{Name: "w", Replicas: 1, Pods: 10}) == nil {
+		t.Errorf("expected a hostname over 63 characters to fail validation")
+	}
+}
+
+func TestBarrierScript(t *testing.T) {
+	_, err := exec.LookPath("getent")
+	if err != nil {
+		t.Skip("getent is not available")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := int32(listener.Addr().(*net.TCPAddr).Port)
+
+	hostfile := filepath.Join(t.TempDir(), "hostlist.txt")
+	err = os.WriteFile(hostfile, []byte("127.0.0.1\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("bash", "-c", BarrierScript(hostfile, port, 10)).CombinedOutput()
+	if err != nil {
+		t.Fatalf("expected the barrier to pass: %s\n%s", err, out)
+	}
+
+	// Once nothing is listening, the barrier fails at the timeout
+	listener.Close()
+	out, err = exec.Command("bash", "-c", BarrierScript(hostfile, port, 1)).CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected the barrier to fail:\n%s", out)
+	}
+	if !strings.Contains(string(out), metadata.RendezvousFailed+": host 127.0.0.1") {
+		t.Errorf("expected a failure message for the host, got:\n%s", out)
+	}
+}
@@ -8,7 +8,9 @@ SPDX-License-Identifier: MIT
 package io
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -182,6 +184,50 @@ func (m Fio) Options() map[string]intstr.IntOrString {
 	}
 }
 
+// fioOutput is the part of the fio json output that we parse
+type fioOutput struct {
+	Jobs []struct {
+		Name  string      `json:"jobname"`
+		Read  fioIOResult `json:"read"`
+		Write fioIOResult `json:"write"`
+	} `json:"jobs"`
+}
+
+// Bandwidth is in KiB/s
+type fioIOResult struct {
+	Bandwidth float64 `json:"bw"`
+	IOPS      float64 `json:"iops"`
+}
+
+// ParseResults returns the bandwidth and iops of each job, for reads and writes.
+// Each pod runs the jobs, so they are summed across pods.
+func (m Fio) ParseResults(log string) []metrics.Sample {
+	samples := []metrics.Sample{}
+	for _, section := range metadata.Sections(log) {
+		start := strings.Index(section, "{")
+		end := strings.LastIndex(section, "}")
+		if start < 0 || end < start {
+			continue
+		}
+		output := fioOutput{}
+		err := json.Unmarshal([]byte(section[start:end+1]), &output)
+		if err != nil {
+			continue
+		}
+		directions := []string{"read", "write"}
+		for _, job := range output.Jobs {
+			for i, result := range []fioIOResult{job.Read, job.Write} {
+				labels := map[string]string{"job": job.Name, "direction": directions[i]}
+				samples = append(samples,
+					metrics.Sample{Name: "bandwidth_kib", Labels: labels, Value: result.Bandwidth, Unit: "KiB/s", Merge: metrics.MergeSum, Better: metrics.BetterHigher},
+					metrics.Sample{Name: "iops", Labels: labels, Value: result.IOPS, Unit: "IO/s", Merge: metrics.MergeSum, Better: metrics.BetterHigher},
+				)
+			}
+		}
+	}
+	return samples
+}
+
 func init() {
 	base := metrics.BaseMetric{
 		Identifier: fioIdentifier,
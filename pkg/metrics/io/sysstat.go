@@ -8,8 +8,10 @@ SPDX-License-Identifier: MIT
 package io
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -147,6 +149,79 @@ func (m IOStat) Options() map[string]intstr.IntOrString {
 	}
 }
 
+// Fields of a device in the JSON of iostat -dxm, with the name and unit of the sample
+var iostatFields = []struct {
+	field string
+	name  string
+	unit  string
+}{
+	{"r/s", "reads", "IO/s"},
+	{"w/s", "writes", "IO/s"},
+	{"rMB/s", "read_mb", "MB/s"},
+	{"wMB/s", "write_mb", "MB/s"},
+	{"r_await", "read_await", "ms"},
+	{"w_await", "write_await", "ms"},
+	{"aqu-sz", "queue_size", ""},
+	{"util", "util", "%"},
+}
+
+// iostatOutput is the JSON of iostat, with the statistics of each device
+type iostatOutput struct {
+	Sysstat struct {
+		Hosts []struct {
+			Statistics []struct {
+				Disk []map[string]interface{} `json:"disk"`
+			} `json:"statistics"`
+		} `json:"hosts"`
+	} `json:"sysstat"`
+}
+
+// ParseResults returns the statistics of each device at each timepoint.
+// Human readable output (the human option) is not parsed.
+func (m IOStat) ParseResults(log string) []metrics.Sample {
+	samples := []metrics.Sample{}
+	for _, section := range metadata.SplitLog(log).Sections {
+		start := strings.Index(section.Output, "{")
+		end := strings.LastIndex(section.Output, "}")
+		if start < 0 || end < start {
+			continue
+		}
+		output := iostatOutput{}
+		err := json.Unmarshal([]byte(section.Output[start:end+1]), &output)
+		if err != nil {
+			continue
+		}
+		for _, host := range output.Sysstat.Hosts {
+			for _, statistics := range host.Statistics {
+				for _, disk := range statistics.Disk {
+					device, _ := disk["disk_device"].(string)
+					for _, field := range iostatFields {
+						value, ok := disk[field.field].(float64)
+						if !ok {
+							continue
+						}
+						samples = append(samples, metrics.Sample{
+							Name: field.name,
+							Labels: map[string]string{
+								"device":               device,
+								metrics.TimepointLabel: strconv.Itoa(section.Iteration),
+							},
+							Value: value,
+							Unit:  field.unit,
+						})
+					}
+				}
+			}
+		}
+	}
+	return samples
+}
+
+// ResultChart plots the statistics of each device over time
+func (m IOStat) ResultChart() metrics.Chart {
+	return metrics.Chart{Kind: metrics.ChartTimeSeries, X: metrics.TimepointLabel}
+}
+
 func init() {
 	base := metrics.BaseMetric{
 		Identifier: iostatIdentifier,
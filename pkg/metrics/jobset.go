@@ -14,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metrics/hosts"
 	"github.com/converged-computing/metrics-operator/pkg/specs"
 
 	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
@@ -28,7 +29,12 @@ var (
 	soleTenancyValue  = "sole-tenancy"
 )
 
-const podLabelAppName = "app.kubernetes.io/name"
+const (
+	podLabelAppName = "app.kubernetes.io/name"
+
+	// Pods are labeled with the metric so status can be reported for it
+	MetricLabel = "metrics-operator-metric"
+)
 
 // GetJobSet is called by the controller to return a JobSet for the MetricSet
 func GetJobSet(
@@ -57,12 +63,21 @@ func GetJobSet(
 		if err != nil {
 			return js, containerSpecs, err
 		}
+		err = hosts.NewGenerator(spec).Validate(hosts.NewJobs(jobs)...)
+		if err != nil {
+			return js, containerSpecs, err
+		}
 
 		// Generate container specs for the metric, each is associated with a replicated job
 		// The containers are paired with entrypoints, and also with the replicated jobs
 		// We do this so we can match addons easily. The only reason we do this outside
 		// of the loop below is to allow shared logic.
 		cs := m.PrepareContainers(spec, &m)
+		setTimeout(m, jobs, cs)
+		setDebug(spec.Spec.Logging, cs)
+		setFailurePolicy(jobs, cs)
+		setEvents(m, cs)
+		setMetricLabel(jobs, m.Name())
 
 		// Prepare container and volume specs (that are changeable) e.g.,
 		// 1. Create VolumeSpec across metrics and addons that can predefine volumes
@@ -72,6 +87,15 @@ func GetJobSet(
 		if err != nil {
 			return js, containerSpecs, err
 		}
+		setSSH(spec, m, jobs, cs)
+		setFlux(spec, m, jobs, cs)
+
+		// User templates override entrypoint blocks, after addons customize them
+		err = renderTemplates(spec, m, jobs, cs)
+		if err != nil {
+			return js, containerSpecs, err
+		}
+		setResults(spec, m, jobs, cs)
 
 		// Add the finalized container specs for the entire set of replicated jobs
 		// We need this at the end to hand back to generate config maps
@@ -89,6 +113,46 @@ func GetJobSet(
 	return js, containerSpecs, nil
 }
 
+// setDebug asks the metric entrypoints to hold the container for debugging
+func setDebug(logging api.Logging, containerSpecs []*specs.ContainerSpec) {
+	if logging.Debug == "" {
+		return
+	}
+	for _, cs := range containerSpecs {
+
+		// A container with a command does not use the entrypoint
+		if len(cs.Command) > 0 {
+			continue
+		}
+		cs.EntrypointScript.Debug = logging.Debug
+		cs.EntrypointScript.DebugLines = logging.DebugLines
+		cs.EntrypointScript.DebugHoldSeconds = logging.DebugHoldSeconds
+	}
+}
+
+// setEvents asks the metric entrypoints to write JSON-lines events after markers
+func setEvents(m Metric, containerSpecs []*specs.ContainerSpec) {
+	for _, cs := range containerSpecs {
+		if len(cs.Command) > 0 {
+			continue
+		}
+		cs.EntrypointScript.Metric = m.Name()
+	}
+}
+
+// setMetricLabel labels the pods of each replicated job with the metric name.
+// The pod labels are shared with the MetricSet, so we copy them first.
+func setMetricLabel(jobs []*jobset.ReplicatedJob, name string) {
+	for _, rj := range jobs {
+		labels := map[string]string{}
+		for key, value := range rj.Template.Spec.Template.Labels {
+			labels[key] = value
+		}
+		labels[MetricLabel] = name
+		rj.Template.Spec.Template.Labels = labels
+	}
+}
+
 // Get list of strings that define successful for a jobset.
 // Since these are from replicatedJobs in metrics, we collect from there
 func getSuccessJobs(metrics []*Metric) []string {
@@ -9,9 +9,12 @@ package metrics
 
 import (
 	"fmt"
+	"strings"
+	"text/template"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
 	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics/hosts"
 	"github.com/converged-computing/metrics-operator/pkg/specs"
 	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
 )
@@ -25,6 +28,61 @@ var (
 	defaultWorkerLetter   = "w"
 )
 
+// launcherData fills the launcher and worker script templates
+type launcherData struct {
+	SSH         string
+	Metadata    string
+	Hosts       []string
+	Command     string
+	Rendezvous  string
+	Flux        string
+	Interactive string
+}
+
+// The prefix is shared by the launcher and workers, and writes the hostlist
+// (and problem.sh, only if we have a command). The launcher runs problem.sh
+// between the pre and post blocks.
+var (
+	launcherPrefixTemplate = template.Must(template.New("prefix").Parse(`#!/bin/bash
+{{ .SSH }}
+echo "{{ .Metadata }}"
+# Write the hosts file
+cat <<EOF > ./hostlist.txt
+{{ range .Hosts }}{{ . }}
+{{ end }}EOF
+{{ if .Command }}
+# Write the command file
+cat <<EOF > ./problem.sh
+#!/bin/bash
+{{ .Command }}
+EOF
+chmod +x ./problem.sh{{ end }}
+{{ .Rendezvous }}
+{{ .Flux }}
+echo "` + metadata.CollectionStart + `"
+`))
+
+	launcherPreTemplate = template.Must(template.New("pre").Parse(`
+echo "` + metadata.Separator + `"
+`))
+
+	launcherPostTemplate = template.Must(template.New("post").Parse(`
+echo "` + metadata.CollectionEnd + `"
+{{ .Interactive }}
+`))
+)
+
+// renderScript renders a launcher template. The templates are fixed and
+// the data only has strings, so we don't expect an error.
+func renderScript(t *template.Template, data launcherData) string {
+	var script strings.Builder
+	err := t.Execute(&script, data)
+	if err != nil {
+		logger.Errorf("🟥️ Issue rendering the %s script: %s", t.Name(), err)
+	}
+	return script.String()
+}
+
 // LauncherWorker is a launcher + worker setup for apps. These need to
 // be accessible by other packages (and not conflict with function names)
 type LauncherWorker struct {
@@ -46,6 +104,22 @@ type LauncherWorker struct {
 	WorkerContainer   string
 	LauncherContainer string
 	WorkerLetter      string
+
+	// Number of pods for each of the launcher and workers
+	launcherPods int32
+	workerPods   int32
+
+	// Seconds to wait for all hosts to be reachable, and the port to check
+	rendezvousTimeout int32
+	rendezvousPort    int32
+
+	// The MPI launcher from the "mpi" option, or the default of the metric
+	DefaultMPI string
+	mpi        string
+
+	// The launcher is mpi (over ssh) or flux, and hosts names the pods
+	launcher string
+	hosts    hosts.Generator
 }
 
 // Family returns a generic performance family
@@ -59,6 +133,14 @@ func (m *LauncherWorker) SuccessJobs() []string {
 	return []string{m.LauncherLetter}
 }
 
+// Return container resources and attributes, which shadow the BaseMetric ones
+func (m LauncherWorker) Resources() *api.ContainerResources {
+	return m.ResourceSpec
+}
+func (m LauncherWorker) Attributes() *api.ContainerSpec {
+	return m.AttributeSpec
+}
+
 // Set default options / attributes for the launcher metric
 func (m *LauncherWorker) SetDefaultOptions(metric *api.Metric) {
 	m.ResourceSpec = &metric.Resources
@@ -78,6 +160,174 @@ func (m *LauncherWorker) SetDefaultOptions(metric *api.Metric) {
 	}
 }
 
+// SetPods sets the launcher and worker pods. The launcher defaults to 1 pod,
+// and the workers to the remainder of the metric (or MetricSet) pods. Workers
+// without metric pods size the metric, and otherwise they must add up (ValidatePods).
+func (m *LauncherWorker) SetPods(metric *api.Metric, set *api.MetricSet) {
+	m.BaseMetric.SetPods(metric, set)
+	m.hosts = hosts.NewGenerator(set)
+	m.launcherPods = 1
+	if metric.LauncherPods > 0 {
+		m.launcherPods = metric.LauncherPods
+	}
+	m.workerPods = m.BaseMetric.Pods() - m.launcherPods
+	if metric.WorkerPods > 0 {
+		m.workerPods = metric.WorkerPods
+		if metric.Pods == 0 {
+			m.pods = m.launcherPods + m.workerPods
+		}
+	}
+	if m.workerPods < 0 {
+		m.workerPods = 0
+	}
+}
+
+// SetLauncherOptions sets options shared by all launcher worker metrics: the
+// MPI launcher, and the timeout and port of the rendezvous barrier, which
+// waits for all launcher and worker hosts before the metric runs
+func (m *LauncherWorker) SetLauncherOptions(metric *api.Metric) {
+	mpi, ok := metric.Options["mpi"]
+	if ok {
+		m.mpi = mpi.StrVal
+	}
+	launcher, ok := metric.Options["launcher"]
+	if ok {
+		m.launcher = launcher.StrVal
+	}
+	m.rendezvousTimeout = hosts.DefaultRendezvousTimeout
+	m.rendezvousPort = hosts.DefaultRendezvousPort
+	timeout, ok := metric.Options["rendezvousTimeout"]
+	if ok && timeout.IntVal > 0 {
+		m.rendezvousTimeout = timeout.IntVal
+	}
+	port, ok := metric.Options["rendezvousPort"]
+	if ok && port.IntVal > 0 {
+		m.rendezvousPort = port.IntVal
+	}
+}
+
+// Rendezvous returns the barrier script that waits for the hosts in a hostfile.
+// In Flux mode, the brokers wait for each other instead.
+func (m LauncherWorker) Rendezvous(hostfile string) string {
+	if m.FluxEnabled() {
+		return ""
+	}
+	timeout := m.rendezvousSeconds()
+	port := m.rendezvousPort
+	if port == 0 {
+		port = hosts.DefaultRendezvousPort
+	}
+	return hosts.BarrierScript(hostfile, port, timeout)
+}
+
+// SSH returns the script that starts sshd, which Flux mode doesn't need
+func (m LauncherWorker) SSH() string {
+	if m.FluxEnabled() {
+		return ""
+	}
+	return SSHScript()
+}
+
+// ConvertHostnames writes the addresses of the hosts in hostnames.txt to
+// hostlist.txt. In Flux mode, the hosts might not resolve before the brokers
+// start, and the hostnames are kept since the launcher doesn't use them.
+func (m LauncherWorker) ConvertHostnames() string {
+	if m.FluxEnabled() {
+		return "\ncp ./hostnames.txt ./hostlist.txt\n"
+	}
+	return TemplateConvertHostnames
+}
+
+// ValidateFlags checks flags from the user for the launcher. In Flux mode,
+// flags of MPI launchers are translated, and others must be valid for flux submit.
+func (m LauncherWorker) ValidateFlags(flags string) bool {
+	if !m.FluxEnabled() {
+		return true
+	}
+	_, err := FluxFlags(flags)
+	if err != nil {
+		logger.Errorf("🟥️ %s", err)
+		return false
+	}
+	return true
+}
+
+// rendezvousSeconds is the time to wait for all hosts
+func (m LauncherWorker) rendezvousSeconds() int32 {
+	if m.rendezvousTimeout == 0 {
+		return hosts.DefaultRendezvousTimeout
+	}
+	return m.rendezvousTimeout
+}
+
+// MPI returns the MPI launcher of the metric. An unknown launcher falls
+// back to the default, and fails validation. In Flux mode, commands are
+// submitted to the Flux instance instead.
+func (m LauncherWorker) MPI() MPILauncher {
+	if m.FluxEnabled() {
+		return Flux{}
+	}
+	launcher, ok := MPILaunchers[m.mpiName()]
+	if !ok {
+		return MPILaunchers[DefaultMPILauncher]
+	}
+	return launcher
+}
+
+// mpiName is the name of the MPI launcher that was chosen
+func (m LauncherWorker) mpiName() string {
+	if m.mpi != "" {
+		return m.mpi
+	}
+	if m.DefaultMPI != "" {
+		return m.DefaultMPI
+	}
+	return DefaultMPILauncher
+}
+
+// FluxEnabled determines if the metric runs in a Flux instance
+func (m LauncherWorker) FluxEnabled() bool {
+	return m.launcher == LauncherFlux
+}
+
+// FluxBootstrap returns the script that starts the Flux broker on each pod,
+// in Flux mode. The broker config is the same for all pods.
+func (m *LauncherWorker) FluxBootstrap() string {
+	if !m.FluxEnabled() {
+		return ""
+	}
+	config := fluxBrokerConfig(m.hosts, m.hostJobs()...)
+	return fluxBootstrapScript(config, m.Pods(), m.rendezvousSeconds())
+}
+
+// ValidateMPI checks that the launcher and MPI launcher are known
+func (m LauncherWorker) ValidateMPI() bool {
+	if m.launcher != "" && m.launcher != LauncherMPI && m.launcher != LauncherFlux {
+		logger.Errorf("🟥️ Launcher %s is not known, choices are %s and %s", m.launcher, LauncherMPI, LauncherFlux)
+		return false
+	}
+	_, ok := MPILaunchers[m.mpiName()]
+	if !ok {
+		logger.Errorf("🟥️ MPI launcher %s is not known, choices are %v", m.mpiName(), mpiLauncherNames())
+	}
+	return ok
+}
+
+// Pods returns the total number of launcher and worker pods
+func (m LauncherWorker) Pods() int32 {
+	return m.launcherPods + m.workerPods
+}
+
+// LauncherPods returns the number of launcher pods
+func (m LauncherWorker) LauncherPods() int32 {
+	return m.launcherPods
+}
+
+// WorkerPods returns the number of worker pods
+func (m LauncherWorker) WorkerPods() int32 {
+	return m.workerPods
+}
+
 // Ensure the worker and launcher default names are set
 func (m *LauncherWorker) ensureDefaultNames() {
 	// Ensure we set the default launcher letter, if not set
@@ -111,18 +361,11 @@ func (m *LauncherWorker) PrepareContainers(
 	hosts := m.GetHostlist(spec)
 	prefix := m.GetCommonPrefix(meta, m.Command, hosts)
 
-	preBlock := `
-echo "%s"
-`
-
-	postBlock := `
-echo "%s"
-%s
-`
 	command := fmt.Sprintf("%s ./problem.sh", m.Prefix)
-	interactive := metadata.Interactive(spec.Spec.Logging.Interactive)
-	preBlock = prefix + fmt.Sprintf(preBlock, metadata.Separator)
-	postBlock = fmt.Sprintf(postBlock, metadata.CollectionEnd, interactive)
+	preBlock := prefix + renderScript(launcherPreTemplate, launcherData{})
+	postBlock := renderScript(launcherPostTemplate, launcherData{
+		Interactive: metadata.Interactive(spec.Spec.Logging.Interactive),
+	})
 
 	// Entrypoint for the launcher
 	launcherEntrypoint := specs.EntrypointScript{
@@ -155,46 +398,20 @@ func (m *LauncherWorker) GetCommonPrefix(
 	command string,
 	hosts string,
 ) string {
-
-	// Generate problem.sh with command only if we have one!
-	if command != "" {
-		command = fmt.Sprintf(`# Write the command file
-cat <<EOF > ./problem.sh
-#!/bin/bash
-%s
-EOF
-chmod +x ./problem.sh`, command)
-	}
-
-	prefixTemplate := `#!/bin/bash
-# Start ssh daemon
-/usr/sbin/sshd -D &
-echo "%s"
-# Write the hosts file
-cat <<EOF > ./hostlist.txt
-%s
-EOF
-
-%s
-
-# Allow network to ready (this could be a variable)
-echo "Sleeping for 10 seconds waiting for network..."
-sleep 10
-echo "%s"
-`
-	return fmt.Sprintf(
-		prefixTemplate,
-		meta,
-		hosts,
-		command,
-		metadata.CollectionStart,
-	)
+	return renderScript(launcherPrefixTemplate, launcherData{
+		SSH:        m.SSH(),
+		Metadata:   meta,
+		Hosts:      strings.Fields(hosts),
+		Command:    command,
+		Rendezvous: m.Rendezvous("./hostlist.txt"),
+		Flux:       m.FluxBootstrap(),
+	})
 }
 
 // AddWorkers generates worker jobs, only if we have them
 func (m *LauncherWorker) AddWorkers(spec *api.MetricSet) (*jobset.ReplicatedJob, error) {
 
-	numWorkers := spec.Spec.Pods - 1
+	numWorkers := m.workerPods
 	workers, err := AssembleReplicatedJob(spec, false, numWorkers, numWorkers, m.WorkerLetter, m.SoleTenancy)
 	if err != nil {
 		return workers, err
@@ -244,12 +461,12 @@ func (m *LauncherWorker) ReplicatedJobs(spec *api.MetricSet) ([]*jobset.Replicat
 	m.ensureDefaultNames()
 
 	// Generate a replicated job for the launcher (LauncherWorker) and workers
-	launcher, err := AssembleReplicatedJob(spec, false, 1, 1, m.LauncherLetter, m.SoleTenancy)
+	launcher, err := AssembleReplicatedJob(spec, false, m.launcherPods, m.launcherPods, m.LauncherLetter, m.SoleTenancy)
 	if err != nil {
 		return js, err
 	}
 
-	numWorkers := spec.Spec.Pods - 1
+	numWorkers := m.workerPods
 	var workers *jobset.ReplicatedJob
 
 	// Generate the replicated job with just a launcher, or launcher and workers
@@ -267,25 +484,41 @@ func (m *LauncherWorker) ReplicatedJobs(spec *api.MetricSet) ([]*jobset.Replicat
 
 // Validate that we can run a network. At least one launcher and worker is required
 func (m LauncherWorker) Validate(spec *api.MetricSet) bool {
-	isValid := spec.Spec.Pods >= 2
+	isValid := m.Pods() >= 2
 	if !isValid {
 		logger.Errorf("Pods for a Launcher Worker app must be >=2. This app is invalid.")
 	}
-	return isValid
+	return isValid && m.ValidateMPI()
 }
 
-// Get common hostlist for launcher/worker app
-func (m *LauncherWorker) GetHostlist(spec *api.MetricSet) string {
-	m.ensureDefaultNames()
+// ValidatePods checks that the launcher and worker pods add up to the metric pods
+func (m LauncherWorker) ValidatePods() bool {
+	if m.Pods() != m.BaseMetric.Pods() {
+		logger.Errorf("🟥️ launcherPods (%d) and workerPods (%d) must add up to the pods (%d)", m.launcherPods, m.workerPods, m.BaseMetric.Pods())
+		return false
+	}
+	return true
+}
+
+// SSHHosts returns the fully qualified hostnames of the launcher and workers,
+// and none in Flux mode, which doesn't use ssh
+func (m *LauncherWorker) SSHHosts(spec *api.MetricSet) []string {
+	if m.FluxEnabled() {
+		return nil
+	}
+	return hosts.NewGenerator(spec).Hosts(m.hostJobs()...)
+}
 
-	// The launcher has a different hostname, n for netmark
-	hosts := fmt.Sprintf("%s-%s-0-0.%s.%s.svc.cluster.local\n",
-		spec.Name, m.LauncherLetter, spec.Spec.ServiceName, spec.Namespace,
-	)
-	// Add number of workers
-	for i := 0; i < int(spec.Spec.Pods-1); i++ {
-		hosts += fmt.Sprintf("%s-%s-0-%d.%s.%s.svc.cluster.local\n",
-			spec.Name, m.WorkerLetter, i, spec.Spec.ServiceName, spec.Namespace)
+// hostJobs describes the pods of the launcher and workers
+func (m *LauncherWorker) hostJobs() []hosts.Job {
+	m.ensureDefaultNames()
+	return []hosts.Job{
+		{Name: m.LauncherLetter, Replicas: 1, Pods: m.launcherPods},
+		{Name: m.WorkerLetter, Replicas: 1, Pods: m.workerPods},
 	}
-	return hosts
+}
+
+// Get common hostlist for launcher/worker app
+func (m *LauncherWorker) GetHostlist(spec *api.MetricSet) string {
+	return hosts.NewGenerator(spec).Hostlist(m.hostJobs()...)
 }
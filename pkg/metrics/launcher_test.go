@@ -0,0 +1,126 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/addons"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// launcherPods are the pods of a launcher worker metric
+type launcherPods interface {
+	LauncherPods() int32
+	WorkerPods() int32
+}
+
+func TestLauncherWorkerPods(t *testing.T) {
+	tests := []struct {
+		name     string
+		pods     int32
+		metric   api.Metric
+		launcher int32
+		workers  int32
+		invalid  bool
+	}{
+		{name: "defaults", pods: 4, launcher: 1, workers: 3},
+		{name: "metric pods", pods: 2, metric: api.Metric{Pods: 5}, launcher: 1, workers: 4},
+		{name: "launcher pods", pods: 4, metric: api.Metric{LauncherPods: 2}, launcher: 2, workers: 2},
+		{name: "worker pods size the metric", pods: 2, metric: api.Metric{LauncherPods: 1, WorkerPods: 3}, launcher: 1, workers: 3},
+		{name: "all pods", pods: 2, metric: api.Metric{Pods: 4, LauncherPods: 2, WorkerPods: 2}, launcher: 2, workers: 2},
+		{name: "pods do not add up", pods: 2, metric: api.Metric{Pods: 4, WorkerPods: 2}, invalid: true},
+		{name: "too many launcher pods", pods: 2, metric: api.Metric{LauncherPods: 3}, invalid: true},
+	}
+	for _, test := range tests {
+		metric := test.metric
+		metric.Name = "network-osu-benchmark"
+		spec := &api.MetricSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+			Spec:       api.MetricSetSpec{Pods: test.pods, ServiceName: "ms", Metrics: []api.Metric{metric}},
+		}
+		spec.Validate()
+		m, err := metrics.GetMetric(&spec.Spec.Metrics[0], spec)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("%s: expected the metric not to validate", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		pods := m.(launcherPods)
+		if pods.LauncherPods() != test.launcher || pods.WorkerPods() != test.workers || m.Pods() != test.launcher+test.workers {
+			t.Errorf("%s: expected %d launcher and %d worker pods, found %d and %d (%d pods)",
+				test.name, test.launcher, test.workers, pods.LauncherPods(), pods.WorkerPods(), m.Pods())
+		}
+	}
+}
+
+// TestAddonPods checks that addons are sized by the metric, and the flux
+// addon has the launcher pods of the metric before the workers
+func TestAddonPods(t *testing.T) {
+	spec := &api.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+		Spec: api.MetricSetSpec{
+			Pods:        2,
+			ServiceName: "ms",
+			Metrics: []api.Metric{{
+				Name:         "network-osu-benchmark",
+				LauncherPods: 2,
+				WorkerPods:   3,
+				Addons:       []api.MetricAddon{{Name: "workload-flux"}},
+			}},
+		},
+	}
+	spec.Validate()
+	m, err := metrics.GetMetric(&spec.Spec.Metrics[0], spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := m.GetAddons()
+	if len(found) != 1 {
+		t.Fatalf("expected one addon, found %d", len(found))
+	}
+	flux, ok := (*found[0]).(*addons.FluxFramework)
+	if !ok {
+		t.Fatalf("expected the flux addon, found %T", *found[0])
+	}
+	options := flux.Options()
+	if options["pods"].IntVal != 5 || options["launcherPods"].IntVal != 2 {
+		t.Errorf("expected the addon to have 5 pods with 2 launchers, found %v", options)
+	}
+	if !strings.Contains(flux.Setup, `hosts="ms-l-0-[0-1],ms-w-0-[0-2]"`) {
+		t.Errorf("expected the launcher and worker hosts in the setup:\n%s", flux.Setup)
+	}
+}
+
+// TestCommonPrefix checks the hostlist, and that problem.sh is only written with a command
+func TestCommonPrefix(t *testing.T) {
+	m := metrics.LauncherWorker{}
+	prefix := m.GetCommonPrefix("meta", "echo hello", "ms-l-0-0.ms\nms-w-0-0.ms\n")
+	hostlist := "cat <<EOF > ./hostlist.txt\nms-l-0-0.ms\nms-w-0-0.ms\nEOF\n"
+	if !strings.HasPrefix(prefix, "#!/bin/bash\n") || !strings.Contains(prefix, hostlist) {
+		t.Errorf("expected the hostlist in the prefix:\n%s", prefix)
+	}
+	if !strings.Contains(prefix, "cat <<EOF > ./problem.sh\n#!/bin/bash\necho hello\nEOF\n") {
+		t.Errorf("expected the command in problem.sh:\n%s", prefix)
+	}
+	if !strings.HasSuffix(prefix, "echo \"METRICS OPERATOR COLLECTION START\"\n") {
+		t.Errorf("expected the prefix to end with the collection start:\n%s", prefix)
+	}
+	prefix = m.GetCommonPrefix("meta", "", "ms-l-0-0.ms")
+	if strings.Contains(prefix, "problem.sh") {
+		t.Errorf("expected no problem.sh without a command:\n%s", prefix)
+	}
+}
@@ -8,6 +8,7 @@ SPDX-License-Identifier: MIT
 package metrics
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -23,22 +24,54 @@ var (
 	logger *zap.SugaredLogger
 )
 
-// Default metadata (in JSON) to also put at the top of logs for parsing
-// I'd like to improve upon this manual approach, it's a bit messy.
+// Default metadata (in JSON) to also put at the top of logs for parsing.
+// The fields filled by the entrypoint are expanded when it is echoed.
 func Metadata(set *api.MetricSet, metric *Metric) string {
 
 	m := (*metric)
 	export := metadata.MetricExport{
+		Version:         metadata.Version,
+		OperatorVersion: metadata.OperatorVersion,
 
 		// Global
-		Pods: set.Spec.Pods,
+		Pods:      m.Pods(),
+		MetricSet: set.Name,
+		Namespace: set.Namespace,
+		UID:       string(set.UID),
+		SpecHash:  SpecHash(set),
 
 		// Metric
 		MetricName:        m.Name(),
 		MetricDescription: m.Description(),
+		MetricType:        m.Family(),
 		MetricOptions:     m.Options(),
 		MetricListOptions: m.ListOptions(),
+		PodResources:      resourceStrings(set.Spec.Resources),
 	}
+	for _, addon := range m.GetAddons() {
+		a := (*addon)
+		export.Addons = append(export.Addons, metadata.AddonExport{
+			Name:        a.Name(),
+			Options:     a.Options(),
+			ListOptions: a.ListOptions(),
+		})
+	}
+	resources := m.Resources()
+	if resources != nil && (len(resources.Limits) > 0 || len(resources.Requests) > 0) {
+		export.Resources = &metadata.ResourcesExport{
+			Limits:   resourceStrings(resources.Limits),
+			Requests: resourceStrings(resources.Requests),
+		}
+	}
+	digests := map[string]string{}
+	for _, image := range set.Status.Images {
+		digests[image.Image] = image.Digest
+	}
+	for _, image := range MetricImages(m) {
+		export.Images = append(export.Images, metadata.ImageExport{Image: image, Digest: digests[image]})
+	}
+	export.SetRuntime()
+
 	metadata, err := json.Marshal(export)
 	if err != nil {
 		logger.Errorf("Warning, error serializing spec metadata: %s", err.Error())
@@ -48,6 +81,61 @@ func Metadata(set *api.MetricSet, metric *Metric) string {
 	return fmt.Sprintf("METADATA START %s\nMETADATA END", metadataEscaped)
 }
 
+// SpecHash is a hash of the spec of a MetricSet, e.g., sha256:<hex>
+func SpecHash(set *api.MetricSet) string {
+	spec, err := json.Marshal(set.Spec)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(spec))
+}
+
+// MetricImages are the images of a metric and the containers of its addons
+func MetricImages(m Metric) []string {
+	images := []string{}
+	seen := map[string]bool{}
+	add := func(image string) {
+		if image != "" && !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	add(m.Image())
+	for _, addon := range m.GetAddons() {
+		for _, container := range (*addon).AssembleContainers() {
+			add(container.Image)
+		}
+	}
+	return images
+}
+
+// Images are the images of all metrics of a set, for the controller to resolve
+func Images(set *MetricSet) []string {
+	images := []string{}
+	seen := map[string]bool{}
+	for _, metric := range set.Metrics() {
+		for _, image := range MetricImages(*metric) {
+			if !seen[image] {
+				seen[image] = true
+				images = append(images, image)
+			}
+		}
+	}
+	return images
+}
+
+// resourceStrings converts resources (e.g., memory: 1Gi, cpu: 2) to strings
+func resourceStrings(resources api.ContainerResource) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	values := map[string]string{}
+	for name, value := range resources {
+		values[name] = value.String()
+	}
+	return values
+}
+
 func init() {
 	handle, err := zap.NewProduction()
 	if err != nil {
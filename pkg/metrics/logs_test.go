@@ -0,0 +1,81 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics_test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// TestMetadata echoes the metadata like an entrypoint, and parses it
+func TestMetadata(t *testing.T) {
+	image := "ghcr.io/converged-computing/metric-osu-benchmark:latest"
+	spec := &api.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default", UID: "1234"},
+		Spec: api.MetricSetSpec{
+			Pods:        2,
+			ServiceName: "ms",
+			Resources:   api.ContainerResource{"memory": intstr.FromString("1Gi")},
+			Metrics: []api.Metric{{
+				Name:      "network-osu-benchmark",
+				Resources: api.ContainerResources{Requests: api.ContainerResource{"cpu": intstr.FromInt(2)}},
+				Addons: []api.MetricAddon{{
+					Name:    "commands",
+					Options: map[string]intstr.IntOrString{"preBlock": intstr.FromString("echo hello")},
+				}},
+			}},
+		},
+		Status: api.MetricSetStatus{Images: []api.ImageStatus{{Image: image, Digest: "sha256:abcd"}}},
+	}
+	spec.Validate()
+	m, err := metrics.GetMetric(&spec.Spec.Metrics[0], spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	command := exec.Command("bash", "-c", `echo "`+metrics.Metadata(spec, &m)+`"`)
+	command.Env = append(os.Environ(), metadata.NodeEnv+"=node-1", metadata.PodEnv+"=ms-l-0-0-abcde", "JOB_COMPLETION_INDEX=0")
+	out, err := command.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	line := strings.TrimPrefix(strings.Split(string(out), "\n")[0], "METADATA START ")
+	export := metadata.MetricExport{}
+	err = json.Unmarshal([]byte(line), &export)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+
+	if export.Version != metadata.Version || export.MetricSet != "ms" || export.UID != "1234" ||
+		export.SpecHash != metrics.SpecHash(spec) || !strings.HasPrefix(export.SpecHash, "sha256:") {
+		t.Errorf("unexpected MetricSet metadata: %s", line)
+	}
+	if len(export.Addons) != 1 || export.Addons[0].Name != "commands" ||
+		export.Addons[0].Options["preBlock"].StrVal != "echo hello" {
+		t.Errorf("unexpected addons metadata: %s", line)
+	}
+	if export.PodResources["memory"] != "1Gi" || export.Resources == nil || export.Resources.Requests["cpu"] != "2" {
+		t.Errorf("unexpected resources metadata: %s", line)
+	}
+	if len(export.Images) != 1 || export.Images[0].Image != image || export.Images[0].Digest != "sha256:abcd" {
+		t.Errorf("unexpected images metadata: %s", line)
+	}
+	if export.Node != "node-1" || export.Pod != "ms-l-0-0-abcde" || export.PodIndex != "0" || export.Kernel == "" {
+		t.Errorf("unexpected runtime metadata: %s", line)
+	}
+}
@@ -38,6 +38,12 @@ type Metric interface {
 
 	// Options and exportable attributes
 	SetOptions(*api.Metric)
+	SetPods(*api.Metric, *api.MetricSet)
+	Pods() int32
+	SetTimeout(*api.Metric)
+	Timeout() int32
+	SetTemplates(*api.Metric)
+	Templates() []api.EntrypointTemplate
 	Options() map[string]intstr.IntOrString
 	ListOptions() map[string][]intstr.IntOrString
 
@@ -59,6 +65,12 @@ type Metric interface {
 	PrepareContainers(*api.MetricSet, *Metric) []*specs.ContainerSpec
 }
 
+// A launcherMetric has options and pods shared by launcher worker metrics
+type launcherMetric interface {
+	SetLauncherOptions(*api.Metric)
+	ValidatePods() bool
+}
+
 // GetMetric returns a metric, if it is known to the metrics operator
 // We also confirm that the addon exists, validate, and instantiate it.
 func GetMetric(metric *api.Metric, set *api.MetricSet) (Metric, error) {
@@ -74,22 +86,43 @@ func GetMetric(metric *api.Metric, set *api.MetricSet) (Metric, error) {
 		}
 		m := reflect.New(templateType.Type()).Interface().(Metric)
 
+		// Launcher worker options come first, because metrics use the
+		// MPI launcher for their default commands
+		launcher, ok := m.(launcherMetric)
+		if ok {
+			launcher.SetLauncherOptions(metric)
+		}
+
 		// Set global and custom options on the registry metric from the CRD
 		m.SetOptions(metric)
+		m.SetPods(metric, set)
+		if ok && !launcher.ValidatePods() {
+			return nil, fmt.Errorf("%s launcher and worker pods do not add up to its pods", metric.Name)
+		}
+		m.SetTimeout(metric)
+		m.SetTemplates(metric)
 
 		// If the metric has a custom container, set here
 		if metric.Image != "" {
 			m.SetContainer(metric.Image)
 		}
 
+		// Addons are sized by the metric they customize, not the MetricSet
+		sized := set.DeepCopy()
+		sized.Spec.Pods = m.Pods()
+
 		// Register addons, meaning adding the spec but not instantiating yet (or should we?)
 		for _, a := range metric.Addons {
 
 			logger.Infof("Attempting to add addon %s", a.Name)
-			addon, err := addons.GetAddon(&a, set)
+			addon, err := addons.GetAddon(&a, sized)
 			if err != nil {
 				return nil, fmt.Errorf("addon %s for metric %s did not validate", a.Name, metric.Name)
 			}
+			ma, ok := addon.(addons.MetricAddon)
+			if ok {
+				ma.SetMetric(metric)
+			}
 			logger.Infof("Registering addon %s", a.Name)
 			m.RegisterAddon(&addon)
 		}
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// An MPILauncher generates the hostfile and command line for an MPI
+// implementation, so metrics don't hand write flags for one of them.
+type MPILauncher interface {
+	Name() string
+
+	// Hostfile returns a shell snippet that writes the hostfile of the job
+	// from its hosts, a file with one host per line
+	Hostfile(job MPIJob) string
+
+	// Command returns the launcher command, to be followed by the application
+	Command(job MPIJob) string
+}
+
+// An MPIJob describes how to launch an application. Values are strings so
+// they can be shell variables that are known at runtime (e.g., $np)
+type MPIJob struct {
+
+	// A file with one host per line, and the hostfile for the launcher.
+	// When the hostfile is not set, the hosts file is used as is.
+	Hosts    string
+	Hostfile string
+
+	// Total processes, and processes per node (also slots in the hostfile)
+	NP  string
+	PPN string
+
+	// Map processes by socket, core, etc. when processes per node are not set,
+	// and bind them to core, socket, none, etc. (defaults of the launcher if unset)
+	Map  string
+	Bind string
+
+	// Extra flags for the launcher
+	Flags string
+}
+
+// hostfile is the hostfile that the launcher command uses
+func (j MPIJob) hostfile() string {
+	if j.Hostfile == "" {
+		return j.Hosts
+	}
+	return j.Hostfile
+}
+
+// writeHostfile writes one host per line with a format for the slots
+func writeHostfile(job MPIJob, format string) string {
+	if job.Hostfile == "" {
+		return ""
+	}
+	if job.PPN == "" {
+		return fmt.Sprintf("cp %s %s\n", job.Hosts, job.Hostfile)
+	}
+	line := fmt.Sprintf(format, "${h}", job.PPN)
+	return fmt.Sprintf(`rm -f %[2]s
+for h in $(cat %[1]s); do
+	echo "%[3]s" >> %[2]s
+done
+`, job.Hosts, job.Hostfile, line)
+}
+
+// joinFlags joins non-empty flags with a space
+func joinFlags(flags ...string) string {
+	values := []string{}
+	for _, flag := range flags {
+		if flag != "" {
+			values = append(values, flag)
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// OpenMPI uses mpirun with slots in the hostfile
+type OpenMPI struct{}
+
+func (l OpenMPI) Name() string {
+	return "openmpi"
+}
+
+func (l OpenMPI) Hostfile(job MPIJob) string {
+	return writeHostfile(job, "%s slots=%s")
+}
+
+func (l OpenMPI) Command(job MPIJob) string {
+	flags := []string{"mpirun", "--allow-run-as-root", "--hostfile", job.hostfile()}
+	if job.NP != "" {
+		flags = append(flags, "-np", job.NP)
+	}
+	if job.PPN != "" {
+		flags = append(flags, "--map-by", fmt.Sprintf("ppr:%s:node", job.PPN), "--rank-by", "core")
+	} else if job.Map != "" {
+		flags = append(flags, "--map-by", job.Map)
+	}
+	if job.Bind != "" {
+		flags = append(flags, "--bind-to", job.Bind)
+	}
+	return joinFlags(append(flags, job.Flags)...)
+}
+
+// MPICH uses the Hydra process manager (mpiexec) with host:slots in the hostfile
+type MPICH struct{}
+
+func (l MPICH) Name() string {
+	return "mpich"
+}
+
+func (l MPICH) Hostfile(job MPIJob) string {
+	return writeHostfile(job, "%s:%s")
+}
+
+func (l MPICH) Command(job MPIJob) string {
+	flags := []string{"mpiexec", "-f", job.hostfile()}
+	if job.NP != "" {
+		flags = append(flags, "-n", job.NP)
+	}
+	if job.PPN != "" {
+		flags = append(flags, "-ppn", job.PPN)
+	} else if job.Map != "" {
+		flags = append(flags, "-map-by", job.Map)
+	}
+	if job.Bind != "" {
+		flags = append(flags, "-bind-to", job.Bind)
+	}
+	return joinFlags(append(flags, job.Flags)...)
+}
+
+// PMIx uses the PMIx reference runtime (prterun), with slots in the hostfile
+type PMIx struct{}
+
+func (l PMIx) Name() string {
+	return "pmix"
+}
+
+func (l PMIx) Hostfile(job MPIJob) string {
+	return writeHostfile(job, "%s slots=%s")
+}
+
+func (l PMIx) Command(job MPIJob) string {
+	flags := []string{
+		"PRTE_ALLOW_RUN_AS_ROOT=1",
+		"PRTE_ALLOW_RUN_AS_ROOT_CONFIRM=1",
+		"prterun", "--hostfile", job.hostfile(),
+	}
+	if job.NP != "" {
+		flags = append(flags, "--np", job.NP)
+	}
+	if job.PPN != "" {
+		flags = append(flags, "--map-by", fmt.Sprintf("ppr:%s:node", job.PPN), "--rank-by", "core")
+	} else if job.Map != "" {
+		flags = append(flags, "--map-by", job.Map)
+	}
+	if job.Bind != "" {
+		flags = append(flags, "--bind-to", job.Bind)
+	}
+	return joinFlags(append(flags, job.Flags)...)
+}
+
+var (
+	// DefaultMPILauncher is used when neither the metric nor the user choose one
+	DefaultMPILauncher = "openmpi"
+
+	// MPILaunchers are selected by name with the "mpi" option
+	MPILaunchers = map[string]MPILauncher{}
+)
+
+// RegisterMPILauncher adds an MPI launcher by name
+func RegisterMPILauncher(l MPILauncher) {
+	MPILaunchers[l.Name()] = l
+}
+
+// mpiLauncherNames returns the known launchers, sorted
+func mpiLauncherNames() []string {
+	names := []string{}
+	for name := range MPILaunchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterMPILauncher(OpenMPI{})
+	RegisterMPILauncher(MPICH{})
+	RegisterMPILauncher(PMIx{})
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+func TestMPILaunchers(t *testing.T) {
+	job := metrics.MPIJob{Hosts: "./hostlist.txt", Hostfile: "./hostfile.mpi", NP: "$np", PPN: "$tasks", Bind: "core"}
+	for name, expected := range map[string][]string{
+		"openmpi": {"mpirun --allow-run-as-root --hostfile ./hostfile.mpi -np $np --map-by ppr:$tasks:node --rank-by core --bind-to core", "${h} slots=$tasks"},
+		"mpich":   {"mpiexec -f ./hostfile.mpi -n $np -ppn $tasks -bind-to core", "${h}:$tasks"},
+		"pmix":    {"prterun --hostfile ./hostfile.mpi --np $np --map-by ppr:$tasks:node --rank-by core --bind-to core", "${h} slots=$tasks"},
+	} {
+		launcher := metrics.MPILaunchers[name]
+		command := launcher.Command(job)
+		if !strings.HasSuffix(command, expected[0]) {
+			t.Errorf("%s: unexpected command %s", name, command)
+		}
+		if !strings.Contains(launcher.Hostfile(job), expected[1]) {
+			t.Errorf("%s: unexpected hostfile %s", name, launcher.Hostfile(job))
+		}
+	}
+
+	// Without a hostfile or processes per node, the hosts file is used as is
+	command := metrics.MPILaunchers["openmpi"].Command(metrics.MPIJob{Hosts: "./hostlist.txt", NP: "2", Map: "socket"})
+	if command != "mpirun --allow-run-as-root --hostfile ./hostlist.txt -np 2 --map-by socket" {
+		t.Errorf("unexpected command %s", command)
+	}
+}
+
+func TestMPIOption(t *testing.T) {
+	for launcher, valid := range map[string]bool{"mpich": true, "slurm": false} {
+		spec := &api.MetricSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+			Spec: api.MetricSetSpec{
+				Pods:        2,
+				ServiceName: "ms",
+				Metrics: []api.Metric{{
+					Name:    "network-osu-benchmark",
+					Options: map[string]intstr.IntOrString{"mpi": intstr.FromString(launcher)},
+				}},
+			},
+		}
+		spec.Validate()
+		m, err := metrics.GetMetric(&spec.Spec.Metrics[0], spec)
+		if !valid {
+			if err == nil {
+				t.Errorf("expected launcher %s to be invalid", launcher)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		set := metrics.MetricSet{}
+		set.Add(&m)
+		_, containerSpecs, err := metrics.GetJobSet(spec, &set)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(containerSpecs[0].EntrypointScript.WriteScript(), "mpiexec -f ./hostfile-pairs.mpi -n 2 -ppn 1 ") {
+			t.Errorf("expected osu to use mpich:\n%s", containerSpecs[0].EntrypointScript.WriteScript())
+		}
+	}
+}
@@ -140,8 +140,7 @@ func (m Chatterbug) PrepareContainers(
 	// The launcher has a different hostname, n for netmark
 	hosts := m.GetHostlist(spec)
 	prefixTemplate := `#!/bin/bash
-# Start ssh daemon
-/usr/sbin/sshd -D &
+%s
 
 # If we have zero tasks, default to workers * nproc for total tasks
 # This is only for non point to point benchmarks
@@ -157,42 +156,35 @@ fi
 echo "Number of tasks (nproc on one node) is $tasks"
 echo "Number of tasks total (across $pods nodes) is $np"
 
-# Allow network to ready
-echo "Sleeping for 10 seconds waiting for network..."
-sleep 10
-
 # Write the hosts file.
 cat <<EOF > ./hostnames.txt
 %s
 EOF
-
-# openmpi is evil and we need the ip addresses
-for h in $(cat ./hostnames.txt); do
-   if [[ "$h" == "" ]]; then
-      continue
-   fi
-   address=$(getent hosts $h | awk '{ print $1 }')
-   echo "${address}" >> ./hostlist.txt
-done   
-
+%s
+%s%s
 cat ./hostlist.txt
 # Show metadata for run
 echo "%s"
 `
 	prefix := fmt.Sprintf(
 		prefixTemplate,
+		m.SSH(),
 		m.tasks,
-		spec.Spec.Pods,
+		m.Pods(),
 		hosts,
+		m.Rendezvous("./hostnames.txt"),
+		m.ConvertHostnames(),
+		m.FluxBootstrap(),
 		meta,
 	)
 
 	// Prepare command for chatterbug
-	commands := fmt.Sprintf("\nsleep 5\necho %s\n", metadata.CollectionStart)
+	commands := fmt.Sprintf("\necho %s\n", metadata.CollectionStart)
 
 	// Full path to, e.g., /root/chatterbug/stencil3d/stencil3d.x
 	command := path.Join("/root/chatterbug", m.command, ChatterbugApps[m.command])
-	line := fmt.Sprintf("mpirun --hostfile ./hostlist.txt --allow-run-as-root %s %s %s", m.mpirun, command, m.args)
+	mpirun := m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt", Flags: m.mpirun})
+	line := fmt.Sprintf("%s %s %s", mpirun, command, m.args)
 
 	commands += fmt.Sprintf("echo %s\necho \"%s\"\n", metadata.Separator, line)
 
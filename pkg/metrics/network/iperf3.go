@@ -0,0 +1,426 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	metrics "github.com/converged-computing/metrics-operator/pkg/metrics"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+)
+
+// iperf3 measures TCP and UDP throughput between pods, without MPI.
+// https://software.es.net/iperf/
+
+const (
+	iperf3Identifier = "network-iperf3"
+	iperf3Summary    = "TCP and UDP network throughput between pods"
+	iperf3Container  = "ghcr.io/converged-computing/metric-iperf3:latest"
+)
+
+// Modes of pairing clients and servers
+const (
+	// The launcher is a client of each worker, one at a time
+	iperf3ModeLauncher = "launcher"
+
+	// Each pod is a client of every other pod, in rounds where a pod is in one pair
+	iperf3ModePairwise = "pairwise"
+)
+
+// The launcher prints the json output of each pair between these lines
+const (
+	iperf3PairStart = "IPERF3 PAIR START"
+	iperf3PairEnd   = "IPERF3 PAIR END"
+)
+
+type Iperf3 struct {
+	metrics.LauncherWorker
+
+	// Options
+	mode     string
+	protocol string
+	parallel int32
+	duration int32
+	window   string
+	bitrate  string
+	port     int32
+}
+
+// Family returns the network family
+func (m Iperf3) Family() string {
+	return metrics.NetworkFamily
+}
+
+func (m Iperf3) Url() string {
+	return "https://software.es.net/iperf/"
+}
+
+// Set custom options / attributes for the metric
+func (m *Iperf3) SetOptions(metric *api.Metric) {
+	m.ResourceSpec = &metric.Resources
+	m.AttributeSpec = &metric.Attributes
+
+	m.Identifier = iperf3Identifier
+	m.Summary = iperf3Summary
+	m.Container = iperf3Container
+
+	// One pod per hostname, so we measure the network between nodes
+	m.SoleTenancy = true
+
+	// Defaults
+	m.mode = iperf3ModeLauncher
+	m.protocol = "tcp"
+	m.parallel = 1
+	m.duration = 10
+	m.port = 5201
+
+	st, ok := metric.Options["soleTenancy"]
+	if ok && (st.StrVal == "false" || st.StrVal == "no") {
+		m.SoleTenancy = false
+	}
+	mode, ok := metric.Options["mode"]
+	if ok {
+		m.mode = mode.StrVal
+	}
+	protocol, ok := metric.Options["protocol"]
+	if ok {
+		m.protocol = strings.ToLower(protocol.StrVal)
+	}
+	parallel, ok := metric.Options["parallel"]
+	if ok && parallel.IntVal > 0 {
+		m.parallel = parallel.IntVal
+	}
+	duration, ok := metric.Options["duration"]
+	if ok && duration.IntVal > 0 {
+		m.duration = duration.IntVal
+	}
+	window, ok := metric.Options["window"]
+	if ok {
+		m.window = window.StrVal
+	}
+	bitrate, ok := metric.Options["bitrate"]
+	if ok {
+		m.bitrate = bitrate.StrVal
+	}
+	port, ok := metric.Options["port"]
+	if ok && port.IntVal > 0 {
+		m.port = port.IntVal
+	}
+}
+
+// Validate the mode and protocol, and the launcher worker
+func (m Iperf3) Validate(spec *api.MetricSet) bool {
+	if m.mode != iperf3ModeLauncher && m.mode != iperf3ModePairwise {
+		fmt.Printf("🟥️ Mode %s is not known, choices are %s and %s\n", m.mode, iperf3ModeLauncher, iperf3ModePairwise)
+		return false
+	}
+	if m.protocol != "tcp" && m.protocol != "udp" {
+		fmt.Printf("🟥️ Protocol %s is not known, choices are tcp and udp\n", m.protocol)
+		return false
+	}
+	if m.FluxEnabled() {
+		fmt.Printf("🟥️ iperf3 connects to the servers of the pods, and does not run in a Flux instance\n")
+		return false
+	}
+	return m.LauncherWorker.Validate(spec)
+}
+
+// Exported options and list options
+func (m Iperf3) Options() map[string]intstr.IntOrString {
+	values := map[string]intstr.IntOrString{
+		"mode":        intstr.FromString(m.mode),
+		"protocol":    intstr.FromString(m.protocol),
+		"parallel":    intstr.FromInt(int(m.parallel)),
+		"duration":    intstr.FromInt(int(m.duration)),
+		"window":      intstr.FromString(m.window),
+		"bitrate":     intstr.FromString(m.bitrate),
+		"port":        intstr.FromInt(int(m.port)),
+		"soleTenancy": intstr.FromString("false"),
+	}
+	if m.SoleTenancy {
+		values["soleTenancy"] = intstr.FromString("true")
+	}
+	return values
+}
+
+// clientFlags are the flags of each client
+func (m Iperf3) clientFlags() string {
+	flags := fmt.Sprintf("-p %d -P %d -t %d -J", m.port, m.parallel, m.duration)
+	if m.protocol == "udp" {
+		flags += " -u"
+	}
+	if m.window != "" {
+		flags += " -w " + m.window
+	}
+	if m.bitrate != "" {
+		flags += " -b " + m.bitrate
+	}
+	return flags
+}
+
+// iperf3Schedule returns rounds of (client, server) pairs, by index of the pods
+// in the hostlist (the launcher is 0). A pod is in at most one pair of a round,
+// so links are not oversubscribed. Pairwise, every pod is a client of every other
+// pod, using the circle method for round robin tournaments in each direction.
+func iperf3Schedule(pods int32, pairwise bool) [][][2]int32 {
+	rounds := [][][2]int32{}
+	if !pairwise {
+		for worker := int32(1); worker < pods; worker++ {
+			rounds = append(rounds, [][2]int32{{0, worker}})
+		}
+		return rounds
+	}
+
+	// With an odd number of pods, one sits out each round (paired with n)
+	n := pods
+	if n%2 == 1 {
+		n++
+	}
+	forward := [][][2]int32{}
+	reverse := [][][2]int32{}
+	for round := int32(0); round < n-1; round++ {
+		pairs := [][2]int32{}
+		backward := [][2]int32{}
+		for i := int32(0); i < n/2; i++ {
+			a := (round + i) % (n - 1)
+			b := (round + n - 1 - i) % (n - 1)
+			if i == 0 {
+				b = n - 1
+			}
+			if a >= pods || b >= pods {
+				continue
+			}
+			pairs = append(pairs, [2]int32{a, b})
+			backward = append(backward, [2]int32{b, a})
+		}
+		forward = append(forward, pairs)
+		reverse = append(reverse, backward)
+	}
+	return append(forward, reverse...)
+}
+
+func (m Iperf3) PrepareContainers(
+	spec *api.MetricSet,
+	metric *metrics.Metric,
+) []*specs.ContainerSpec {
+
+	// Metadata to add to beginning of run
+	meta := metrics.Metadata(spec, metric)
+	hosts := m.GetHostlist(spec)
+
+	// Each line of the schedule is a round, client, and server
+	schedule := ""
+	rounds := iperf3Schedule(m.Pods(), m.mode == iperf3ModePairwise)
+	for round, pairs := range rounds {
+		for _, pair := range pairs {
+			schedule += fmt.Sprintf("%d %d %d\n", round, pair[0], pair[1])
+		}
+	}
+
+	// Every pod runs a server, and waits for it to listen before the rendezvous, so
+	// the servers of all pods are ready after it. We don't connect to the servers to
+	// check, since iperf3 counts a connection as a test, and is busy while it runs.
+	prefixTemplate := `#!/bin/bash
+%s
+echo "%s"
+
+# Write the hosts file
+cat <<EOF > ./hostlist.txt
+%s
+EOF
+
+# Start the iperf3 server, and wait for it to listen (state 0A)
+iperf3 -s -D -p %d
+for i in $(seq 1 30); do
+	grep -Eq ":%04X [0-9A-F]+:[0-9A-F]+ 0A" /proc/net/tcp /proc/net/tcp6 2>/dev/null && break
+	sleep 1
+done
+%s
+`
+	prefix := fmt.Sprintf(
+		prefixTemplate,
+		metrics.SSHScript(),
+		meta,
+		hosts,
+		m.port,
+		m.port,
+		m.Rendezvous("./hostlist.txt"),
+	)
+
+	// The launcher runs the clients of each round (on other pods over ssh), and
+	// prints the output of each pair after the rounds
+	launcherTemplate := `
+# Write the schedule of rounds (round, client, server)
+cat <<EOF > ./schedule.txt
+%s
+EOF
+hosts=($(cat ./hostlist.txt))
+iperf3_client() {
+	local client=$1
+	local server=$2
+	local command="iperf3 -c ${hosts[$server]} %s"
+
+	# Retry a server that is busy (e.g., still finishing the last test)
+	for attempt in $(seq 1 5); do
+		if [[ $client -eq 0 ]]; then
+			${command} > ./iperf3-${client}-${server}.json && return
+		else
+			ssh -n ${hosts[$client]} ${command} > ./iperf3-${client}-${server}.json && return
+		fi
+		sleep ${attempt}
+	done
+}
+echo "IPERF3 ROUNDS %d"
+echo "%s"
+echo "%s"
+`
+	preBlock := prefix + fmt.Sprintf(
+		launcherTemplate,
+		schedule,
+		m.clientFlags(),
+		len(rounds),
+		metadata.CollectionStart,
+		metadata.Separator,
+	)
+
+	command := fmt.Sprintf(`for round in $(seq 0 %d); do
+	while read r client server; do
+		if [[ "$r" == "$round" ]]; then
+			iperf3_client $client $server &
+		fi
+	done < ./schedule.txt
+	wait
+done
+while read r client server; do
+	if [[ "$r" != "" ]]; then
+		echo "%s $client $server"
+		cat ./iperf3-${client}-${server}.json
+		echo "%s"
+	fi
+done < ./schedule.txt`, len(rounds)-1, iperf3PairStart, iperf3PairEnd)
+
+	interactive := metadata.Interactive(spec.Spec.Logging.Interactive)
+	postBlock := fmt.Sprintf("\necho \"%s\"\n%s\n", metadata.CollectionEnd, interactive)
+
+	launcherEntrypoint := specs.EntrypointScript{
+		Name:    specs.DeriveScriptKey(m.LauncherScript),
+		Path:    m.LauncherScript,
+		Pre:     preBlock,
+		Command: command,
+		Post:    postBlock,
+	}
+
+	// The workers serve until the launcher is done
+	workerEntrypoint := specs.EntrypointScript{
+		Name:    specs.DeriveScriptKey(m.WorkerScript),
+		Path:    m.WorkerScript,
+		Pre:     prefix,
+		Command: "sleep infinity",
+	}
+
+	launcherContainer := m.GetLauncherContainerSpec(launcherEntrypoint)
+	workerContainer := m.GetWorkerContainerSpec(workerEntrypoint)
+	return []*specs.ContainerSpec{&launcherContainer, &workerContainer}
+}
+
+// iperf3Output is the part of the iperf3 json output that we parse
+type iperf3Output struct {
+	End struct {
+		Sent     iperf3Sum `json:"sum_sent"`
+		Received iperf3Sum `json:"sum_received"`
+
+		// UDP has the jitter and loss (seen by the server) in the sum
+		Sum *iperf3Sum `json:"sum"`
+	} `json:"end"`
+	Error string `json:"error"`
+}
+
+type iperf3Sum struct {
+	BitsPerSecond float64  `json:"bits_per_second"`
+	Retransmits   *float64 `json:"retransmits"`
+	JitterMs      *float64 `json:"jitter_ms"`
+	LostPercent   *float64 `json:"lost_percent"`
+}
+
+// ParseResults returns the throughput of each pair of pods (client and server,
+// by index in the hostlist), with retransmits for TCP and jitter and loss for UDP
+func (m Iperf3) ParseResults(log string) []metrics.Sample {
+	samples := []metrics.Sample{}
+	for _, section := range strings.Split(log, iperf3PairStart)[1:] {
+		end := strings.Index(section, iperf3PairEnd)
+		if end < 0 {
+			continue
+		}
+		section = section[:end]
+		newline := strings.Index(section, "\n")
+		if newline < 0 {
+			continue
+		}
+		pair := strings.Fields(section[:newline])
+		if len(pair) != 2 {
+			continue
+		}
+		output := iperf3Output{}
+		err := json.Unmarshal([]byte(section[newline+1:]), &output)
+		if err != nil || output.Error != "" {
+			continue
+		}
+		labels := map[string]string{"source": pair[0], "destination": pair[1]}
+
+		// The sum of UDP is of the received datagrams in older versions
+		received := output.End.Received
+		if received.BitsPerSecond == 0 && output.End.Sum != nil {
+			received = *output.End.Sum
+		}
+		samples = append(samples, metrics.Sample{
+			Name: "throughput_mbits", Labels: labels, Value: received.BitsPerSecond / 1e6,
+			Unit: "Mbit/s", Better: metrics.BetterHigher,
+		})
+		if output.End.Sent.Retransmits != nil {
+			samples = append(samples, metrics.Sample{
+				Name: "retransmits", Labels: labels, Value: *output.End.Sent.Retransmits,
+				Better: metrics.BetterLower,
+			})
+		}
+		if output.End.Sum != nil && output.End.Sum.JitterMs != nil {
+			samples = append(samples, metrics.Sample{
+				Name: "jitter_ms", Labels: labels, Value: *output.End.Sum.JitterMs,
+				Unit: "ms", Better: metrics.BetterLower,
+			})
+		}
+		if output.End.Sum != nil && output.End.Sum.LostPercent != nil {
+			samples = append(samples, metrics.Sample{
+				Name: "lost_percent", Labels: labels, Value: *output.End.Sum.LostPercent,
+				Unit: "%", Better: metrics.BetterLower,
+			})
+		}
+	}
+	return samples
+}
+
+// ResultChart plots each result as a matrix of clients and servers
+func (m Iperf3) ResultChart() metrics.Chart {
+	return metrics.Chart{Kind: metrics.ChartHeatmap, X: "destination", Y: "source"}
+}
+
+func init() {
+	base := metrics.BaseMetric{
+		Identifier: iperf3Identifier,
+		Summary:    iperf3Summary,
+		Container:  iperf3Container,
+	}
+	launcher := metrics.LauncherWorker{BaseMetric: base}
+	iperf3 := Iperf3{LauncherWorker: launcher}
+	metrics.Register(&iperf3)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package network
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+)
+
+func TestIperf3Schedule(t *testing.T) {
+	for pods := int32(2); pods <= 7; pods++ {
+		for _, pairwise := range []bool{false, true} {
+			name := fmt.Sprintf("%d pods, pairwise %t", pods, pairwise)
+			seen := map[[2]int32]int{}
+			for round, pairs := range iperf3Schedule(pods, pairwise) {
+				busy := map[int32]bool{}
+				for _, pair := range pairs {
+					client, server := pair[0], pair[1]
+					if client == server || client < 0 || server < 0 || client >= pods || server >= pods {
+						t.Errorf("%s: invalid pair %v in round %d", name, pair, round)
+					}
+					if busy[client] || busy[server] {
+						t.Errorf("%s: pod in more than one pair of round %d: %v", name, round, pairs)
+					}
+					busy[client] = true
+					busy[server] = true
+					seen[pair]++
+				}
+			}
+
+			// Pairwise, every pod is a client of every other pod, and otherwise
+			// the launcher is a client of each worker
+			expected := map[[2]int32]int{}
+			for client := int32(0); client < pods; client++ {
+				for server := int32(0); server < pods; server++ {
+					if client != server && (pairwise || client == 0) {
+						expected[[2]int32{client, server}] = 1
+					}
+				}
+			}
+			if fmt.Sprint(seen) != fmt.Sprint(expected) {
+				t.Errorf("%s: expected each pair once %v, found %v", name, expected, seen)
+			}
+		}
+	}
+}
+
+// Output of iperf3 -J (3.9), without the intervals and most of the start
+var iperf3TCP = `{
+	"start": {
+		"connected": [{"socket": 5, "local_host": "10.244.0.12", "local_port": 43318, "remote_host": "10.244.1.9", "remote_port": 5201}],
+		"version": "iperf 3.9",
+		"test_start": {"protocol": "TCP", "num_streams": 1, "blksize": 131072, "omit": 0, "duration": 10, "bytes": 0, "blocks": 0, "reverse": 0, "tos": 0}
+	},
+	"intervals": [],
+	"end": {
+		"streams": [],
+		"sum_sent": {"start": 0, "end": 10.000107, "seconds": 10.000107, "bytes": 11724177408, "bits_per_second": 9379241505.218, "retransmits": 412, "sender": true},
+		"sum_received": {"start": 0, "end": 10.000435, "seconds": 10.000435, "bytes": 11721637888, "bits_per_second": 9376904651.85, "sender": true},
+		"cpu_utilization_percent": {"host_total": 38.25, "host_user": 1.39, "host_system": 36.86, "remote_total": 52.14, "remote_user": 3.21, "remote_system": 48.93},
+		"sender_tcp_congestion": "cubic",
+		"receiver_tcp_congestion": "cubic"
+	}
+}`
+
+var iperf3UDP = `{
+	"start": {
+		"version": "iperf 3.9",
+		"test_start": {"protocol": "UDP", "num_streams": 1, "blksize": 1448, "omit": 0, "duration": 10, "bytes": 0, "blocks": 0, "reverse": 0, "tos": 0}
+	},
+	"intervals": [],
+	"end": {
+		"streams": [],
+		"sum": {"start": 0, "end": 10.000191, "seconds": 10.000191, "bytes": 1310720, "bits_per_second": 1048555.97, "jitter_ms": 0.0158, "lost_packets": 3, "packets": 905, "lost_percent": 0.3314917, "sender": true},
+		"cpu_utilization_percent": {"host_total": 0.52, "host_user": 0.11, "host_system": 0.41, "remote_total": 0.09, "remote_user": 0.01, "remote_system": 0.08}
+	}
+}`
+
+var iperf3Busy = `{
+	"start": {"connected": [], "version": "iperf 3.9"},
+	"intervals": [],
+	"end": {},
+	"error": "the server is busy running a test. try again later"
+}`
+
+func TestIperf3ParseResults(t *testing.T) {
+	pair := func(client, server int, output string) string {
+		return fmt.Sprintf("%s %d %d\n%s\n%s", iperf3PairStart, client, server, output, iperf3PairEnd)
+	}
+	tests := []struct {
+		name     string
+		log      string
+		expected map[string]float64
+	}{
+		{
+			name:     "tcp",
+			log:      pair(0, 1, iperf3TCP),
+			expected: map[string]float64{"throughput_mbits": 9376.90465185, "retransmits": 412},
+		},
+		{
+			name:     "udp",
+			log:      pair(1, 0, iperf3UDP),
+			expected: map[string]float64{"throughput_mbits": 1.04855597, "jitter_ms": 0.0158, "lost_percent": 0.3314917},
+		},
+		{name: "busy", log: pair(0, 1, iperf3Busy), expected: map[string]float64{}},
+		{name: "truncated", log: iperf3PairStart + " 0 1\n" + iperf3TCP[:200], expected: map[string]float64{}},
+	}
+	for _, test := range tests {
+		samples := Iperf3{}.ParseResults(strings.Join([]string{metadata.CollectionStart, test.log, metadata.CollectionEnd}, "\n"))
+		if len(samples) != len(test.expected) {
+			t.Errorf("%s: expected %d samples, found %v", test.name, len(test.expected), samples)
+			continue
+		}
+		for _, sample := range samples {
+			expected, ok := test.expected[sample.Name]
+			if !ok || fmt.Sprintf("%.6f", sample.Value) != fmt.Sprintf("%.6f", expected) {
+				t.Errorf("%s: unexpected sample %s=%v", test.name, sample.Name, sample.Value)
+			}
+			if sample.Labels["source"] == sample.Labels["destination"] || sample.Labels["source"] == "" {
+				t.Errorf("%s: unexpected labels %v", test.name, sample.Labels)
+			}
+		}
+	}
+}
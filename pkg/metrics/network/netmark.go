@@ -10,6 +10,7 @@ package network
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -26,6 +27,12 @@ const (
 	netmarkContainer  = "vanessa/netmark:latest"
 )
 
+// The launcher prints the RTT.csv matrix between these lines
+const (
+	netmarkRTTStart = "NETMARK RTT.CSV START"
+	netmarkRTTEnd   = "NETMARK RTT.CSV END"
+)
+
 type Netmark struct {
 	metrics.LauncherWorker
 
@@ -62,6 +69,7 @@ func (m *Netmark) SetOptions(metric *api.Metric) {
 	m.ResourceSpec = &metric.Resources
 	m.AttributeSpec = &metric.Attributes
 	m.LauncherLetter = "n"
+	m.DefaultMPI = "mpich"
 
 	m.Identifier = netmarkIdentifier
 	m.Summary = netmarkSummary
@@ -147,8 +155,7 @@ func (m Netmark) PrepareContainers(
 	}
 
 	prefixTemplate := `#!/bin/bash
-# Start ssh daemon
-/usr/sbin/sshd -D &
+%s
 echo "%s"
 
 # If we have zero tasks, default to workers * nproc
@@ -163,25 +170,26 @@ fi
 cat <<EOF > ./hostlist.txt
 %s
 EOF
-
-# Allow network to ready
-echo "Sleeping for 10 seconds waiting for network..."
-sleep 10
+%s%s
 echo "%s"
 `
 	prefix := fmt.Sprintf(
 		prefixTemplate,
+		m.SSH(),
 		meta,
 		m.tasks,
-		spec.Spec.Pods,
+		m.Pods(),
 		hosts,
+		m.Rendezvous("./hostlist.txt"),
+		m.FluxBootstrap(),
 		metadata.CollectionStart,
 	)
 
 	// Netmark main command
-	command := "mpirun -f ./hostlist.txt -np $np /usr/local/bin/netmark.x -w %d -t %d -c %d -b %d %s"
+	command := "%s /usr/local/bin/netmark.x -w %d -t %d -c %d -b %d %s"
 	command = fmt.Sprintf(
 		command,
+		m.MPI().Command(metrics.MPIJob{Hosts: "./hostlist.txt", NP: "$np"}),
 		m.warmups,
 		m.trials,
 		m.sendReceiveCycles,
@@ -193,15 +201,17 @@ echo "%s"
 
 	postBlock := `
 ls
-echo "NETMARK RTT.CSV START"
+echo "%s"
 cat RTT.csv
-echo "NETMARK RTT.CSV END"
+echo "%s"
 echo "%s"
 %s
 `
 	interactive := metadata.Interactive(spec.Spec.Logging.Interactive)
 	postBlock = fmt.Sprintf(
 		postBlock,
+		netmarkRTTStart,
+		netmarkRTTEnd,
 		metadata.CollectionEnd,
 		interactive,
 	)
@@ -231,6 +241,50 @@ echo "%s"
 	return []*specs.ContainerSpec{&launcherContainer, &workerContainer}
 }
 
+// ParseResults returns the round trip time between each pair of ranks, from
+// the RTT.csv matrix of the launcher (a row for each source rank)
+func (m Netmark) ParseResults(log string) []metrics.Sample {
+	samples := []metrics.Sample{}
+	start := strings.Index(log, netmarkRTTStart)
+	if start < 0 {
+		return samples
+	}
+	matrix := log[start+len(netmarkRTTStart):]
+	end := strings.Index(matrix, netmarkRTTEnd)
+	if end >= 0 {
+		matrix = matrix[:end]
+	}
+	source := 0
+	for _, line := range strings.Split(matrix, "\n") {
+		destination := 0
+		for _, field := range strings.Split(line, ",") {
+			value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				continue
+			}
+			samples = append(samples, metrics.Sample{
+				Name: "rtt",
+				Labels: map[string]string{
+					"source":      strconv.Itoa(source),
+					"destination": strconv.Itoa(destination),
+				},
+				Value:  value,
+				Better: metrics.BetterLower,
+			})
+			destination++
+		}
+		if destination > 0 {
+			source++
+		}
+	}
+	return samples
+}
+
+// ResultChart plots the round trip times as a matrix of ranks
+func (m Netmark) ResultChart() metrics.Chart {
+	return metrics.Chart{Kind: metrics.ChartHeatmap, X: "destination", Y: "source"}
+}
+
 func init() {
 	base := metrics.BaseMetric{
 		Identifier: netmarkIdentifier,
@@ -10,6 +10,9 @@ package network
 import (
 	"fmt"
 	"path"
+	"regexp"
+	"strconv"
+	"strings"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -27,10 +30,11 @@ const (
 	OSUContainer  = "ghcr.io/converged-computing/metric-osu-benchmark:latest"
 )
 
+// A BenchmarkConfig is run with one process per node on a pair of hosts,
+// or with tasks per node on all hosts
 type BenchmarkConfig struct {
-	Workdir  string
-	Flags    string
-	HostFile string
+	Workdir string
+	Pairs   bool
 }
 
 var (
@@ -56,57 +60,57 @@ var (
 	osuBenchmarkCommands = map[string]BenchmarkConfig{
 
 		// Single Sided (all require exactly 2 processes)
-		"osu_get_acc_latency": {Workdir: singleSidedDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"},
-		"osu_acc_latency":     {Workdir: singleSidedDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"}, // Latency Test for Accumulate
-		"osu_fop_latency":     {Workdir: singleSidedDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"},
-		"osu_get_latency":     {Workdir: singleSidedDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"}, // Latency Test for Get
-		"osu_put_latency":     {Workdir: singleSidedDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"}, // Latency Test for Put
-		"osu_cas_latency":     {Workdir: singleSidedDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"},
-		"osu_get_bw":          {Workdir: singleSidedDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"},
-		"osu_put_bibw":        {Workdir: singleSidedDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"},
-		"osu_put_bw":          {Workdir: singleSidedDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"},
+		"osu_get_acc_latency": {Workdir: singleSidedDir, Pairs: true},
+		"osu_acc_latency":     {Workdir: singleSidedDir, Pairs: true}, // Latency Test for Accumulate
+		"osu_fop_latency":     {Workdir: singleSidedDir, Pairs: true},
+		"osu_get_latency":     {Workdir: singleSidedDir, Pairs: true}, // Latency Test for Get
+		"osu_put_latency":     {Workdir: singleSidedDir, Pairs: true}, // Latency Test for Put
+		"osu_cas_latency":     {Workdir: singleSidedDir, Pairs: true},
+		"osu_get_bw":          {Workdir: singleSidedDir, Pairs: true},
+		"osu_put_bibw":        {Workdir: singleSidedDir, Pairs: true},
+		"osu_put_bw":          {Workdir: singleSidedDir, Pairs: true},
 
 		// Collective
 		// For allreduce this should work, need to test -np $np -map-by ppr:1:node -rank-by core
-		"osu_allreduce":      {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"}, // MPI_Allreduce Latency Test
-		"osu_allgather":      {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_allgatherv":     {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_alltoall":       {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_alltoallv":      {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_barrier":        {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_bcast":          {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_gather":         {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_gatherv":        {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_iallgather":     {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_iallgatherv":    {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_iallreduce":     {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_ialltoall":      {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_ialltoallv":     {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_ialltoallw":     {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_ibarrier":       {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_ibcast":         {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_igather":        {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_igatherv":       {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_ireduce":        {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_iscatter":       {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_iscatterv":      {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_reduce":         {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_reduce_scatter": {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_scatter":        {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_scatterv":       {Workdir: collectiveDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
+		"osu_allreduce":      {Workdir: collectiveDir}, // MPI_Allreduce Latency Test
+		"osu_allgather":      {Workdir: collectiveDir},
+		"osu_allgatherv":     {Workdir: collectiveDir},
+		"osu_alltoall":       {Workdir: collectiveDir},
+		"osu_alltoallv":      {Workdir: collectiveDir},
+		"osu_barrier":        {Workdir: collectiveDir},
+		"osu_bcast":          {Workdir: collectiveDir},
+		"osu_gather":         {Workdir: collectiveDir},
+		"osu_gatherv":        {Workdir: collectiveDir},
+		"osu_iallgather":     {Workdir: collectiveDir},
+		"osu_iallgatherv":    {Workdir: collectiveDir},
+		"osu_iallreduce":     {Workdir: collectiveDir},
+		"osu_ialltoall":      {Workdir: collectiveDir},
+		"osu_ialltoallv":     {Workdir: collectiveDir},
+		"osu_ialltoallw":     {Workdir: collectiveDir},
+		"osu_ibarrier":       {Workdir: collectiveDir},
+		"osu_ibcast":         {Workdir: collectiveDir},
+		"osu_igather":        {Workdir: collectiveDir},
+		"osu_igatherv":       {Workdir: collectiveDir},
+		"osu_ireduce":        {Workdir: collectiveDir},
+		"osu_iscatter":       {Workdir: collectiveDir},
+		"osu_iscatterv":      {Workdir: collectiveDir},
+		"osu_reduce":         {Workdir: collectiveDir},
+		"osu_reduce_scatter": {Workdir: collectiveDir},
+		"osu_scatter":        {Workdir: collectiveDir},
+		"osu_scatterv":       {Workdir: collectiveDir},
 
 		// Point to Point (commented if requires 2 processes)
-		"osu_latency":    {Workdir: pointToPointDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"}, // Latency Test (requires 2)
-		"osu_bibw":       {Workdir: pointToPointDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"}, // Bidirectional Bandwidth Test (requires 2)
-		"osu_bw":         {Workdir: pointToPointDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"}, // Bandwidth Test (requires 2)
-		"osu_latency_mp": {Workdir: pointToPointDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"}, // requires 2
-		"osu_latency_mt": {Workdir: pointToPointDir, Flags: "-np 2 -map-by ppr:1:node", HostFile: "./hostlist-pairs.txt"}, // requires 2
-		"osu_mbw_mr":     {Workdir: pointToPointDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_multi_lat":  {Workdir: pointToPointDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
+		"osu_latency":    {Workdir: pointToPointDir, Pairs: true}, // Latency Test (requires 2)
+		"osu_bibw":       {Workdir: pointToPointDir, Pairs: true}, // Bidirectional Bandwidth Test (requires 2)
+		"osu_bw":         {Workdir: pointToPointDir, Pairs: true}, // Bandwidth Test (requires 2)
+		"osu_latency_mp": {Workdir: pointToPointDir, Pairs: true}, // requires 2
+		"osu_latency_mt": {Workdir: pointToPointDir, Pairs: true}, // requires 2
+		"osu_mbw_mr":     {Workdir: pointToPointDir},
+		"osu_multi_lat":  {Workdir: pointToPointDir},
 
 		// Startup
-		"osu_hello": {Workdir: startupDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
-		"osu_init":  {Workdir: startupDir, Flags: "-np $np -map-by ppr:$tasks:node -rank-by core", HostFile: "./hostlist.txt"},
+		"osu_hello": {Workdir: startupDir},
+		"osu_init":  {Workdir: startupDir},
 	}
 )
 
@@ -148,7 +152,7 @@ func (m *OSUBenchmark) SetOptions(metric *api.Metric) {
 
 	m.lookup = map[string]bool{}
 	m.commands = []string{}
-	m.sleep = 60
+	m.sleep = 0
 	m.ResourceSpec = &metric.Resources
 	m.AttributeSpec = &metric.Attributes
 
@@ -238,7 +242,7 @@ func (m OSUBenchmark) Validate(spec *api.MetricSet) bool {
 		fmt.Printf("🟥️ OSUBenchmark not valid, requires 1+ commands.")
 		return false
 	}
-	return true
+	return m.ValidateMPI() && m.ValidateFlags(m.flags)
 }
 
 // Family returns the network family
@@ -256,9 +260,21 @@ func (m OSUBenchmark) PrepareContainers(
 
 	// The launcher has a different hostname, n for netmark
 	hosts := m.GetHostlist(spec)
+
+	// Point to point and one sided benchmarks run on a pair of hosts
+	allJob := metrics.MPIJob{Hosts: "./hostlist.txt", Hostfile: "./hostfile.mpi", NP: "$np", PPN: "$tasks"}
+	pairsJob := metrics.MPIJob{Hosts: "./hostlist-pairs.txt", Hostfile: "./hostfile-pairs.mpi", NP: "2", PPN: "1"}
+
+	// Flags from the user replace the process counts and mapping
+	if m.flags != "" {
+		for _, job := range []*metrics.MPIJob{&allJob, &pairsJob} {
+			job.NP = ""
+			job.PPN = ""
+			job.Flags = m.flags
+		}
+	}
 	prefixTemplate := `#!/bin/bash
-# Start ssh daemon
-/usr/sbin/sshd -D &
+%s
 
 # If we have zero tasks, default to workers * nproc for total tasks
 # This is only for non point to point benchmarks
@@ -273,21 +289,23 @@ fi
 echo "Number of tasks (nproc on one node) is $tasks"
 echo "Number of tasks total (across $pods nodes) is $np"
 
-# Allow network to ready (we need the hostnames / ip addresses to be there)
-sleeptime=%d
-echo "Sleeping for ${sleeptime} seconds waiting for network..."
-sleep ${sleeptime}
-
 # Write the hosts file.
 cat <<EOF > ./hostnames.txt
 %s
 EOF
+%s
 
+# Optional extra wait after all hosts are reachable
+sleeptime=%d
+if [[ ${sleeptime} -gt 0 ]]; then
+	echo "Sleeping for ${sleeptime} seconds..."
+	sleep ${sleeptime}
+fi
 %s
 
 # prepare hostlist for pair to pair
 cat hostlist.txt | head -2 > ./hostlist-pairs.txt
-
+%s%s%s
 echo "Hostlist"
 cat ./hostlist.txt
 
@@ -299,44 +317,40 @@ echo "%s"
 `
 	prefix := fmt.Sprintf(
 		prefixTemplate,
+		m.SSH(),
 		m.tasks,
-		spec.Spec.Pods,
-		m.sleep,
+		m.Pods(),
 		hosts,
-		metrics.TemplateConvertHostnames,
+		m.Rendezvous("./hostnames.txt"),
+		m.sleep,
+		m.ConvertHostnames(),
+		m.MPI().Hostfile(allJob),
+		m.MPI().Hostfile(pairsJob),
+		m.FluxBootstrap(),
 		meta,
 	)
 
 	// Do we want timed?
-	mpirun := "mpirun"
+	timed := ""
 	if m.timed {
-		mpirun = "time mpirun"
+		timed = "time "
 	}
 
 	// Prepare list of commands, e.g.,
-	// mpirun -f ./hostlist.txt -np 2 ./osu_acc_latency (mpich)
-	// mpirun --hostfile ./hostfile.txt --allow-run-as-root -N 2 -np 2 ./osu_fop_latency (openmpi)
-	// Sleep a little more to allow worker to write launcher hostname
-	commands := fmt.Sprintf("\nsleep 5\necho %s\n", metadata.CollectionStart)
+	// mpiexec -f ./hostfile-pairs.mpi -n 2 -ppn 1 ./osu_acc_latency (mpich)
+	// mpirun --allow-run-as-root --hostfile ./hostfile-pairs.mpi -np 2 --map-by ppr:1:node ./osu_fop_latency (openmpi)
+	commands := fmt.Sprintf("\necho %s\n", metadata.CollectionStart)
 	for _, executable := range m.commands {
 
 		workDir := osuBenchmarkCommands[executable].Workdir
 		command := path.Join(workDir, executable)
 
-		// Flags can vary by command, or be overridden by the user
-		flags := osuBenchmarkCommands[executable].Flags
-		if m.flags != "" {
-			flags = m.flags
-		}
-		hostfile := osuBenchmarkCommands[executable].HostFile
-
 		// Some pair to pair is for 2 nodes
-		var line string
-		if workDir == pointToPointDir || workDir == singleSidedDir {
-			line = fmt.Sprintf("%s --hostfile %s --allow-run-as-root %s %s", mpirun, hostfile, flags, command)
-		} else {
-			line = fmt.Sprintf("%s --hostfile %s --allow-run-as-root %s %s", mpirun, hostfile, flags, command)
+		job := allJob
+		if osuBenchmarkCommands[executable].Pairs {
+			job = pairsJob
 		}
+		line := fmt.Sprintf("%s%s %s", timed, m.MPI().Command(job), command)
 		commands += fmt.Sprintf("echo %s\necho \"%s\"\n%s\n", metadata.Separator, line, line)
 	}
 
@@ -371,6 +385,80 @@ echo "%s"
 	return []*specs.ContainerSpec{&launcherContainer, &workerContainer}
 }
 
+// A unit in a table header, e.g., "# Size       Avg Latency(us)"
+var osuUnit = regexp.MustCompile(`\(([^)]+)\)`)
+
+// osuMerge sums rates (e.g., MB/s) across pods, and takes the worst latency
+func osuMerge(unit string) string {
+	if strings.HasSuffix(unit, "/s") {
+		return metrics.MergeSum
+	}
+	return metrics.MergeMax
+}
+
+// osuBetter is higher for rates, and lower for latency
+func osuBetter(unit string) string {
+	if strings.HasSuffix(unit, "/s") {
+		return metrics.BetterHigher
+	}
+	return metrics.BetterLower
+}
+
+// ParseResults returns the first value for each message size of each
+// benchmark, labeled with the size and the unit of the table
+func (m OSUBenchmark) ParseResults(log string) []metrics.Sample {
+	samples := []metrics.Sample{}
+	for _, section := range metadata.Sections(log) {
+		lines := strings.Split(strings.TrimSpace(section), "\n")
+
+		// The first line is the command, ending with the benchmark
+		fields := strings.Fields(strings.Trim(lines[0], "\""))
+		if len(fields) == 0 {
+			continue
+		}
+		benchmark := path.Base(fields[len(fields)-1])
+		unit := ""
+		for _, line := range lines[1:] {
+			fields := strings.Fields(line)
+			if strings.HasPrefix(line, "# Size") {
+				unit = ""
+				match := osuUnit.FindStringSubmatch(line)
+				if match != nil {
+					unit = match[1]
+				} else if len(fields) > 2 {
+					unit = fields[2]
+				}
+				continue
+			}
+			if unit == "" || len(fields) < 2 {
+				continue
+			}
+			_, err := strconv.Atoi(fields[0])
+			if err != nil {
+				continue
+			}
+			value, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				continue
+			}
+			samples = append(samples, metrics.Sample{
+				Name:   benchmark,
+				Labels: map[string]string{"size": fields[0], "unit": unit},
+				Value:  value,
+				Unit:   unit,
+				Merge:  osuMerge(unit),
+				Better: osuBetter(unit),
+			})
+		}
+	}
+	return samples
+}
+
+// ResultChart plots each benchmark by message size
+func (m OSUBenchmark) ResultChart() metrics.Chart {
+	return metrics.Chart{Kind: metrics.ChartLine, X: "size", LogX: true}
+}
+
 func init() {
 	base := metrics.BaseMetric{
 		Identifier: OSUIdentifier,
@@ -0,0 +1,227 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package perf
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	metrics "github.com/converged-computing/metrics-operator/pkg/metrics"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	streamIdentifier = "perf-stream"
+	streamSummary    = "STREAM memory bandwidth benchmark (Copy, Scale, Add, Triad)"
+	streamContainer  = "ghcr.io/converged-computing/metric-stream:latest"
+
+	// STREAM's default array size, without a memory request
+	streamDefaultArraySize = 10000000
+)
+
+// STREAM runs on each node (one pod per node by default) and is compiled when the
+// container starts, since the array size is set at compile time.
+// https://www.cs.virginia.edu/stream/
+
+type Stream struct {
+	metrics.SingleApplication
+
+	// Custom Options
+	arraySize int32
+	threads   int32
+	bind      string
+	places    string
+	ntimes    int32
+}
+
+func (m Stream) Url() string {
+	return "https://www.cs.virginia.edu/stream/"
+}
+
+// Set custom options / attributes for the metric
+func (m *Stream) SetOptions(metric *api.Metric) {
+
+	m.Identifier = streamIdentifier
+	m.Summary = streamSummary
+	m.Container = streamContainer
+	m.ResourceSpec = &metric.Resources
+	m.AttributeSpec = &metric.Attributes
+
+	// Defaults
+	m.SoleTenancy = true
+	m.bind = "spread"
+	m.places = "cores"
+	m.ntimes = 10
+
+	st, ok := metric.Options["soleTenancy"]
+	if ok && (st.StrVal == "false" || st.StrVal == "no") {
+		m.SoleTenancy = false
+	}
+	arraySize, ok := metric.Options["arraySize"]
+	if ok {
+		m.arraySize = arraySize.IntVal
+	}
+	if m.arraySize <= 0 {
+		m.arraySize = streamArraySize(metric.Resources)
+	}
+	threads, ok := metric.Options["threads"]
+	if ok {
+		m.threads = threads.IntVal
+	}
+	ntimes, ok := metric.Options["ntimes"]
+	if ok && ntimes.IntVal > 1 {
+		m.ntimes = ntimes.IntVal
+	}
+	bind, ok := metric.Options["bind"]
+	if ok {
+		m.bind = bind.StrVal
+	}
+	places, ok := metric.Options["places"]
+	if ok {
+		m.places = places.StrVal
+	}
+}
+
+// streamArraySize sizes the three arrays (of doubles) to use 60% of the memory
+// request (or limit) of the container, or is the default without either
+func streamArraySize(resources api.ContainerResources) int32 {
+	memory, ok := resources.Requests["memory"]
+	if !ok {
+		memory, ok = resources.Limits["memory"]
+	}
+	if !ok {
+		return streamDefaultArraySize
+	}
+	quantity, err := resource.ParseQuantity(memory.String())
+	if err != nil || quantity.Value() <= 0 {
+		fmt.Printf("Cannot parse memory %s for the STREAM array size, using the default\n", memory.String())
+		return streamDefaultArraySize
+	}
+	elements := quantity.Value() * 6 / 10 / (3 * 8)
+	return int32(math.Min(float64(elements), math.MaxInt32))
+}
+
+// Exported options and list options
+func (m Stream) Options() map[string]intstr.IntOrString {
+	values := map[string]intstr.IntOrString{
+		"arraySize":   intstr.FromInt(int(m.arraySize)),
+		"threads":     intstr.FromInt(int(m.threads)),
+		"ntimes":      intstr.FromInt(int(m.ntimes)),
+		"bind":        intstr.FromString(m.bind),
+		"places":      intstr.FromString(m.places),
+		"soleTenancy": intstr.FromString("false"),
+	}
+	if m.SoleTenancy {
+		values["soleTenancy"] = intstr.FromString("true")
+	}
+	return values
+}
+
+func (m Stream) PrepareContainers(
+	spec *api.MetricSet,
+	metric *metrics.Metric,
+) []*specs.ContainerSpec {
+
+	// Metadata to add to beginning of run
+	meta := metrics.Metadata(spec, metric)
+
+	// Thread binding is left to the OpenMP runtime if it is unset
+	binding := ""
+	if m.bind != "" {
+		binding += fmt.Sprintf("export OMP_PROC_BIND=%s\n", m.bind)
+	}
+	if m.places != "" {
+		binding += fmt.Sprintf("export OMP_PLACES=%s\n", m.places)
+	}
+
+	preBlock := `#!/bin/bash
+echo "%s"
+cd /opt/stream
+
+# Zero threads is one for each processor
+threads=%d
+if [[ $threads -eq 0 ]]; then
+	threads=$(nproc)
+fi
+export OMP_NUM_THREADS=$threads
+%s
+# Arrays larger than 2GB need the medium code model on x86_64
+flags=""
+if [[ "$(uname -m)" == "x86_64" ]]; then
+	flags="-mcmodel=medium"
+fi
+echo "STREAM COMPILE START"
+gcc -O3 -fopenmp ${flags} -DSTREAM_ARRAY_SIZE=%d -DNTIMES=%d stream.c -o stream-operator
+echo "STREAM COMPILE END"
+echo "OMP_NUM_THREADS=${OMP_NUM_THREADS} OMP_PROC_BIND=${OMP_PROC_BIND} OMP_PLACES=${OMP_PLACES}"
+echo "%s"
+echo "%s"
+`
+	preBlock = fmt.Sprintf(
+		preBlock,
+		meta,
+		m.threads,
+		binding,
+		m.arraySize,
+		m.ntimes,
+		metadata.CollectionStart,
+		metadata.Separator,
+	)
+
+	interactive := metadata.Interactive(spec.Spec.Logging.Interactive)
+	postBlock := fmt.Sprintf("\necho \"%s\"\n%s\n", metadata.CollectionEnd, interactive)
+	return m.ApplicationContainerSpec(preBlock, "./stream-operator", postBlock)
+}
+
+// A line of the STREAM results, e.g., "Triad:  12722.8  0.019023  0.018864  0.019232"
+var streamResult = regexp.MustCompile(`^(Copy|Scale|Add|Triad):\s+([0-9.eE+-]+)\s`)
+
+// ParseResults returns the best rate of each function. Each pod is a node,
+// so rates are summed for the cluster.
+func (m Stream) ParseResults(log string) []metrics.Sample {
+	samples := []metrics.Sample{}
+	for _, section := range metadata.Sections(log) {
+		for _, line := range strings.Split(section, "\n") {
+			match := streamResult.FindStringSubmatch(strings.TrimSpace(line))
+			if match == nil {
+				continue
+			}
+			value, err := strconv.ParseFloat(match[2], 64)
+			if err != nil {
+				continue
+			}
+			samples = append(samples, metrics.Sample{
+				Name:   "best_rate_mb",
+				Labels: map[string]string{"function": strings.ToLower(match[1])},
+				Value:  value,
+				Unit:   "MB/s",
+				Merge:  metrics.MergeSum,
+				Better: metrics.BetterHigher,
+			})
+		}
+	}
+	return samples
+}
+
+func init() {
+	base := metrics.BaseMetric{
+		Identifier: streamIdentifier,
+		Summary:    streamSummary,
+		Container:  streamContainer,
+	}
+	app := metrics.SingleApplication{BaseMetric: base}
+	stream := Stream{SingleApplication: app}
+	metrics.Register(&stream)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package perf
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+)
+
+func TestStreamArraySize(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources api.ContainerResources
+		expected  int32
+	}{
+		{name: "none", expected: streamDefaultArraySize},
+		{
+			name:      "request",
+			resources: api.ContainerResources{Requests: api.ContainerResource{"memory": intstr.FromString("1Gi")}},
+			expected:  26843545,
+		},
+		{
+			name:      "limit",
+			resources: api.ContainerResources{Limits: api.ContainerResource{"memory": intstr.FromString("2Gi")}},
+			expected:  53687091,
+		},
+		{
+			name: "request before limit",
+			resources: api.ContainerResources{
+				Requests: api.ContainerResource{"memory": intstr.FromString("1Gi")},
+				Limits:   api.ContainerResource{"memory": intstr.FromString("2Gi")},
+			},
+			expected: 26843545,
+		},
+		{
+			name:      "clamped",
+			resources: api.ContainerResources{Requests: api.ContainerResource{"memory": intstr.FromString("1Ti")}},
+			expected:  math.MaxInt32,
+		},
+		{
+			name:      "invalid",
+			resources: api.ContainerResources{Requests: api.ContainerResource{"memory": intstr.FromString("lots")}},
+			expected:  streamDefaultArraySize,
+		},
+	}
+	for _, test := range tests {
+		size := streamArraySize(test.resources)
+		if size != test.expected {
+			t.Errorf("%s: expected an array size of %d, found %d", test.name, test.expected, size)
+		}
+	}
+}
+
+// Output of STREAM 5.10, and a build that prints rates in e-notation
+var streamOutput = `-------------------------------------------------------------
+STREAM version $Revision: 5.10 $
+-------------------------------------------------------------
+This system uses 8 bytes per array element.
+-------------------------------------------------------------
+Array size = 10000000 (elements), Offset = 0 (elements)
+Memory per array = 76.3 MiB (= 0.1 GiB).
+Total memory required = 228.9 MiB (= 0.2 GiB).
+Each kernel will be executed 10 times.
+ The *best* time for each kernel (excluding the first iteration)
+ will be used to compute the reported bandwidth.
+-------------------------------------------------------------
+Number of Threads requested = 4
+Number of Threads counted = 4
+-------------------------------------------------------------
+Your clock granularity/precision appears to be 1 microseconds.
+Each test below will take on the order of 4533 microseconds.
+   (= 4533 clock ticks)
+Increase the size of the arrays if this shows that
+you are not getting at least 20 clock ticks per test.
+-------------------------------------------------------------
+WARNING -- The above is only a rough guideline.
+For best results, please be sure you know the
+precision of your system timer.
+-------------------------------------------------------------
+Function    Best Rate MB/s  Avg time     Min time     Max time
+Copy:           29612.4     0.005570     0.005403     0.005881
+Scale:          20317.1     0.008013     0.007875     0.008232
+Add:            22787.3     0.010707     0.010532     0.010954
+Triad:       1.2722e+05     0.019023     0.018864     0.019232
+-------------------------------------------------------------
+Solution Validates: avg error less than 1.000000e-13 on all three arrays
+-------------------------------------------------------------`
+
+func TestStreamParseResults(t *testing.T) {
+	log := strings.Join([]string{
+		"METADATA START {}",
+		metadata.CollectionStart,
+		metadata.Separator,
+		streamOutput,
+		metadata.CollectionEnd,
+	}, "\n")
+	expected := map[string]float64{"copy": 29612.4, "scale": 20317.1, "add": 22787.3, "triad": 127220}
+	samples := Stream{}.ParseResults(log)
+	if len(samples) != len(expected) {
+		t.Fatalf("expected %d samples, found %v", len(expected), samples)
+	}
+	for _, sample := range samples {
+		function := sample.Labels["function"]
+		if sample.Name != "best_rate_mb" || sample.Value != expected[function] {
+			t.Errorf("unexpected sample %s %v=%v", sample.Name, sample.Labels, sample.Value)
+		}
+	}
+
+	// Output before the collection starts isn't parsed
+	samples = Stream{}.ParseResults(streamOutput)
+	if len(samples) != 0 {
+		t.Errorf("expected no samples outside of the collection, found %v", samples)
+	}
+}
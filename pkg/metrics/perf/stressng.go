@@ -0,0 +1,184 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package perf
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/addons"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	metrics "github.com/converged-computing/metrics-operator/pkg/metrics"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	stressNgIdentifier = "perf-stress-ng"
+	stressNgSummary    = "stress-ng synthetic CPU, memory, and cache stress (bogo-ops)"
+)
+
+// stress-ng writes its metrics (--yaml) to a file that is printed between these lines
+const (
+	stressNgYAMLStart = "STRESS-NG YAML START"
+	stressNgYAMLEnd   = "STRESS-NG YAML END"
+)
+
+// stress-ng runs stressors (e.g., cpu, vm, cache) with a number of workers for a
+// time, and reports the bogo (bogus) operations of each. To run it next to another
+// metric instead (e.g., monitored by perf-sysstat) use the stress-ng addon.
+// https://github.com/ColinIanKing/stress-ng
+
+type StressNg struct {
+	metrics.SingleApplication
+
+	// Custom Options
+	stressors []string
+	workers   int32
+	timeout   int32
+	args      string
+}
+
+func (m StressNg) Url() string {
+	return "https://github.com/ColinIanKing/stress-ng"
+}
+
+// Set custom options / attributes for the metric
+func (m *StressNg) SetOptions(metric *api.Metric) {
+
+	m.Identifier = stressNgIdentifier
+	m.Summary = stressNgSummary
+	m.Container = addons.StressNgContainer
+	m.ResourceSpec = &metric.Resources
+	m.AttributeSpec = &metric.Attributes
+
+	// Defaults are shared with the addon
+	m.stressors = addons.StressNgStressors
+	m.timeout = addons.StressNgTimeout
+
+	stressors, ok := metric.Options["stressors"]
+	if ok {
+		m.stressors = addons.StressNgStressorList(stressors.StrVal)
+	}
+	workers, ok := metric.Options["workers"]
+	if ok {
+		m.workers = workers.IntVal
+	}
+	timeout, ok := metric.Options["timeout"]
+	if ok && timeout.IntVal > 0 {
+		m.timeout = timeout.IntVal
+	}
+	args, ok := metric.Options["args"]
+	if ok {
+		m.args = args.StrVal
+	}
+}
+
+// Validate there is at least one stressor
+func (m StressNg) Validate(spec *api.MetricSet) bool {
+	if len(m.stressors) == 0 {
+		fmt.Printf("🟥️ perf-stress-ng requires one or more stressors.\n")
+		return false
+	}
+	return true
+}
+
+// Exported options and list options
+func (m StressNg) Options() map[string]intstr.IntOrString {
+	return map[string]intstr.IntOrString{
+		"stressors": intstr.FromString(strings.Join(m.stressors, ",")),
+		"workers":   intstr.FromInt(int(m.workers)),
+		"timeout":   intstr.FromInt(int(m.timeout)),
+		"args":      intstr.FromString(m.args),
+	}
+}
+
+func (m StressNg) PrepareContainers(
+	spec *api.MetricSet,
+	metric *metrics.Metric,
+) []*specs.ContainerSpec {
+
+	// Metadata to add to beginning of run
+	meta := metrics.Metadata(spec, metric)
+	command := addons.StressNgCommand(m.stressors, m.workers, m.timeout, m.args) + " --yaml ./stress-ng.yaml"
+
+	preBlock := `#!/bin/bash
+echo "%s"
+echo "%s"
+echo "%s"
+`
+	preBlock = fmt.Sprintf(preBlock, meta, metadata.CollectionStart, metadata.Separator)
+
+	postBlock := `
+echo "%s"
+cat ./stress-ng.yaml
+echo "%s"
+echo "%s"
+%s
+`
+	interactive := metadata.Interactive(spec.Spec.Logging.Interactive)
+	postBlock = fmt.Sprintf(postBlock, stressNgYAMLStart, stressNgYAMLEnd, metadata.CollectionEnd, interactive)
+	return m.ApplicationContainerSpec(preBlock, command, postBlock)
+}
+
+// stressNgOutput is the part of the stress-ng yaml that we parse
+type stressNgOutput struct {
+	Metrics []struct {
+		Stressor         string  `json:"stressor"`
+		BogoOps          float64 `json:"bogo-ops"`
+		BogoOpsPerSecond float64 `json:"bogo-ops-per-second-real-time"`
+		BogoOpsCPUTime   float64 `json:"bogo-ops-per-second-usr-sys-time"`
+	} `json:"metrics"`
+}
+
+// ParseResults returns the bogo-ops and rates of each stressor. Each pod runs
+// the stressors, so they are summed across pods. A document without the end
+// marker is truncated (e.g., the pod was stopped), so it isn't parsed.
+func (m StressNg) ParseResults(log string) []metrics.Sample {
+	samples := []metrics.Sample{}
+	start := strings.Index(log, stressNgYAMLStart)
+	if start < 0 {
+		return samples
+	}
+	document := log[start+len(stressNgYAMLStart):]
+	end := strings.Index(document, stressNgYAMLEnd)
+	if end < 0 {
+		return samples
+	}
+	document = document[:end]
+	output := stressNgOutput{}
+	err := yaml.Unmarshal([]byte(document), &output)
+	if err != nil {
+		return samples
+	}
+	for _, result := range output.Metrics {
+		if result.Stressor == "" {
+			continue
+		}
+		labels := map[string]string{"stressor": result.Stressor}
+		samples = append(samples,
+			metrics.Sample{Name: "bogo_ops", Labels: labels, Value: result.BogoOps, Merge: metrics.MergeSum, Better: metrics.BetterHigher},
+			metrics.Sample{Name: "bogo_ops_per_second", Labels: labels, Value: result.BogoOpsPerSecond, Unit: "ops/s", Merge: metrics.MergeSum, Better: metrics.BetterHigher},
+			metrics.Sample{Name: "bogo_ops_per_cpu_second", Labels: labels, Value: result.BogoOpsCPUTime, Unit: "ops/s", Merge: metrics.MergeSum, Better: metrics.BetterHigher},
+		)
+	}
+	return samples
+}
+
+func init() {
+	base := metrics.BaseMetric{
+		Identifier: stressNgIdentifier,
+		Summary:    stressNgSummary,
+		Container:  addons.StressNgContainer,
+	}
+	app := metrics.SingleApplication{BaseMetric: base}
+	stress := StressNg{SingleApplication: app}
+	metrics.Register(&stress)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package perf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+)
+
+// The --yaml output of stress-ng 0.13 for the cpu and vm stressors
+var stressNgYAML = `---
+system-info:
+      stress-ng-version: 0.13.12
+      run-by: root
+      date-yyyy-mm-dd: 2023:09:20
+      time-hh-mm-ss: 17:41:02
+      epoch-secs: 1695231662
+      hostname: ms-m-0-0
+      sysname: Linux
+      nodename: ms-m-0-0
+      release: 5.15.0-1041-gke
+      version: "#46-Ubuntu SMP Fri Jul 21 20:20:02 UTC 2023"
+      machine: x86_64
+      uptime: 1843
+      totalram: 16785338368
+      freeram: 12187258880
+      sharedram: 1138688
+      bufferram: 179892224
+      totalswap: 0
+      freeswap: 0
+      pagesize: 4096
+      cpus: 4
+      cpus-online: 4
+      ticks-per-second: 100
+
+metrics:
+    - stressor: cpu
+      bogo-ops: 17404
+      bogo-ops-per-second-usr-sys-time: 1443.231840
+      bogo-ops-per-second-real-time: 1740.109436
+      wall-clock-time: 10.001670
+      user-time: 12.060000
+      system-time: 0.000000
+    - stressor: vm
+      bogo-ops: 1.23456e+06
+      bogo-ops-per-second-usr-sys-time: 62305.220000
+      bogo-ops-per-second-real-time: 123435.120000
+      wall-clock-time: 10.001660
+      user-time: 7.120000
+      system-time: 12.690000
+times:
+      run-time: 10.01
+      available-cpu-time: 40.04
+      user-time: 19.18
+      system-time: 12.69
+      total-time: 31.87
+      user-time-percent: 47.90
+      system-time-percent: 31.69
+      total-time-percent: 79.60
+...
+`
+
+func TestStressNgParseResults(t *testing.T) {
+	log := func(document string) string {
+		return strings.Join([]string{
+			"METADATA START {}",
+			metadata.CollectionStart,
+			metadata.Separator,
+			"stress-ng: info:  [10] dispatching hogs: 4 cpu, 4 vm",
+			"stress-ng: info:  [10] successful run completed in 10.01s",
+			document,
+			metadata.CollectionEnd,
+		}, "\n")
+	}
+	tests := []struct {
+		name     string
+		log      string
+		expected map[string]float64
+	}{
+		{
+			name: "complete",
+			log:  log(stressNgYAMLStart + "\n" + stressNgYAML + stressNgYAMLEnd),
+			expected: map[string]float64{
+				"cpu/bogo_ops":                1.7404e4,
+				"cpu/bogo_ops_per_second":     1740.109436,
+				"cpu/bogo_ops_per_cpu_second": 1443.231840,
+				"vm/bogo_ops":                 1.23456e6,
+				"vm/bogo_ops_per_second":      123435.12,
+				"vm/bogo_ops_per_cpu_second":  62305.22,
+			},
+		},
+		{
+			name:     "truncated",
+			log:      log(stressNgYAMLStart + "\n" + stressNgYAML[:strings.Index(stressNgYAML, "    - stressor: vm")+30]),
+			expected: map[string]float64{},
+		},
+		{
+			name:     "truncated log",
+			log:      stressNgYAMLStart + "\n" + stressNgYAML[:strings.Index(stressNgYAML, "times:")],
+			expected: map[string]float64{},
+		},
+		{
+			name:     "missing",
+			log:      log(stressNgYAMLStart + "\ncat: ./stress-ng.yaml: No such file or directory\n" + stressNgYAMLEnd),
+			expected: map[string]float64{},
+		},
+		{name: "no markers", log: log(""), expected: map[string]float64{}},
+	}
+	for _, test := range tests {
+		samples := StressNg{}.ParseResults(test.log)
+		if len(samples) != len(test.expected) {
+			t.Errorf("%s: expected %d samples, found %v", test.name, len(test.expected), samples)
+			continue
+		}
+		for _, sample := range samples {
+			key := sample.Labels["stressor"] + "/" + sample.Name
+			expected, ok := test.expected[key]
+			if !ok || sample.Value != expected {
+				t.Errorf("%s: unexpected sample %s=%v", test.name, key, sample.Value)
+			}
+		}
+	}
+}
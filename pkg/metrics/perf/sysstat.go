@@ -8,8 +8,10 @@ SPDX-License-Identifier: MIT
 package perf
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
 	"github.com/converged-computing/metrics-operator/pkg/metadata"
@@ -132,7 +134,7 @@ func (m PidStat) prepareIndexedCommand(spec *api.MetricSet) string {
 
 			// We currently have support for all
 			if key == "all" {
-				for i := 0; i < int(spec.Spec.Pods); i++ {
+				for i := 0; i < int(m.Pods()); i++ {
 					commands[strconv.Itoa(i)] = value.StrVal
 				}
 			}
@@ -266,6 +268,97 @@ done
 	return m.ApplicationContainerSpec(preBlock, command, postBlock)
 }
 
+// Each timepoint has these sections, a title followed by the JSON of jc --pidstat
+var pidstatSections = []string{
+	"CPU STATISTICS TASK",
+	"CPU STATISTICS CHILD",
+	"IO STATISTICS",
+	"POLICY",
+	"PAGEFAULTS TASK",
+	"PAGEFAULTS CHILD",
+	"STACK UTILIZATION",
+	"THREADS TASK",
+	"THREADS CHILD",
+	"KERNEL TABLES",
+	"TASK SWITCHING",
+}
+
+// Fields of pidstat that identify a task, and are not samples
+var pidstatIdentifiers = map[string]bool{
+	"time": true, "uid": true, "pid": true, "tgid": true, "tid": true, "cpu": true, "prio": true,
+}
+
+// ParseResults returns each statistic of each command at each timepoint,
+// named by the section and field, e.g., cpu_statistics_task_percent_cpu
+func (m PidStat) ParseResults(log string) []metrics.Sample {
+	samples := []metrics.Sample{}
+	for _, section := range metadata.SplitLog(log).Sections {
+		lines := strings.Split(section.Output, "\n")
+		for i := 0; i+1 < len(lines); i++ {
+			title := strings.TrimSpace(lines[i])
+			if !isPidstatSection(title) {
+				continue
+			}
+			tasks := []map[string]interface{}{}
+			err := json.Unmarshal([]byte(lines[i+1]), &tasks)
+			if err != nil {
+				continue
+			}
+			prefix := strings.ToLower(strings.ReplaceAll(title, " ", "_"))
+			for _, task := range tasks {
+				command, _ := task["command"].(string)
+				for field, raw := range task {
+					value, ok := raw.(float64)
+					if !ok || pidstatIdentifiers[field] {
+						continue
+					}
+					samples = append(samples, metrics.Sample{
+						Name: prefix + "_" + field,
+						Labels: map[string]string{
+							"command":              command,
+							metrics.TimepointLabel: strconv.Itoa(section.Iteration),
+						},
+						Value: value,
+						Unit:  pidstatUnit(field),
+					})
+				}
+			}
+		}
+	}
+	return samples
+}
+
+func isPidstatSection(title string) bool {
+	for _, section := range pidstatSections {
+		if title == section {
+			return true
+		}
+	}
+	return false
+}
+
+// pidstatUnit is the unit of a field of jc, from its name
+func pidstatUnit(field string) string {
+	switch {
+	case strings.HasPrefix(field, "percent_"):
+		return "%"
+	case strings.HasPrefix(field, "kb_") && strings.HasSuffix(field, "_s"):
+		return "kB/s"
+	case strings.HasSuffix(field, "_s"):
+		return "/s"
+	case strings.HasSuffix(field, "_ms"):
+		return "ms"
+	case field == "vsz" || field == "rss" || field == "stksize" || field == "stkref":
+		return "kB"
+	}
+	return ""
+}
+
+// ResultChart plots the statistics of each command over time
+func (m PidStat) ResultChart() metrics.Chart {
+	return metrics.Chart{Kind: metrics.ChartTimeSeries, X: metrics.TimepointLabel}
+}
+
 func init() {
 	base := metrics.BaseMetric{
 		Identifier: pidstatIdentifier,
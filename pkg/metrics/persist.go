@@ -0,0 +1,227 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+	"github.com/converged-computing/metrics-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+)
+
+const (
+	// The results claim is mounted here in metric containers
+	ResultsMountPath = "/metrics_operator_results"
+
+	// Each container directory has the output, files, and this manifest
+	ResultsManifestFile = "manifest.json"
+	ResultsOutputFile   = "output.txt"
+	ResultsFilesDir     = "files"
+
+	resultsVolumeName = "metrics-operator-results"
+	resultsOutputFile = "/tmp/metrics-operator-output.txt"
+	resultsSize       = "1Gi"
+)
+
+// ResultsManifest is written by each metric container, next to its output
+type ResultsManifest struct {
+	Version       int    `json:"version"`
+	MetricSet     string `json:"metricset"`
+	Namespace     string `json:"namespace"`
+	RunID         string `json:"runId"`
+	Metric        string `json:"metric"`
+	Options       string `json:"options"`
+	ReplicatedJob string `json:"replicatedJob"`
+	Container     string `json:"container"`
+	Image         string `json:"image"`
+	PodIndex      int    `json:"podIndex"`
+	Pod           string `json:"pod"`
+	StartTime     string `json:"startTime"`
+	EndTime       string `json:"endTime"`
+	ExitCode      int    `json:"exitCode"`
+
+	// Files are relative to the directory of the manifest
+	Files []string `json:"files"`
+}
+
+// ResultsClaimName is the existing claim for results, or the one we create
+func ResultsClaimName(spec *api.MetricSet) string {
+	if spec.Spec.Results != nil && spec.Spec.Results.ClaimName != "" {
+		return spec.Spec.Results.ClaimName
+	}
+	return spec.Name + "-results"
+}
+
+// ResultsRunID identifies one run of a MetricSet under the results claim
+func ResultsRunID(spec *api.MetricSet) string {
+	if spec.UID == "" {
+		return "unknown"
+	}
+	return string(spec.UID)
+}
+
+// GetResultsClaim returns the claim to create for results, or nil if the
+// MetricSet doesn't persist results or uses an existing claim
+func GetResultsClaim(spec *api.MetricSet) (*corev1.PersistentVolumeClaim, error) {
+	results := spec.Spec.Results
+	if results == nil || results.ClaimName != "" {
+		return nil, nil
+	}
+	size := results.Size
+	if size == "" {
+		size = resultsSize
+	}
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return nil, err
+	}
+	modes := []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	if len(results.AccessModes) > 0 {
+		modes = []corev1.PersistentVolumeAccessMode{}
+		for _, mode := range results.AccessModes {
+			modes = append(modes, corev1.PersistentVolumeAccessMode(mode))
+		}
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ResultsClaimName(spec),
+			Namespace: spec.Namespace,
+			Labels:    map[string]string{"metricset-name": spec.Name},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: modes,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: quantity},
+			},
+		},
+	}
+	if results.StorageClassName != "" {
+		claim.Spec.StorageClassName = &results.StorageClassName
+	}
+	return claim, nil
+}
+
+// setResults mounts the results claim in the metric containers, and saves the output
+// (and files) of each to <metricset>/<run-id>/<replicated-job>/<pod-index>/<container>/
+// after the post block, with a manifest. This runs after templates, so they are included.
+func setResults(
+	spec *api.MetricSet,
+	m Metric,
+	jobs []*jobset.ReplicatedJob,
+	containerSpecs []*specs.ContainerSpec,
+) {
+	if spec.Spec.Results == nil {
+		return
+	}
+	mountVolume(jobs, containerSpecs, corev1.Volume{
+		Name: resultsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: ResultsClaimName(spec),
+			},
+		},
+	}, ResultsMountPath, false)
+
+	for _, cs := range containerSpecs {
+
+		// A container with a command does not use the entrypoint
+		if len(cs.Command) > 0 {
+			continue
+		}
+
+		// Output addons already save the output to a file we can share
+		if cs.EntrypointScript.OutputFile == "" {
+			cs.EntrypointScript.OutputFile = resultsOutputFile
+		}
+		cs.EntrypointScript.Pre = "metrics_operator_start_time=$(date -u +%Y-%m-%dT%H:%M:%SZ)\n" + cs.EntrypointScript.Pre
+
+		// If the post command ends with sleep infinity, save before it
+		isInteractive, post := metadata.SplitInteractive(cs.EntrypointScript.Post)
+		cs.EntrypointScript.Post = post + "\n" + resultsScript(spec, m, cs)
+		if isInteractive {
+			cs.EntrypointScript.Post += "\nsleep infinity\n"
+		}
+	}
+}
+
+// resultsScript saves the output, files, and manifest of one container
+func resultsScript(spec *api.MetricSet, m Metric, cs *specs.ContainerSpec) string {
+	jobName := cs.JobName
+	if jobName == "" {
+		jobName = ReplicatedJobName
+	}
+	directory := strings.Join([]string{ResultsMountPath, spec.Name, ResultsRunID(spec), jobName}, "/")
+
+	// Fields known now are in the format of the manifest, the rest are arguments
+	format := "{\n  \"version\": 1,\n"
+	for _, field := range [][]string{
+		{"metricset", spec.Name},
+		{"namespace", spec.Namespace},
+		{"runId", ResultsRunID(spec)},
+		{"metric", m.Name()},
+		{"options", metadata.FormatOptions(m.Options(), m.ListOptions())},
+		{"replicatedJob", jobName},
+		{"container", cs.Name},
+		{"image", cs.Image},
+	} {
+		// printf also reads escapes in the format, so backslashes are doubled
+		value, _ := json.Marshal(field[1])
+		escaped := strings.NewReplacer("%", "%%", `\`, `\\`).Replace(string(value))
+		format += fmt.Sprintf("  %q: %s,\n", field[0], escaped)
+	}
+	format += `  "podIndex": %s,
+  "pod": "%s",
+  "startTime": "%s",
+  "endTime": "%s",
+  "exitCode": %s,
+  "files": [%s]
+}
+`
+	template := `
+# Save the output and files to the results volume
+metrics_operator_end_time=$(date -u +%%Y-%%m-%%dT%%H:%%M:%%SZ)
+metrics_operator_results="%[1]s/${JOB_COMPLETION_INDEX:-0}/%[2]s"
+mkdir -p "${metrics_operator_results}"
+
+metrics_operator_flush
+cp %[3]s "${metrics_operator_results}/%[4]s"
+metrics_operator_files="\"%[4]s\""
+shopt -s nullglob globstar
+for file in %[5]s; do
+	if [[ -f "${file}" ]]; then
+		name="%[6]s/${file#/}"
+		mkdir -p "$(dirname "${metrics_operator_results}/${name}")"
+		cp "${file}" "${metrics_operator_results}/${name}"
+		metrics_operator_files="${metrics_operator_files}, \"${name}\""
+	fi
+done
+shopt -u nullglob globstar
+printf %[7]s "${JOB_COMPLETION_INDEX:-0}" "${HOSTNAME%%%%.*}" "${metrics_operator_start_time}" \
+	"${metrics_operator_end_time}" "${metrics_operator_exit_code:-0}" "${metrics_operator_files}" > "${metrics_operator_results}/%[8]s"
+echo "Saved results to ${metrics_operator_results}"
+`
+	return fmt.Sprintf(
+		template,
+		directory,
+		cs.Name,
+		cs.EntrypointScript.OutputFile,
+		ResultsOutputFile,
+		strings.Join(spec.Spec.Results.Files, " "),
+		ResultsFilesDir,
+		utils.ShellQuote(format),
+		ResultsManifestFile,
+	)
+}
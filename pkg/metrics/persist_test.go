@@ -0,0 +1,115 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics_test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+func TestResults(t *testing.T) {
+	tmp := t.TempDir()
+	traces := filepath.Join(tmp, "traces")
+	err := os.MkdirAll(traces, 0755)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(traces, "rank-0.txt"), []byte("trace"), 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &api.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default", UID: "1234"},
+		Spec: api.MetricSetSpec{
+			Pods:        2,
+			ServiceName: "ms",
+			Metrics: []api.Metric{{
+				Name:    "network-osu-benchmark",
+				Options: map[string]intstr.IntOrString{"tasks": intstr.FromInt(2)},
+			}},
+			Results: &api.Results{Files: []string{traces + "/*.txt"}},
+		},
+	}
+	spec.Validate()
+	claim, err := metrics.GetResultsClaim(spec)
+	if err != nil || claim.Name != "ms-results" || claim.Spec.Resources.Requests.Storage().String() != "1Gi" {
+		t.Fatalf("unexpected claim %v: %v", claim, err)
+	}
+	m, err := metrics.GetMetric(&spec.Spec.Metrics[0], spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := metrics.MetricSet{}
+	set.Add(&m)
+	js, containerSpecs, err := metrics.GetJobSet(spec, &set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The claim is mounted in the metric containers of every pod
+	for _, rj := range js.Spec.ReplicatedJobs {
+		podSpec := rj.Template.Spec.Template.Spec
+		found := false
+		for _, volume := range podSpec.Volumes {
+			found = found || (volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == "ms-results")
+		}
+		for _, mount := range podSpec.Containers[0].VolumeMounts {
+			found = found && (mount.MountPath != metrics.ResultsMountPath || !mount.ReadOnly)
+		}
+		if !found {
+			t.Errorf("expected the results claim in replicated job %s", rj.Name)
+		}
+	}
+
+	// Run the launcher, with a command that fails, and the results are still saved
+	cs := containerSpecs[0]
+	cs.EntrypointScript.Pre = strings.SplitN(cs.EntrypointScript.Pre, "\n", 2)[0]
+	cs.EntrypointScript.Command = "echo hello; false"
+	script := strings.ReplaceAll(cs.EntrypointScript.WriteScript(), metrics.ResultsMountPath, tmp)
+	script = strings.ReplaceAll(script, "/tmp/metrics-operator-output.txt", filepath.Join(tmp, "output.txt"))
+	command := exec.Command("bash", "-c", script)
+	command.Env = append(os.Environ(), "HOSTNAME=ms-l-0-0.ms.default.svc.cluster.local", "JOB_COMPLETION_INDEX=0")
+	out, err := command.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+
+	directory := filepath.Join(tmp, "ms", "1234", "l", "0", "launcher")
+	output, err := os.ReadFile(filepath.Join(directory, metrics.ResultsOutputFile))
+	if err != nil || !strings.Contains(string(output), "hello") {
+		t.Errorf("expected the output to be saved: %s\n%s", err, out)
+	}
+	trace, err := os.ReadFile(filepath.Join(directory, metrics.ResultsFilesDir, traces, "rank-0.txt"))
+	if err != nil || string(trace) != "trace" {
+		t.Errorf("expected the trace to be saved: %s", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(directory, metrics.ResultsManifestFile))
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	manifest := metrics.ResultsManifest{}
+	err = json.Unmarshal(raw, &manifest)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, raw)
+	}
+	if manifest.MetricSet != "ms" || manifest.RunID != "1234" || manifest.Metric != "network-osu-benchmark" ||
+		!strings.Contains(manifest.Options, ",tasks=2,") || manifest.ReplicatedJob != "l" || manifest.Pod != "ms-l-0-0" ||
+		manifest.ExitCode != 1 || manifest.StartTime == "" || len(manifest.Files) != 2 {
+		t.Errorf("unexpected manifest:\n%s", raw)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+)
+
+// Pod Security Standard levels, as labeled on a namespace
+// https://kubernetes.io/docs/concepts/security/pod-security-standards/
+const (
+	PodSecurityPrivileged = "privileged"
+	PodSecurityBaseline   = "baseline"
+	PodSecurityRestricted = "restricted"
+)
+
+var (
+	// Capabilities allowed to be added under the baseline level
+	baselineCapabilities = map[corev1.Capability]bool{
+		"AUDIT_WRITE":      true,
+		"CHOWN":            true,
+		"DAC_OVERRIDE":     true,
+		"FOWNER":           true,
+		"FSETID":           true,
+		"KILL":             true,
+		"MKNOD":            true,
+		"NET_BIND_SERVICE": true,
+		"SETFCAP":          true,
+		"SETGID":           true,
+		"SETPCAP":          true,
+		"SETUID":           true,
+		"SYS_CHROOT":       true,
+	}
+)
+
+// CheckPodSecurity returns a list of reasons the pods of a JobSet would not
+// be admitted under a pod security level. An empty list means compatible.
+func CheckPodSecurity(js *jobset.JobSet, level string) []string {
+	violations := []string{}
+	if level != PodSecurityBaseline && level != PodSecurityRestricted {
+		return violations
+	}
+	for _, rj := range js.Spec.ReplicatedJobs {
+		pod := rj.Template.Spec.Template
+		for _, reason := range checkPodSpec(&pod, level) {
+			violations = append(violations, fmt.Sprintf("replicated job %s: %s", rj.Name, reason))
+		}
+	}
+	return violations
+}
+
+// checkPodSpec checks pod and container level settings
+func checkPodSpec(pod *corev1.PodTemplateSpec, level string) []string {
+	reasons := []string{}
+	spec := pod.Spec
+
+	if spec.HostNetwork || spec.HostPID || spec.HostIPC {
+		reasons = append(reasons, "host namespaces are not allowed")
+	}
+	for _, volume := range spec.Volumes {
+		if volume.HostPath != nil {
+			reasons = append(reasons, fmt.Sprintf("hostPath volume %s is not allowed", volume.Name))
+		} else if level == PodSecurityRestricted && !restrictedVolume(&volume) {
+			reasons = append(reasons, fmt.Sprintf("volume %s has a type that is not allowed", volume.Name))
+		}
+	}
+
+	containers := append([]corev1.Container{}, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, container := range containers {
+		for _, reason := range checkContainer(pod, &container, level) {
+			reasons = append(reasons, fmt.Sprintf("container %s %s", container.Name, reason))
+		}
+	}
+	return reasons
+}
+
+// checkContainer checks a single container against a level
+func checkContainer(
+	pod *corev1.PodTemplateSpec,
+	container *corev1.Container,
+	level string,
+) []string {
+
+	reasons := []string{}
+	sc := container.SecurityContext
+	if sc == nil {
+		sc = &corev1.SecurityContext{}
+	}
+
+	// Baseline applies to both baseline and restricted
+	if sc.Privileged != nil && *sc.Privileged {
+		reasons = append(reasons, "is privileged")
+	}
+	added := []corev1.Capability{}
+	if sc.Capabilities != nil {
+		added = sc.Capabilities.Add
+	}
+	for _, capability := range added {
+		if level == PodSecurityRestricted && capability != "NET_BIND_SERVICE" {
+			reasons = append(reasons, fmt.Sprintf("adds capability %s", capability))
+		} else if !baselineCapabilities[capability] {
+			reasons = append(reasons, fmt.Sprintf("adds capability %s", capability))
+		}
+	}
+	if sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		reasons = append(reasons, "uses an Unconfined seccomp profile")
+	}
+	annotation := corev1.AppArmorBetaContainerAnnotationKeyPrefix + container.Name
+	if pod.Annotations[annotation] == corev1.AppArmorBetaProfileNameUnconfined {
+		reasons = append(reasons, "uses an unconfined AppArmor profile")
+	}
+	if level != PodSecurityRestricted {
+		return reasons
+	}
+
+	// Restricted only
+	if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		reasons = append(reasons, "must set allowPrivilegeEscalation to false")
+	}
+	if !dropsAll(sc) {
+		reasons = append(reasons, "must drop ALL capabilities")
+	}
+	if !runsAsNonRoot(pod, sc) {
+		reasons = append(reasons, "must set runAsNonRoot to true")
+	}
+	if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+		reasons = append(reasons, "must not set runAsUser to 0")
+	}
+	if !hasSeccompProfile(pod, sc) {
+		reasons = append(reasons, "must set a RuntimeDefault or Localhost seccomp profile")
+	}
+	return reasons
+}
+
+// restrictedVolume determines if the volume has a type allowed under the restricted level
+func restrictedVolume(volume *corev1.Volume) bool {
+	source := volume.VolumeSource
+	return source.ConfigMap != nil || source.CSI != nil || source.DownwardAPI != nil ||
+		source.EmptyDir != nil || source.Ephemeral != nil || source.PersistentVolumeClaim != nil ||
+		source.Projected != nil || source.Secret != nil
+}
+
+// dropsAll determines if the container drops all capabilities
+func dropsAll(sc *corev1.SecurityContext) bool {
+	if sc.Capabilities == nil {
+		return false
+	}
+	for _, capability := range sc.Capabilities.Drop {
+		if strings.ToUpper(string(capability)) == "ALL" {
+			return true
+		}
+	}
+	return false
+}
+
+// runsAsNonRoot checks the container, falling back to the pod
+func runsAsNonRoot(pod *corev1.PodTemplateSpec, sc *corev1.SecurityContext) bool {
+	if sc.RunAsNonRoot != nil {
+		return *sc.RunAsNonRoot
+	}
+	podSC := pod.Spec.SecurityContext
+	return podSC != nil && podSC.RunAsNonRoot != nil && *podSC.RunAsNonRoot
+}
+
+// hasSeccompProfile checks the container, falling back to the pod
+func hasSeccompProfile(pod *corev1.PodTemplateSpec, sc *corev1.SecurityContext) bool {
+	profile := sc.SeccompProfile
+	if profile == nil && pod.Spec.SecurityContext != nil {
+		profile = pod.Spec.SecurityContext.SeccompProfile
+	}
+	if profile == nil {
+		return false
+	}
+	return profile.Type == corev1.SeccompProfileTypeRuntimeDefault ||
+		profile.Type == corev1.SeccompProfileTypeLocalhost
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics
+
+import (
+	"reflect"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+)
+
+var (
+	yes  = true
+	no   = false
+	root = int64(0)
+	user = int64(1000)
+)
+
+// restrictedContext is a container security context that meets the restricted level
+func restrictedContext() *corev1.SecurityContext {
+	return &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &no,
+		RunAsNonRoot:             &yes,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+func TestCheckContainer(t *testing.T) {
+	tests := []struct {
+		name       string
+		context    func(sc *corev1.SecurityContext) *corev1.SecurityContext
+		pod        corev1.PodTemplateSpec
+		baseline   []string
+		restricted []string
+	}{
+		{
+			name:       "restricted",
+			context:    func(sc *corev1.SecurityContext) *corev1.SecurityContext { return sc },
+			baseline:   []string{},
+			restricted: []string{},
+		},
+		{
+			name:     "no security context",
+			context:  func(sc *corev1.SecurityContext) *corev1.SecurityContext { return nil },
+			baseline: []string{},
+			restricted: []string{
+				"must set allowPrivilegeEscalation to false",
+				"must drop ALL capabilities",
+				"must set runAsNonRoot to true",
+				"must set a RuntimeDefault or Localhost seccomp profile",
+			},
+		},
+		{
+			name: "privileged",
+			context: func(sc *corev1.SecurityContext) *corev1.SecurityContext {
+				sc.Privileged = &yes
+				return sc
+			},
+			baseline:   []string{"is privileged"},
+			restricted: []string{"is privileged"},
+		},
+		{
+			name: "capability not in baseline",
+			context: func(sc *corev1.SecurityContext) *corev1.SecurityContext {
+				sc.Capabilities.Add = []corev1.Capability{"SYS_ADMIN"}
+				return sc
+			},
+			baseline:   []string{"adds capability SYS_ADMIN"},
+			restricted: []string{"adds capability SYS_ADMIN"},
+		},
+		{
+			name: "capability in baseline",
+			context: func(sc *corev1.SecurityContext) *corev1.SecurityContext {
+				sc.Capabilities.Add = []corev1.Capability{"CHOWN", "NET_BIND_SERVICE"}
+				return sc
+			},
+			baseline:   []string{},
+			restricted: []string{"adds capability CHOWN"},
+		},
+		{
+			name: "unconfined seccomp",
+			context: func(sc *corev1.SecurityContext) *corev1.SecurityContext {
+				sc.SeccompProfile.Type = corev1.SeccompProfileTypeUnconfined
+				return sc
+			},
+			baseline: []string{"uses an Unconfined seccomp profile"},
+			restricted: []string{
+				"uses an Unconfined seccomp profile",
+				"must set a RuntimeDefault or Localhost seccomp profile",
+			},
+		},
+		{
+			name:    "unconfined apparmor",
+			context: func(sc *corev1.SecurityContext) *corev1.SecurityContext { return sc },
+			pod: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					corev1.AppArmorBetaContainerAnnotationKeyPrefix + "app": corev1.AppArmorBetaProfileNameUnconfined,
+				}},
+			},
+			baseline:   []string{"uses an unconfined AppArmor profile"},
+			restricted: []string{"uses an unconfined AppArmor profile"},
+		},
+		{
+			name: "privilege escalation",
+			context: func(sc *corev1.SecurityContext) *corev1.SecurityContext {
+				sc.AllowPrivilegeEscalation = &yes
+				return sc
+			},
+			baseline:   []string{},
+			restricted: []string{"must set allowPrivilegeEscalation to false"},
+		},
+		{
+			name: "root user",
+			context: func(sc *corev1.SecurityContext) *corev1.SecurityContext {
+				sc.RunAsNonRoot = &no
+				sc.RunAsUser = &root
+				return sc
+			},
+			baseline:   []string{},
+			restricted: []string{"must set runAsNonRoot to true", "must not set runAsUser to 0"},
+		},
+		{
+			name: "pod security context",
+			context: func(sc *corev1.SecurityContext) *corev1.SecurityContext {
+				sc.RunAsNonRoot = nil
+				sc.SeccompProfile = nil
+				return sc
+			},
+			pod: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{SecurityContext: &corev1.PodSecurityContext{
+					RunAsNonRoot:   &yes,
+					SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost},
+				}},
+			},
+			baseline:   []string{},
+			restricted: []string{},
+		},
+	}
+	for _, test := range tests {
+		for level, expected := range map[string][]string{
+			PodSecurityBaseline:   test.baseline,
+			PodSecurityRestricted: test.restricted,
+		} {
+			container := corev1.Container{Name: "app", SecurityContext: test.context(restrictedContext())}
+			reasons := checkContainer(&test.pod, &container, level)
+			if !reflect.DeepEqual(reasons, expected) {
+				t.Errorf("%s (%s): expected %v, found %v", test.name, level, expected, reasons)
+			}
+		}
+	}
+}
+
+func TestCheckPodSecurity(t *testing.T) {
+	pod := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			HostNetwork: true,
+			Volumes: []corev1.Volume{
+				{Name: "data", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/data"}}},
+				{Name: "shared", VolumeSource: corev1.VolumeSource{NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/"}}},
+				{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+			InitContainers: []corev1.Container{{Name: "init", SecurityContext: &corev1.SecurityContext{Privileged: &yes}}},
+			Containers:     []corev1.Container{{Name: "app", SecurityContext: restrictedContext()}},
+		},
+	}
+	js := &jobset.JobSet{
+		Spec: jobset.JobSetSpec{
+			ReplicatedJobs: []jobset.ReplicatedJob{
+				{Name: "m", Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: pod}}},
+			},
+		},
+	}
+	tests := []struct {
+		level    string
+		expected []string
+	}{
+		{level: PodSecurityPrivileged, expected: []string{}},
+		{level: "", expected: []string{}},
+		{
+			level: PodSecurityBaseline,
+			expected: []string{
+				"replicated job m: host namespaces are not allowed",
+				"replicated job m: hostPath volume data is not allowed",
+				"replicated job m: container init is privileged",
+			},
+		},
+		{
+			level: PodSecurityRestricted,
+			expected: []string{
+				"replicated job m: host namespaces are not allowed",
+				"replicated job m: hostPath volume data is not allowed",
+				"replicated job m: volume shared has a type that is not allowed",
+				"replicated job m: container init is privileged",
+				"replicated job m: container init must set allowPrivilegeEscalation to false",
+				"replicated job m: container init must drop ALL capabilities",
+				"replicated job m: container init must set runAsNonRoot to true",
+				"replicated job m: container init must set a RuntimeDefault or Localhost seccomp profile",
+			},
+		},
+	}
+	for _, test := range tests {
+		violations := CheckPodSecurity(js, test.level)
+		if !reflect.DeepEqual(violations, test.expected) {
+			t.Errorf("%q: expected %v, found %v", test.level, test.expected, violations)
+		}
+	}
+}
+
+func TestGetSecurityContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		context  api.SecurityContext
+		expected *corev1.SecurityContext
+	}{
+		{
+			name:    "default",
+			context: api.SecurityContext{},
+			expected: &corev1.SecurityContext{
+				Privileged:             &no,
+				ReadOnlyRootFilesystem: &no,
+				Capabilities:           &corev1.Capabilities{Add: []corev1.Capability{}, Drop: []corev1.Capability{}},
+			},
+		},
+		{
+			name: "capabilities",
+			context: api.SecurityContext{
+				Privileged:   true,
+				AllowPtrace:  true,
+				AllowAdmin:   true,
+				Capabilities: api.Capabilities{Add: []string{"SYS_PTRACE", "NET_RAW"}, Drop: []string{"MKNOD"}},
+			},
+			expected: &corev1.SecurityContext{
+				Privileged:             &yes,
+				ReadOnlyRootFilesystem: &no,
+				Capabilities: &corev1.Capabilities{
+					Add:  []corev1.Capability{capPtrace, capAdmin, "NET_RAW"},
+					Drop: []corev1.Capability{"MKNOD"},
+				},
+			},
+		},
+		{
+			name: "restricted",
+			context: api.SecurityContext{
+				RunAsUser:                &user,
+				RunAsGroup:               &user,
+				RunAsNonRoot:             &yes,
+				AllowPrivilegeEscalation: &no,
+				ReadOnlyRootFilesystem:   true,
+				Capabilities:             api.Capabilities{Drop: []string{"ALL"}},
+				SeccompProfile:           api.Profile{Type: "RuntimeDefault"},
+			},
+			expected: &corev1.SecurityContext{
+				Privileged:               &no,
+				RunAsUser:                &user,
+				RunAsGroup:               &user,
+				RunAsNonRoot:             &yes,
+				AllowPrivilegeEscalation: &no,
+				ReadOnlyRootFilesystem:   &yes,
+				Capabilities:             &corev1.Capabilities{Add: []corev1.Capability{}, Drop: []corev1.Capability{"ALL"}},
+				SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+		},
+		{
+			name:    "localhost seccomp",
+			context: api.SecurityContext{SeccompProfile: api.Profile{Type: "Localhost", LocalhostProfile: "profiles/metric.json"}},
+			expected: &corev1.SecurityContext{
+				Privileged:             &no,
+				ReadOnlyRootFilesystem: &no,
+				Capabilities:           &corev1.Capabilities{Add: []corev1.Capability{}, Drop: []corev1.Capability{}},
+				SeccompProfile: &corev1.SeccompProfile{
+					Type:             corev1.SeccompProfileTypeLocalhost,
+					LocalhostProfile: &[]string{"profiles/metric.json"}[0],
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		sc := getSecurityContext(&test.context)
+		if !reflect.DeepEqual(sc, test.expected) {
+			t.Errorf("%s: expected %+v, found %+v", test.name, test.expected, sc)
+		}
+	}
+
+	// The restricted context is admitted under the restricted level
+	container := corev1.Container{Name: "app", SecurityContext: getSecurityContext(&tests[2].context)}
+	reasons := checkContainer(&corev1.PodTemplateSpec{}, &container, PodSecurityRestricted)
+	if len(reasons) != 0 {
+		t.Errorf("expected the restricted context to be admitted, found %v", reasons)
+	}
+}
+
+func TestSetPodSecurity(t *testing.T) {
+	group := int64(2000)
+	shared := map[string]string{"shared": "annotation"}
+	rj := jobset.ReplicatedJob{Name: "l"}
+	rj.Template.Spec.Template.ObjectMeta.Annotations = shared
+	rj.Template.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsNonRoot: &yes}
+
+	containerSpecs := []specs.ContainerSpec{
+		{JobName: "l", Name: "launcher", Attributes: &api.ContainerSpec{SecurityContext: api.SecurityContext{
+			FSGroup:         &group,
+			AppArmorProfile: api.Profile{Type: "RuntimeDefault"},
+		}}},
+		{Name: "sidecar", Attributes: &api.ContainerSpec{SecurityContext: api.SecurityContext{
+			AppArmorProfile: api.Profile{Type: "Localhost", LocalhostProfile: "metric"},
+		}}},
+		{JobName: "w", Name: "workers", Attributes: &api.ContainerSpec{SecurityContext: api.SecurityContext{
+			AppArmorProfile: api.Profile{Type: "Unconfined"},
+		}}},
+	}
+	setPodSecurity(&rj, containerSpecs)
+
+	expected := map[string]string{
+		"shared": "annotation",
+		corev1.AppArmorBetaContainerAnnotationKeyPrefix + "launcher": corev1.AppArmorBetaProfileRuntimeDefault,
+		corev1.AppArmorBetaContainerAnnotationKeyPrefix + "sidecar":  corev1.AppArmorBetaProfileNamePrefix + "metric",
+	}
+	template := rj.Template.Spec.Template
+	if !reflect.DeepEqual(template.ObjectMeta.Annotations, expected) {
+		t.Errorf("expected annotations %v, found %v", expected, template.ObjectMeta.Annotations)
+	}
+	if len(shared) != 1 {
+		t.Errorf("expected the shared annotations not to change, found %v", shared)
+	}
+	if template.Spec.SecurityContext == nil || *template.Spec.SecurityContext.FSGroup != group ||
+		template.Spec.SecurityContext.RunAsNonRoot == nil || !*template.Spec.SecurityContext.RunAsNonRoot {
+		t.Errorf("expected fsGroup %d in the pod security context, found %v", group, template.Spec.SecurityContext)
+	}
+
+	// Without a fsGroup, there is no pod security context
+	rj = jobset.ReplicatedJob{Name: "w"}
+	setPodSecurity(&rj, containerSpecs)
+	if rj.Template.Spec.Template.Spec.SecurityContext != nil {
+		t.Errorf("expected no pod security context, found %v", rj.Template.Spec.Template.Spec.SecurityContext)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics
+
+// A Sample is one value parsed from the log of a metric, e.g., the
+// latency of an OSU benchmark for one message size
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+	Unit   string
+
+	// How to merge the sample across pods (defaults to the mean)
+	Merge string
+
+	// If a higher or lower value is better, to find regressions between runs
+	// (empty for a statistic without a direction, e.g., memory use)
+	Better string
+}
+
+// Directions of a sample that is better when it is higher or lower
+const (
+	BetterHigher = "higher"
+	BetterLower  = "lower"
+)
+
+// Ways to merge samples with the same name and labels. The values of one pod
+// (e.g., one per timepoint) are merged first, and then the values of the pods.
+const (
+	// The mean of the values of each pod, and of the pods
+	MergeMean = "mean"
+
+	// The mean of each pod, summed across pods (e.g., bandwidth)
+	MergeSum = "sum"
+
+	// The largest or smallest value (e.g., the worst latency)
+	MergeMax = "max"
+	MergeMin = "min"
+
+	// The sum of all values, for counts in a bucket of a histogram
+	// (the bucket is a label, so each bucket is a sample)
+	MergeHistogram = "histogram"
+)
+
+// A ResultParser parses samples from the log of a metric container. Output
+// that isn't understood is skipped, so a log without results has no samples.
+type ResultParser interface {
+	ParseResults(log string) []Sample
+}
+
+// GetResultParser returns the parser of a metric, if it has one
+func GetResultParser(m Metric) (ResultParser, bool) {
+	parser, ok := m.(ResultParser)
+	return parser, ok
+}
+
+// Kinds of charts for the samples of a metric in a report
+const (
+	// A bar for each sample (the default)
+	ChartBar = "bar"
+
+	// Lines of the value by a numeric label, e.g., latency by message size
+	ChartLine = "line"
+
+	// A matrix of the value by two labels, e.g., round trip times between ranks
+	ChartHeatmap = "heatmap"
+
+	// Lines of the value by timepoint
+	ChartTimeSeries = "timeseries"
+)
+
+// TimepointLabel is the label of a sample from one timepoint of a metric,
+// the index of the section of the log
+const TimepointLabel = "timepoint"
+
+// A Chart says how a report plots the samples of a metric. X (and Y for a
+// heatmap) are labels of the samples, and the value is the other axis.
+type Chart struct {
+	Kind string
+	X    string
+	Y    string
+	LogX bool
+}
+
+// A ResultCharter has a chart for its samples
+type ResultCharter interface {
+	ResultChart() Chart
+}
+
+// GetResultChart returns the chart of a metric, or a bar chart
+func GetResultChart(m Metric) Chart {
+	charter, ok := m.(ResultCharter)
+	if !ok {
+		return Chart{Kind: ChartBar}
+	}
+	return charter.ResultChart()
+}
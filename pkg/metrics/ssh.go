@@ -0,0 +1,276 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	corev1 "k8s.io/api/core/v1"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+)
+
+var (
+	// SSHMountPath is where the MetricSet keys are mounted. This can't be under
+	// /metrics_operator, which is a read only config map.
+	SSHMountPath   = "/metrics_operator_ssh"
+	sshVolumeName  = "metrics-operator-ssh"
+	secretReadOnly = int32(0400)
+)
+
+// Keys of the ssh Secret (and files in the mount)
+const (
+	SSHPrivateKey     = "id_ed25519"
+	SSHPublicKey      = "id_ed25519.pub"
+	SSHAuthorizedKeys = "authorized_keys"
+	SSHHostKey        = "ssh_host_ed25519_key"
+	SSHHostPublicKey  = "ssh_host_ed25519_key.pub"
+	SSHKnownHosts     = "known_hosts"
+)
+
+// An sshMetric runs sshd between its pods (e.g., a LauncherWorker) with the
+// keys of the MetricSet. It returns the hostnames for known_hosts.
+type sshMetric interface {
+	SSHHosts(*api.MetricSet) []string
+}
+
+// SSHSecretName is the name of the Secret with the keys of a MetricSet
+func SSHSecretName(spec *api.MetricSet) string {
+	return spec.Name + "-ssh"
+}
+
+// SSHHosts returns the hostnames of all metrics that use ssh. When there
+// are none, the MetricSet doesn't need keys.
+func SSHHosts(spec *api.MetricSet, set *MetricSet) []string {
+	hosts := []string{}
+	for _, metric := range set.Metrics() {
+		m, ok := (*metric).(sshMetric)
+		if ok {
+			hosts = append(hosts, m.SSHHosts(spec)...)
+		}
+	}
+	return hosts
+}
+
+// GenerateSSHKeys generates a client keypair and a host keypair shared by all
+// pods of the MetricSet. The client key is authorized, and the host key is known
+// for all hosts, so ssh between pods doesn't depend on keys in the images.
+func GenerateSSHKeys(hosts []string) (map[string][]byte, error) {
+	data := map[string][]byte{}
+
+	clientKey, clientPublicKey, err := generateSSHKey("metrics-operator")
+	if err != nil {
+		return data, err
+	}
+	hostKey, hostPublicKey, err := generateSSHKey("metrics-operator-host")
+	if err != nil {
+		return data, err
+	}
+	publicKey, err := ssh.NewPublicKey(hostPublicKey)
+	if err != nil {
+		return data, err
+	}
+	data[SSHPrivateKey] = clientKey
+	data[SSHPublicKey] = marshalSSHPublicKey(clientPublicKey)
+	data[SSHAuthorizedKeys] = data[SSHPublicKey]
+	data[SSHHostKey] = hostKey
+	data[SSHHostPublicKey] = marshalSSHPublicKey(hostPublicKey)
+
+	// Each pod is known by its short and fully qualified hostname
+	knownHosts := ""
+	for _, host := range hosts {
+		addresses := []string{host}
+		short := strings.SplitN(host, ".", 2)[0]
+		if short != host {
+			addresses = []string{short, host}
+		}
+		knownHosts += knownhosts.Line(addresses, publicKey) + "\n"
+	}
+	data[SSHKnownHosts] = []byte(knownHosts)
+	return data, nil
+}
+
+// generateSSHKey returns an ed25519 private key (in the OpenSSH format) and public key
+func generateSSHKey(comment string) ([]byte, ed25519.PublicKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The checks are random, but must match
+	check := make([]byte, 4)
+	_, err = rand.Read(check)
+	if err != nil {
+		return nil, nil, err
+	}
+	wirePublicKey := ssh.Marshal(struct {
+		KeyType string
+		Key     []byte
+	}{ssh.KeyAlgoED25519, publicKey})
+
+	// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.key
+	private := ssh.Marshal(struct {
+		Check1    uint32
+		Check2    uint32
+		KeyType   string
+		PublicKey []byte
+		Key       []byte
+		Comment   string
+	}{
+		binary.BigEndian.Uint32(check),
+		binary.BigEndian.Uint32(check),
+		ssh.KeyAlgoED25519,
+		publicKey,
+		privateKey,
+		comment,
+	})
+
+	// The unencrypted block is padded to the cipher block size (8)
+	for i := 1; len(private)%8 != 0; i++ {
+		private = append(private, byte(i))
+	}
+	key := ssh.Marshal(struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PublicKey    []byte
+		PrivateBlock []byte
+	}{"none", "none", "", 1, wirePublicKey, private})
+
+	block := &pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte("openssh-key-v1\x00"), key...),
+	}
+	return pem.EncodeToMemory(block), publicKey, nil
+}
+
+// marshalSSHPublicKey returns the public key in authorized_keys format
+func marshalSSHPublicKey(key ed25519.PublicKey) []byte {
+	publicKey, err := ssh.NewPublicKey(key)
+	if err != nil {
+		return []byte{}
+	}
+	return ssh.MarshalAuthorizedKey(publicKey)
+}
+
+// SSHScript installs the MetricSet keys (when mounted) and starts sshd.
+// The host public key is kept to add resolved addresses to known_hosts.
+func SSHScript() string {
+	template := `# Install the ssh keys of the MetricSet, if they are mounted
+sshd_args=""
+if [ -d %[1]s ]; then
+	mkdir -p ~/.ssh /etc/ssh
+	chmod 700 ~/.ssh
+	cp %[1]s/%[2]s %[1]s/%[3]s %[1]s/%[4]s %[1]s/%[5]s ~/.ssh/
+	cp %[1]s/%[6]s ~/.ssh/metrics_operator_host_key.pub
+	cp %[1]s/%[7]s %[1]s/%[6]s /etc/ssh/
+	chmod 600 ~/.ssh/%[2]s ~/.ssh/%[4]s /etc/ssh/%[7]s
+	chmod 644 ~/.ssh/%[3]s ~/.ssh/%[5]s /etc/ssh/%[6]s
+	sshd_args="-h /etc/ssh/%[7]s"
+fi
+
+# Start ssh daemon
+/usr/sbin/sshd -D ${sshd_args} &`
+	return fmt.Sprintf(
+		template,
+		SSHMountPath,
+		SSHPrivateKey,
+		SSHPublicKey,
+		SSHAuthorizedKeys,
+		SSHKnownHosts,
+		SSHHostPublicKey,
+		SSHHostKey,
+	)
+}
+
+// SSHKnownAddresses adds resolved addresses (one per line) to known_hosts,
+// for launchers that use them instead of hostnames (e.g., openmpi)
+func SSHKnownAddresses(addresses string) string {
+	template := `
+if [ -f ~/.ssh/metrics_operator_host_key.pub ]; then
+	for address in $(cat %s); do
+		echo "${address} $(cat ~/.ssh/metrics_operator_host_key.pub)" >> ~/.ssh/%s
+	done
+fi
+`
+	return fmt.Sprintf(template, addresses, SSHKnownHosts)
+}
+
+// setSSH mounts the ssh Secret into the containers of a metric that uses ssh.
+func setSSH(
+	spec *api.MetricSet,
+	m Metric,
+	jobs []*jobset.ReplicatedJob,
+	containerSpecs []*specs.ContainerSpec,
+) {
+	sm, ok := m.(sshMetric)
+	if ok && len(sm.SSHHosts(spec)) > 0 {
+		mountSecret(jobs, containerSpecs, sshVolumeName, SSHSecretName(spec), SSHMountPath)
+	}
+}
+
+// mountSecret mounts a Secret (read only) into the containers of the metric
+func mountSecret(
+	jobs []*jobset.ReplicatedJob,
+	containerSpecs []*specs.ContainerSpec,
+	volumeName, secretName, path string,
+) {
+	mountVolume(jobs, containerSpecs, corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  secretName,
+				DefaultMode: &secretReadOnly,
+			},
+		},
+	}, path, true)
+}
+
+// mountVolume mounts a volume into the containers of the metric.
+// Addon containers (e.g., sidecars) don't use them, so they aren't mounted there.
+func mountVolume(
+	jobs []*jobset.ReplicatedJob,
+	containerSpecs []*specs.ContainerSpec,
+	volume corev1.Volume,
+	path string,
+	readOnly bool,
+) {
+	names := map[string]bool{}
+	for _, cs := range containerSpecs {
+		if len(cs.Command) == 0 {
+			names[cs.Name] = true
+		}
+	}
+	for _, rj := range jobs {
+		podSpec := &rj.Template.Spec.Template.Spec
+		podSpec.Volumes = append(podSpec.Volumes, volume)
+		for i, container := range podSpec.Containers {
+			if !names[container.Name] {
+				continue
+			}
+
+			// Containers of a job share the mounts, so we need a copy
+			mounts := append([]corev1.VolumeMount{}, container.VolumeMounts...)
+			podSpec.Containers[i].VolumeMounts = append(mounts, corev1.VolumeMount{
+				Name:      volume.Name,
+				MountPath: path,
+				ReadOnly:  readOnly,
+			})
+		}
+	}
+}
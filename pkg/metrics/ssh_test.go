@@ -0,0 +1,69 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+func TestGenerateSSHKeys(t *testing.T) {
+	hosts := []string{"ms-l-0-0.ms.default.svc.cluster.local", "ms-w-0-0.ms.default.svc.cluster.local"}
+	data, err := metrics.GenerateSSHKeys(hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The private keys are in the OpenSSH format, and match their public keys
+	for private, public := range map[string]string{
+		metrics.SSHPrivateKey: metrics.SSHPublicKey,
+		metrics.SSHHostKey:    metrics.SSHHostPublicKey,
+	} {
+		signer, err := ssh.ParsePrivateKey(data[private])
+		if err != nil {
+			t.Fatalf("parsing %s: %s", private, err)
+		}
+		if !bytes.Equal(ssh.MarshalAuthorizedKey(signer.PublicKey()), data[public]) {
+			t.Errorf("%s does not match %s", private, public)
+		}
+	}
+	if !bytes.Equal(data[metrics.SSHAuthorizedKeys], data[metrics.SSHPublicKey]) {
+		t.Errorf("expected the client key to be authorized")
+	}
+	knownHosts := string(data[metrics.SSHKnownHosts])
+	if !strings.HasPrefix(knownHosts, "ms-l-0-0,"+hosts[0]+" ssh-ed25519 ") || strings.Count(knownHosts, "\n") != 2 {
+		t.Errorf("unexpected known_hosts:\n%s", knownHosts)
+	}
+
+	// OpenSSH itself should read the private key, if it's installed
+	keygen, err := exec.LookPath("ssh-keygen")
+	if err != nil {
+		return
+	}
+	path := filepath.Join(t.TempDir(), metrics.SSHPrivateKey)
+	err = os.WriteFile(path, data[metrics.SSHPrivateKey], 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(keygen, "-y", "-f", path).CombinedOutput()
+	if err != nil {
+		t.Fatalf("ssh-keygen could not read the private key: %s\n%s", err, out)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) < 2 || !strings.HasPrefix(string(data[metrics.SSHPublicKey]), fields[0]+" "+fields[1]) {
+		t.Errorf("ssh-keygen public key %s does not match", out)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metrics/hosts"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+)
+
+// EntrypointData is the data model for user templates that override a block
+// (pre, command, or post) of a metric entrypoint. The fields are documented
+// in the user guide, so keep them in sync.
+type EntrypointData struct {
+
+	// Name and namespace of the MetricSet, and the metric
+	MetricSet string
+	Namespace string
+	Metric    string
+
+	// Replicated job of the entrypoint, and all replicated jobs of the metric
+	Job  string
+	Jobs []string
+
+	// Number of pods for the metric, and for the replicated job of the entrypoint
+	Pods    int32
+	JobPods int32
+
+	// Tasks from the tasks option (0 when not set)
+	Tasks int32
+
+	// Fully qualified hostnames of all pods of the metric, in job order
+	Hosts []string
+
+	// Metric options, as strings
+	Options     map[string]string
+	ListOptions map[string][]string
+
+	// Volume mounts (from addons) of the container, by volume name
+	Mounts map[string]string
+
+	// The block generated by the metric, to wrap or extend it
+	Default string
+}
+
+// templateFuncs are available to user templates
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// renderTemplates renders user templates into the entrypoint blocks they override.
+// This happens after addons so the addon mounts are known, and the templates
+// have the last word. An invalid template fails the JobSet creation.
+func renderTemplates(
+	spec *api.MetricSet,
+	m Metric,
+	jobs []*jobset.ReplicatedJob,
+	containerSpecs []*specs.ContainerSpec,
+) error {
+	if len(m.Templates()) == 0 {
+		return nil
+	}
+	data := EntrypointData{
+		MetricSet:   spec.Name,
+		Namespace:   spec.Namespace,
+		Metric:      m.Name(),
+		Pods:        m.Pods(),
+		Hosts:       hosts.NewGenerator(spec).Hosts(hosts.NewJobs(jobs)...),
+		Options:     map[string]string{},
+		ListOptions: map[string][]string{},
+	}
+	for key, value := range m.Options() {
+		data.Options[key] = value.String()
+	}
+	for key, values := range m.ListOptions() {
+		for _, value := range values {
+			data.ListOptions[key] = append(data.ListOptions[key], value.String())
+		}
+	}
+	tasks, ok := m.Options()["tasks"]
+	if ok {
+		data.Tasks = tasks.IntVal
+	}
+	jobPods := map[string]int32{}
+	for _, rj := range jobs {
+		data.Jobs = append(data.Jobs, rj.Name)
+		jobPods[rj.Name] = *rj.Template.Spec.Parallelism
+	}
+
+	// Parse all templates first, so an invalid one fails regardless of the job
+	parsed := []*template.Template{}
+	for i, t := range m.Templates() {
+		tmpl, err := template.New(fmt.Sprintf("%s-%s", m.Name(), t.Block)).
+			Funcs(templateFuncs).
+			Option("missingkey=error").
+			Parse(t.Template)
+		if err != nil {
+			return fmt.Errorf("template %d for metric %s: %s", i, m.Name(), err)
+		}
+		if t.Job != "" && jobPods[t.Job] == 0 {
+			return fmt.Errorf("template %d for metric %s: unknown job %q (jobs are %v)", i, m.Name(), t.Job, data.Jobs)
+		}
+		parsed = append(parsed, tmpl)
+	}
+
+	for _, cs := range containerSpecs {
+
+		// A container with a command does not use the entrypoint
+		if len(cs.Command) > 0 {
+			continue
+		}
+		jobName := cs.JobName
+		if jobName == "" {
+			jobName = ReplicatedJobName
+		}
+		data.Job = jobName
+		data.JobPods = jobPods[jobName]
+		data.Mounts = getContainerMounts(jobs, jobName, cs.Name)
+
+		for i, t := range m.Templates() {
+			if t.Job != "" && t.Job != jobName {
+				continue
+			}
+			block := &cs.EntrypointScript.Pre
+			if t.Block == api.BlockCommand {
+				block = &cs.EntrypointScript.Command
+			} else if t.Block == api.BlockPost {
+				block = &cs.EntrypointScript.Post
+			}
+			data.Default = *block
+
+			var rendered strings.Builder
+			err := parsed[i].Execute(&rendered, data)
+			if err != nil {
+				return fmt.Errorf("template %d for metric %s: %s", i, m.Name(), err)
+			}
+			*block = rendered.String()
+		}
+	}
+	return nil
+}
+
+// getContainerMounts returns the volume mounts of a container in a replicated job
+func getContainerMounts(jobs []*jobset.ReplicatedJob, jobName, containerName string) map[string]string {
+	mounts := map[string]string{}
+	for _, rj := range jobs {
+		if rj.Name != jobName {
+			continue
+		}
+		for _, container := range rj.Template.Spec.Template.Spec.Containers {
+			if container.Name != containerName {
+				continue
+			}
+			for _, mount := range container.VolumeMounts {
+				mounts[mount.Name] = mount.MountPath
+			}
+		}
+	}
+	return mounts
+}
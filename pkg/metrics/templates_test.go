@@ -0,0 +1,85 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/network"
+)
+
+// getEntrypoints renders the JobSet for one metric and returns the entrypoints by job
+func getEntrypoints(t *testing.T, templates []api.EntrypointTemplate) (map[string]string, error) {
+	spec := &api.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+		Spec: api.MetricSetSpec{
+			Pods:        3,
+			ServiceName: "ms",
+			Metrics: []api.Metric{{
+				Name:      "network-osu-benchmark",
+				Templates: templates,
+			}},
+		},
+	}
+	if !spec.Validate() {
+		t.Fatalf("MetricSet did not validate")
+	}
+	m, err := metrics.GetMetric(&spec.Spec.Metrics[0], spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := metrics.MetricSet{}
+	set.Add(&m)
+	_, containerSpecs, err := metrics.GetJobSet(spec, &set)
+	entrypoints := map[string]string{}
+	for _, cs := range containerSpecs {
+		entrypoints[cs.JobName] = cs.EntrypointScript.WriteScript()
+	}
+	return entrypoints, err
+}
+
+func TestRenderTemplates(t *testing.T) {
+	entrypoints, err := getEntrypoints(t, []api.EntrypointTemplate{
+		{Block: api.BlockCommand, Job: "l", Template: `mpirun -np {{ .Pods }} --host {{ join .Hosts "," }} ./bench`},
+		{Block: api.BlockPost, Template: `echo "{{ .Metric }} {{ .Job }}/{{ .JobPods }}"
+{{ .Default }}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := "ms-l-0-0.ms.default.svc.cluster.local,ms-w-0-0.ms.default.svc.cluster.local,ms-w-0-1.ms.default.svc.cluster.local"
+	if !strings.Contains(entrypoints["l"], "mpirun -np 3 --host "+hosts+" ./bench") {
+		t.Errorf("launcher command was not rendered:\n%s", entrypoints["l"])
+	}
+	if !strings.Contains(entrypoints["l"], `echo "network-osu-benchmark l/1"`) ||
+		!strings.Contains(entrypoints["w"], `echo "network-osu-benchmark w/2"`) {
+		t.Errorf("post block was not rendered for all jobs:\n%s", entrypoints)
+	}
+	if strings.Contains(entrypoints["w"], "./bench") {
+		t.Errorf("command template for the launcher was rendered for workers")
+	}
+}
+
+func TestRenderTemplatesValidation(t *testing.T) {
+	for name, template := range map[string]api.EntrypointTemplate{
+		"syntax":        {Block: api.BlockPre, Template: "{{ .Pods "},
+		"unknown field": {Block: api.BlockPre, Template: "{{ .Nodes }}"},
+		"missing key":   {Block: api.BlockPre, Template: "{{ .Options.nope }}"},
+		"unknown job":   {Block: api.BlockPre, Job: "x", Template: "echo"},
+	} {
+		_, err := getEntrypoints(t, []api.EntrypointTemplate{template})
+		if err == nil {
+			t.Errorf("expected %s to fail rendering", name)
+		}
+	}
+}
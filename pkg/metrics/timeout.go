@@ -0,0 +1,80 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+)
+
+// setTimeout adds the metric timeout to the entrypoints of the containers
+// that decide success (e.g., the launcher and not the workers, which sleep).
+func setTimeout(m Metric, jobs []*jobset.ReplicatedJob, containerSpecs []*specs.ContainerSpec) {
+	if m.Timeout() <= 0 {
+		return
+	}
+
+	// A metric without success jobs needs all of its jobs to complete
+	timed := map[string]bool{}
+	for _, name := range m.SuccessJobs() {
+		timed[name] = true
+	}
+	for _, rj := range jobs {
+		if len(m.SuccessJobs()) == 0 {
+			timed[rj.Name] = true
+		}
+	}
+	for _, cs := range containerSpecs {
+		if !timed[containerJobName(cs)] || len(cs.Command) > 0 {
+			continue
+		}
+		cs.EntrypointScript.Timeout = m.Timeout()
+	}
+}
+
+// setFailurePolicy fails the job when an entrypoint that exits with the exit code
+// of the command (with a timeout or debug mode) fails, instead of restarting the pod.
+// Other entrypoints exit with the code of the post block, and keep the restart policy.
+func setFailurePolicy(jobs []*jobset.ReplicatedJob, containerSpecs []*specs.ContainerSpec) {
+	rules := map[string][]batchv1.PodFailurePolicyRule{}
+	for _, cs := range containerSpecs {
+		if len(cs.Command) > 0 || (cs.EntrypointScript.Timeout <= 0 && cs.EntrypointScript.Debug == "") {
+			continue
+		}
+		jobName := containerJobName(cs)
+		containerName := cs.Name
+		rules[jobName] = append(rules[jobName], batchv1.PodFailurePolicyRule{
+			Action: batchv1.PodFailurePolicyActionFailJob,
+			OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+				ContainerName: &containerName,
+				Operator:      batchv1.PodFailurePolicyOnExitCodesOpNotIn,
+				Values:        []int32{0},
+			},
+		})
+	}
+
+	// A pod failure policy requires pods to never restart
+	for _, rj := range jobs {
+		if len(rules[rj.Name]) == 0 {
+			continue
+		}
+		rj.Template.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+		rj.Template.Spec.PodFailurePolicy = &batchv1.PodFailurePolicy{Rules: rules[rj.Name]}
+	}
+}
+
+// containerJobName is the replicated job of a container spec
+func containerJobName(cs *specs.ContainerSpec) string {
+	if cs.JobName == "" {
+		return ReplicatedJobName
+	}
+	return cs.JobName
+}
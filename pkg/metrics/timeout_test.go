@@ -0,0 +1,123 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package metrics_test
+
+import (
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/perf"
+)
+
+// TestTimeout checks that the timeout is added to the entrypoints of the jobs that
+// decide success, and that a failing command in those jobs (or in debug mode) fails
+// the job instead of restarting the pod
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		metric  string
+		timeout int32
+		debug   string
+
+		// Timeout of the entrypoint of each container, by job
+		expected map[string]int32
+
+		// Jobs that fail on a non-zero exit code
+		failed map[string]bool
+	}{
+		{
+			metric:   "network-osu-benchmark",
+			timeout:  60,
+			expected: map[string]int32{"l": 60, "w": 0},
+			failed:   map[string]bool{"l": true},
+		},
+		{
+			metric:   "perf-stream",
+			timeout:  30,
+			expected: map[string]int32{metrics.ReplicatedJobName: 30},
+			failed:   map[string]bool{metrics.ReplicatedJobName: true},
+		},
+		{metric: "network-osu-benchmark", timeout: 0, expected: map[string]int32{"l": 0, "w": 0}},
+		{
+			metric:   "network-osu-benchmark",
+			debug:    "onFailure",
+			expected: map[string]int32{"l": 0, "w": 0},
+			failed:   map[string]bool{"l": true, "w": true},
+		},
+	}
+	for _, test := range tests {
+		spec := &api.MetricSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+			Spec: api.MetricSetSpec{
+				Pods:        2,
+				ServiceName: "ms",
+				Metrics:     []api.Metric{{Name: test.metric, Timeout: test.timeout}},
+				Logging:     api.Logging{Debug: test.debug},
+			},
+		}
+		spec.Validate()
+		m, err := metrics.GetMetric(&spec.Spec.Metrics[0], spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set := metrics.MetricSet{}
+		set.Add(&m)
+		js, containerSpecs, err := metrics.GetJobSet(spec, &set)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, cs := range containerSpecs {
+			expected, ok := test.expected[cs.JobName]
+			if !ok {
+				continue
+			}
+			if cs.EntrypointScript.Timeout != expected {
+				t.Errorf("%s: expected a timeout of %d for %s, found %d", test.metric, expected, cs.JobName, cs.EntrypointScript.Timeout)
+			}
+		}
+		for _, rj := range js.Spec.ReplicatedJobs {
+			policy := rj.Template.Spec.PodFailurePolicy
+			restartPolicy := rj.Template.Spec.Template.Spec.RestartPolicy
+			if !test.failed[rj.Name] {
+				if policy != nil || restartPolicy != corev1.RestartPolicyOnFailure {
+					t.Errorf("%s: expected no pod failure policy for %s, found %v (%s)", test.metric, rj.Name, policy, restartPolicy)
+				}
+				continue
+			}
+			if policy == nil || len(policy.Rules) != 1 || restartPolicy != corev1.RestartPolicyNever {
+				t.Fatalf("%s: expected a pod failure policy without restarts for %s", test.metric, rj.Name)
+			}
+
+			// Any non-zero exit code fails the job, including the timeout
+			rule := policy.Rules[0]
+			if rule.Action != batchv1.PodFailurePolicyActionFailJob || rule.OnExitCodes == nil ||
+				!matchesExitCode(rule.OnExitCodes, 1) || !matchesExitCode(rule.OnExitCodes, metadata.TimeoutExitCode) ||
+				matchesExitCode(rule.OnExitCodes, 0) || *rule.OnExitCodes.ContainerName != rj.Template.Spec.Template.Spec.Containers[0].Name {
+				t.Errorf("%s: unexpected pod failure policy rule %+v", test.metric, rule)
+			}
+		}
+	}
+}
+
+// matchesExitCode is true if the pod failure policy requirement matches the exit code
+func matchesExitCode(requirement *batchv1.PodFailurePolicyOnExitCodesRequirement, exitCode int32) bool {
+	found := false
+	for _, value := range requirement.Values {
+		found = found || value == exitCode
+	}
+	if requirement.Operator == batchv1.PodFailurePolicyOnExitCodesOpNotIn {
+		return !found
+	}
+	return found
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+// Package registry resolves image references to digests with the registry
+// HTTP API (anonymous pulls only), so metadata records exactly what ran.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// Docker Hub images without a registry (e.g., ubuntu:22.04)
+	dockerHub         = "registry-1.docker.io"
+	dockerHubAliases  = map[string]bool{"docker.io": true, "index.docker.io": true}
+	defaultTag        = "latest"
+	digestHeader      = "Docker-Content-Digest"
+	authenticateRealm = "realm"
+
+	// Manifests we accept, an index (or list) is preferred for multi-arch images
+	manifestTypes = []string{
+		"application/vnd.oci.image.index.v1+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.docker.distribution.manifest.v2+json",
+	}
+)
+
+// A Reference is an image split into the registry, repository, and tag or digest
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference splits an image, e.g., ghcr.io/converged-computing/metric-osu-benchmark:latest
+func ParseReference(image string) Reference {
+	ref := Reference{Registry: dockerHub}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+
+	// The first component is a registry if it looks like a host
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry = parts[0]
+		name = parts[1]
+	}
+	if dockerHubAliases[ref.Registry] {
+		ref.Registry = dockerHub
+	}
+
+	// A tag follows the last colon after the last slash
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = defaultTag
+	}
+	if ref.Registry == dockerHub && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	ref.Repository = name
+	return ref
+}
+
+// A Resolver looks up the digests of images
+type Resolver struct {
+	Client *http.Client
+
+	// Scheme of registries (https, or http for testing)
+	Scheme string
+}
+
+// NewResolver returns a resolver with a timeout for each request
+func NewResolver(timeout time.Duration) *Resolver {
+	return &Resolver{Client: &http.Client{Timeout: timeout}, Scheme: "https"}
+}
+
+// Digest returns the digest of an image. An image with a digest already has it.
+func (r *Resolver) Digest(ctx context.Context, image string) (string, error) {
+	ref := ParseReference(image)
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	manifest := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", r.Scheme, ref.Registry, ref.Repository, ref.Tag)
+	response, err := r.head(ctx, manifest, "")
+	if err != nil {
+		return "", err
+	}
+
+	// Registries that need a token (even for anonymous pulls) say where to get it
+	if response.StatusCode == http.StatusUnauthorized {
+		token, err := r.token(ctx, response.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", err
+		}
+		response, err = r.head(ctx, manifest, token)
+		if err != nil {
+			return "", err
+		}
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry returned %s for %s", response.Status, image)
+	}
+	digest := response.Header.Get(digestHeader)
+	if digest == "" {
+		return "", fmt.Errorf("registry did not return a digest for %s", image)
+	}
+	return digest, nil
+}
+
+// head requests a manifest, optionally with a bearer token
+func (r *Resolver) head(ctx context.Context, manifest, token string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, manifest, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := r.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+	return response, nil
+}
+
+// token gets an anonymous token for a Bearer challenge, e.g.,
+// Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:user/image:pull"
+func (r *Resolver) token(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("unsupported authentication %q", challenge)
+	}
+	params := url.Values{}
+	realm := ""
+	for _, param := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		if key == authenticateRealm {
+			realm = value
+			continue
+		}
+		params.Set(key, value)
+	}
+	if realm == "" {
+		return "", fmt.Errorf("authentication %q does not have a realm", challenge)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+params.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	response, err := r.Client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request returned %s", response.Status)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&token)
+	if err != nil {
+		return "", err
+	}
+	if token.Token == "" {
+		return token.AccessToken, nil
+	}
+	return token.Token, nil
+}
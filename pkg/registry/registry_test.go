@@ -0,0 +1,70 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReference(t *testing.T) {
+	for image, expected := range map[string]Reference{
+		"ubuntu":                      {Registry: dockerHub, Repository: "library/ubuntu", Tag: "latest"},
+		"docker.io/library/ubuntu:22": {Registry: dockerHub, Repository: "library/ubuntu", Tag: "22"},
+		"ghcr.io/converged-computing/metric-osu-benchmark:latest": {
+			Registry: "ghcr.io", Repository: "converged-computing/metric-osu-benchmark", Tag: "latest",
+		},
+		"localhost:5000/metrics/app@sha256:abcd": {Registry: "localhost:5000", Repository: "metrics/app", Digest: "sha256:abcd"},
+	} {
+		ref := ParseReference(image)
+		if ref != expected {
+			t.Errorf("%s: expected %+v, found %+v", image, expected, ref)
+		}
+	}
+}
+
+// TestDigest resolves a digest with an anonymous token, like ghcr.io
+func TestDigest(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:metrics/app:pull" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token": "anonymous"}`)
+		case r.Header.Get("Authorization") != "Bearer anonymous":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:metrics/app:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method == http.MethodHead && r.URL.Path == "/v2/metrics/app/manifests/v1" &&
+			strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json"):
+			w.Header().Set(digestHeader, "sha256:1234")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewResolver(5 * time.Second)
+	resolver.Scheme = "http"
+	host := strings.TrimPrefix(server.URL, "http://")
+	digest, err := resolver.Digest(context.Background(), host+"/metrics/app:v1")
+	if err != nil || digest != "sha256:1234" {
+		t.Errorf("expected sha256:1234, found %q: %v", digest, err)
+	}
+	_, err = resolver.Digest(context.Background(), host+"/metrics/app:v2")
+	if err == nil {
+		t.Errorf("expected an error for a missing tag")
+	}
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package report
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Charts are drawn as standalone SVG, so a report doesn't need scripts or files
+
+const (
+	chartWidth  = 720
+	chartHeight = 360
+	marginLeft  = 70
+	marginRight = 20
+	marginTop   = 20
+	marginBelow = 50
+
+	// Height of a bar, and of a line of the legend
+	rowHeight = 18
+)
+
+// Colors of series, in order
+var palette = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+type point struct {
+	X float64
+	Y float64
+}
+
+// A series is a line of a chart
+type series struct {
+	Name   string
+	Points []point
+}
+
+// A bar is one value of a bar chart
+type bar struct {
+	Name  string
+	Value float64
+}
+
+// svg is a chart being drawn
+type svg struct {
+	strings.Builder
+}
+
+func newSVG(width, height int) *svg {
+	s := &svg{}
+	fmt.Fprintf(s, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`, width, height, width, height)
+	s.WriteString(`<rect width="100%" height="100%" fill="white"/>`)
+	return s
+}
+
+func (s *svg) text(x, y float64, anchor, value string, extra string) {
+	fmt.Fprintf(s, `<text x="%.1f" y="%.1f" text-anchor="%s"%s>%s</text>`, x, y, anchor, extra, html.EscapeString(value))
+}
+
+func (s *svg) line(x1, y1, x2, y2 float64, color string) {
+	fmt.Fprintf(s, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`, x1, y1, x2, y2, color)
+}
+
+func (s *svg) close() string {
+	s.WriteString("</svg>")
+	return s.String()
+}
+
+// An axis maps values to pixels, on a linear or log10 scale
+type axis struct {
+	min  float64
+	max  float64
+	from float64
+	to   float64
+	log  bool
+}
+
+func newAxis(values []float64, from, to float64, log bool) axis {
+	a := axis{min: math.Inf(1), max: math.Inf(-1), from: from, to: to, log: log}
+	for _, value := range values {
+		if log {
+			value = math.Log10(value)
+		}
+		a.min = math.Min(a.min, value)
+		a.max = math.Max(a.max, value)
+	}
+	if math.IsInf(a.min, 0) {
+		a.min, a.max = 0, 1
+	}
+	if a.min == a.max {
+		a.min, a.max = a.min-1, a.max+1
+	}
+	return a
+}
+
+func (a axis) scale(value float64) float64 {
+	if a.log {
+		value = math.Log10(value)
+	}
+	return a.from + (value-a.min)/(a.max-a.min)*(a.to-a.from)
+}
+
+// ticks are about five round values in the range of the axis
+func (a axis) ticks() []float64 {
+	if a.log {
+		ticks := []float64{}
+		for power := math.Ceil(a.min); power <= a.max; power++ {
+			ticks = append(ticks, math.Pow(10, power))
+		}
+		return ticks
+	}
+	raw := (a.max - a.min) / 5
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, factor := range []float64{1, 2, 5} {
+		if magnitude*factor >= raw {
+			step = magnitude * factor
+			break
+		}
+	}
+	ticks := []float64{}
+	for i := math.Ceil(a.min / step); i*step <= a.max+step/1e6; i++ {
+		ticks = append(ticks, i*step)
+	}
+	return ticks
+}
+
+// formatValue is a short label for a value
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', 6, 64)
+}
+
+// lineChart draws series of points, with a legend below the chart
+func lineChart(lines []series, xLabel, yLabel string, logX bool) string {
+	xs := []float64{}
+	ys := []float64{0}
+	for _, line := range lines {
+		for _, p := range line.Points {
+			xs = append(xs, p.X)
+			ys = append(ys, p.Y)
+		}
+	}
+	bottom := float64(chartHeight - marginBelow)
+	x := newAxis(xs, marginLeft, chartWidth-marginRight, logX)
+	y := newAxis(ys, bottom, marginTop, false)
+	s := newSVG(chartWidth, chartHeight+rowHeight*len(lines))
+	drawAxes(s, x, y, xLabel, yLabel)
+
+	for i, line := range lines {
+		color := palette[i%len(palette)]
+		points := []string{}
+		for _, p := range line.Points {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x.scale(p.X), y.scale(p.Y)))
+			fmt.Fprintf(s, `<circle cx="%.1f" cy="%.1f" r="2.5" fill="%s"/>`, x.scale(p.X), y.scale(p.Y), color)
+		}
+		fmt.Fprintf(s, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`, strings.Join(points, " "), color)
+		legend(s, i, line.Name, color, float64(chartHeight))
+	}
+	return s.close()
+}
+
+// drawAxes draws the axes with ticks and labels
+func drawAxes(s *svg, x, y axis, xLabel, yLabel string) {
+	s.line(x.from, y.from, x.to, y.from, "#333")
+	s.line(x.from, y.from, x.from, y.to, "#333")
+	for _, tick := range x.ticks() {
+		s.line(x.scale(tick), y.from, x.scale(tick), y.from+4, "#333")
+		s.text(x.scale(tick), y.from+16, "middle", formatValue(tick), "")
+	}
+	for _, tick := range y.ticks() {
+		s.line(x.from, y.scale(tick), x.to, y.scale(tick), "#eee")
+		s.text(x.from-6, y.scale(tick)+4, "end", formatValue(tick), "")
+	}
+	s.text((x.from+x.to)/2, y.from+36, "middle", xLabel, "")
+	s.text(14, (y.from+y.to)/2, "middle", yLabel, fmt.Sprintf(` transform="rotate(-90 14 %.1f)"`, (y.from+y.to)/2))
+}
+
+func legend(s *svg, i int, name, color string, top float64) {
+	y := top + float64(i*rowHeight)
+	fmt.Fprintf(s, `<rect x="%d" y="%.1f" width="10" height="10" fill="%s"/>`, marginLeft, y, color)
+	s.text(marginLeft+16, y+9, "start", name, "")
+}
+
+// barChart draws a horizontal bar for each value, with the name on the left
+func barChart(bars []bar, label string) string {
+	left := 240.0
+	values := []float64{0}
+	for _, b := range bars {
+		values = append(values, b.Value)
+	}
+	bottom := float64(marginTop + rowHeight*len(bars))
+	x := newAxis(values, left, chartWidth-marginRight, false)
+	s := newSVG(chartWidth, int(bottom)+marginBelow)
+	for _, tick := range x.ticks() {
+		s.line(x.scale(tick), marginTop, x.scale(tick), bottom, "#eee")
+		s.text(x.scale(tick), bottom+16, "middle", formatValue(tick), "")
+	}
+	for i, b := range bars {
+		y := float64(marginTop + i*rowHeight)
+		start, end := x.scale(0), x.scale(b.Value)
+		fmt.Fprintf(s, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="%s"><title>%s</title></rect>`,
+			math.Min(start, end), y+2, math.Abs(end-start), rowHeight-4, palette[0], html.EscapeString(b.Name+": "+formatValue(b.Value)))
+		s.text(left-6, y+rowHeight-5, "end", b.Name, "")
+	}
+	s.line(x.scale(0), marginTop, x.scale(0), bottom, "#333")
+	s.text((left+chartWidth-marginRight)/2, bottom+36, "middle", label, "")
+	return s.close()
+}
+
+// heatmap draws a matrix of values by column (x) and row (y), with a color scale
+func heatmap(columns, rows []string, values map[[2]string]float64, xLabel, yLabel, label string) string {
+	size := math.Max(4, math.Min(24, float64(chartWidth-marginLeft-marginRight-80)/float64(max(len(columns), 1))))
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, value := range values {
+		low = math.Min(low, value)
+		high = math.Max(high, value)
+	}
+	width := marginLeft + int(size*float64(len(columns))) + 100
+	height := max(marginTop+int(size*float64(len(rows)))+marginBelow, marginTop+150)
+	s := newSVG(width, height)
+	for j, row := range rows {
+		for i, column := range columns {
+			value, ok := values[[2]string{column, row}]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(s, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s</title></rect>`,
+				marginLeft+float64(i)*size, marginTop+float64(j)*size, size, size, colorScale(value, low, high),
+				html.EscapeString(fmt.Sprintf("%s %s, %s %s: %s", yLabel, row, xLabel, column, formatValue(value))))
+		}
+	}
+
+	// Label every row and column if they fit, or some of them
+	every := int(math.Ceil(12 / size))
+	for i, column := range columns {
+		if i%every == 0 {
+			s.text(marginLeft+(float64(i)+0.5)*size, float64(marginTop)+size*float64(len(rows))+14, "middle", column, "")
+		}
+	}
+	for j, row := range rows {
+		if j%every == 0 {
+			s.text(marginLeft-6, marginTop+(float64(j)+0.5)*size+4, "end", row, "")
+		}
+	}
+	bottom := float64(marginTop) + size*float64(len(rows))
+	s.text(marginLeft+size*float64(len(columns))/2, bottom+34, "middle", xLabel, "")
+	s.text(14, (marginTop+bottom)/2, "middle", yLabel, fmt.Sprintf(` transform="rotate(-90 14 %.1f)"`, (marginTop+bottom)/2))
+
+	// The color scale, from high (top) to low
+	scaleX := float64(marginLeft) + size*float64(len(columns)) + 20
+	for i := 0; i < 10; i++ {
+		value := high - (high-low)*float64(i)/9
+		fmt.Fprintf(s, `<rect x="%.1f" y="%d" width="12" height="12" fill="%s"/>`, scaleX, marginTop+i*12, colorScale(value, low, high))
+	}
+	s.text(scaleX+16, marginTop+10, "start", formatValue(high), "")
+	s.text(scaleX+16, marginTop+118, "start", formatValue(low), "")
+	s.text(scaleX, marginTop+136, "start", label, "")
+	return s.close()
+}
+
+// colorScale interpolates from light yellow (low) to dark blue (high)
+func colorScale(value, low, high float64) string {
+	stops := [][3]float64{{255, 255, 204}, {161, 218, 180}, {65, 182, 196}, {44, 127, 184}, {37, 52, 148}}
+	position := 0.0
+	if high > low {
+		position = (value - low) / (high - low) * float64(len(stops)-1)
+	}
+	i := int(math.Min(math.Floor(position), float64(len(stops)-2)))
+	fraction := position - float64(i)
+	color := [3]int{}
+	for c := range color {
+		color[c] = int(stops[i][c] + (stops[i+1][c]-stops[i][c])*fraction)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", color[0], color[1], color[2])
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package report
+
+import (
+	"encoding/base64"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Formats of a report
+const (
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+)
+
+// Write writes the report in a format: html or markdown
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatHTML:
+		return r.WriteHTML(w)
+	case FormatMarkdown:
+		return r.WriteMarkdown(w)
+	}
+	return fmt.Errorf("%s is not a known format, choose %s or %s", format, FormatHTML, FormatMarkdown)
+}
+
+// The HTML report has charts inline, and styles in the head, so it is one file
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("report").Funcs(htmltemplate.FuncMap{
+	"svg": func(svg string) htmltemplate.HTML { return htmltemplate.HTML(svg) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 1100px; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; font-size: 0.9em; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; }
+th { background: #f5f5f5; }
+figure { margin: 1em 0; overflow-x: auto; }
+code { background: #f5f5f5; padding: 0 4px; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>Runs: {{ range $i, $run := .Runs }}{{ if $i }}, {{ end }}<code>{{ $run }}</code>{{ end }}</p>
+{{ range .Sections }}
+<h2>{{ .Metric }}</h2>
+{{ if .Options }}<p>Options: <code>{{ .Options }}</code></p>{{ end }}
+{{ range .Figures }}<figure>
+<figcaption>{{ .Title }}</figcaption>
+{{ svg .SVG }}
+</figure>
+{{ end }}
+<table>
+<tr>{{ range .Table.Columns }}<th>{{ . }}</th>{{ end }}</tr>
+{{ range .Table.Rows }}<tr>{{ range . }}<td>{{ . }}</td>{{ end }}</tr>
+{{ end }}</table>
+<h3>Provenance</h3>
+{{ range .Provenance }}<table>
+<tr><th colspan="2">{{ .Run }}</th></tr>
+{{ range .Fields }}<tr><td>{{ .Name }}</td><td>{{ .Value }}</td></tr>
+{{ end }}</table>
+{{ end }}{{ end }}
+</body>
+</html>
+`))
+
+// WriteHTML writes the report as one HTML file
+func (r Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
+
+// The Markdown report has charts as data URIs, so it is one file too
+var markdownTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"image": func(svg string) string {
+		return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+	},
+	"row": func(cells []string) string {
+		escaped := []string{}
+		for _, cell := range cells {
+			escaped = append(escaped, strings.ReplaceAll(cell, "|", "\\|"))
+		}
+		return "| " + strings.Join(escaped, " | ") + " |"
+	},
+	"rule": func(cells []string) string {
+		return strings.Repeat("|---", len(cells)) + "|"
+	},
+}).Parse(`# {{ .Title }}
+
+Runs: {{ range $i, $run := .Runs }}{{ if $i }}, {{ end }}` + "`{{ $run }}`" + `{{ end }}
+{{ range .Sections }}
+## {{ .Metric }}
+{{ if .Options }}
+Options: ` + "`{{ .Options }}`" + `
+{{ end }}{{ range .Figures }}
+![{{ .Title }}]({{ image .SVG }})
+{{ end }}
+{{ row .Table.Columns }}
+{{ rule .Table.Columns }}
+{{ range .Table.Rows }}{{ row . }}
+{{ end }}
+### Provenance
+{{ range .Provenance }}
+| {{ .Run }} | |
+|---|---|
+{{ range .Fields }}| {{ .Name }} | {{ .Value }} |
+{{ end }}{{ end }}{{ end }}`))
+
+// WriteMarkdown writes the report as one Markdown file
+func (r Report) WriteMarkdown(w io.Writer) error {
+	return markdownTemplate.Execute(w, r)
+}
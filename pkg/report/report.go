@@ -0,0 +1,398 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+	"github.com/converged-computing/metrics-operator/pkg/results"
+)
+
+// A Run has the results of one run of a MetricSet, e.g., from the cluster or a directory
+type Run struct {
+	Name    string
+	Results []results.Result
+}
+
+// A Report has a section for each metric (with its options) in the runs
+type Report struct {
+	Title    string
+	Runs     []string
+	Sections []Section
+}
+
+// A Section has the charts, table, and provenance of a metric with some options.
+// Values are merged across the pods of each run, as for the cluster gauges.
+type Section struct {
+	Metric     string
+	Options    string
+	Chart      metrics.Chart
+	Figures    []Figure
+	Table      Table
+	Provenance []Provenance
+}
+
+// A Figure is a chart drawn as SVG
+type Figure struct {
+	Title string
+	SVG   string
+}
+
+// A Table has the values of a section, as text
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Provenance has the metadata of a metric in one run
+type Provenance struct {
+	Run    string
+	Fields []Field
+}
+
+// A Field is a name and value of provenance
+type Field struct {
+	Name  string
+	Value string
+}
+
+// entry is the summary of a metric in one run, with its results
+type entry struct {
+	run     string
+	summary results.Summary
+	results []results.Result
+}
+
+// New returns a report of runs, with a section for each metric and options
+func New(title string, runs []Run) Report {
+	report := Report{Title: title}
+	entries := map[string][]entry{}
+	keys := []string{}
+	for _, run := range runs {
+		report.Runs = append(report.Runs, run.Name)
+		for _, summary := range results.Summarize(run.Results) {
+			if summary.Scope() != results.ScopeCluster {
+				continue
+			}
+			key := summary.Metric + "\x00" + summary.Options
+			if _, ok := entries[key]; !ok {
+				keys = append(keys, key)
+			}
+			found := []results.Result{}
+			for _, result := range run.Results {
+				if result.Metric == summary.Metric && result.Options == summary.Options && result.MetricSet == summary.MetricSet {
+					found = append(found, result)
+				}
+			}
+			entries[key] = append(entries[key], entry{run: run.Name, summary: summary, results: found})
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		report.Sections = append(report.Sections, newSection(entries[key]))
+	}
+	return report
+}
+
+// newSection draws the charts of a metric in each run, with its table and provenance
+func newSection(entries []entry) Section {
+	metric := entries[0].summary.Metric
+	chart := metrics.Chart{Kind: metrics.ChartBar}
+	if m, ok := metrics.Registry[metric]; ok {
+		chart = metrics.GetResultChart(m)
+	}
+	section := Section{
+		Metric:  metric,
+		Options: entries[0].summary.Options,
+		Chart:   chart,
+	}
+	for _, name := range sampleNames(entries) {
+		switch chart.Kind {
+		case metrics.ChartLine, metrics.ChartTimeSeries:
+			section.Figures = append(section.Figures, lineFigure(entries, name, chart))
+		case metrics.ChartHeatmap:
+			section.Figures = append(section.Figures, heatmapFigures(entries, name, chart)...)
+		default:
+			section.Figures = append(section.Figures, barFigure(entries, name))
+		}
+	}
+	section.Table = newTable(entries, chart)
+	for _, e := range entries {
+		section.Provenance = append(section.Provenance, newProvenance(e))
+	}
+	return section
+}
+
+// sampleNames are the names of samples, in order of first appearance
+func sampleNames(entries []entry) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		for _, sample := range e.summary.Samples {
+			if !seen[sample.Name] {
+				seen[sample.Name] = true
+				names = append(names, sample.Name)
+			}
+		}
+	}
+	return names
+}
+
+// seriesName names the samples of a run with the labels that aren't plotted
+func seriesName(run string, sample metrics.Sample, skip ...string) string {
+	parts := []string{}
+	for _, name := range sortedLabels(sample.Labels) {
+		if !contains(skip, name) {
+			parts = append(parts, name+"="+sample.Labels[name])
+		}
+	}
+	if len(parts) == 0 {
+		return run
+	}
+	return run + " " + strings.Join(parts, ",")
+}
+
+// axisLabel is a label of a value, with its unit
+func axisLabel(name string, sample metrics.Sample) string {
+	if sample.Unit == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, sample.Unit)
+}
+
+// lineFigure draws a line for each run and labels, by the label of the chart. A value of
+// zero (e.g., an OSU message size of 0) isn't on a log scale, so it is only in the table.
+func lineFigure(entries []entry, name string, chart metrics.Chart) Figure {
+	lines := []series{}
+	index := map[string]int{}
+	var last metrics.Sample
+	for _, e := range entries {
+		for _, sample := range e.summary.Samples {
+			x, err := strconv.ParseFloat(sample.Labels[chart.X], 64)
+			if sample.Name != name || err != nil || (chart.LogX && x <= 0) {
+				continue
+			}
+			last = sample
+			key := seriesName(e.run, sample, chart.X, "unit")
+			if _, ok := index[key]; !ok {
+				index[key] = len(lines)
+				lines = append(lines, series{Name: key})
+			}
+			lines[index[key]].Points = append(lines[index[key]].Points, point{X: x, Y: sample.Value})
+		}
+	}
+	for _, line := range lines {
+		sort.Slice(line.Points, func(i, j int) bool { return line.Points[i].X < line.Points[j].X })
+	}
+	return Figure{Title: name, SVG: lineChart(lines, chart.X, axisLabel(name, last), chart.LogX)}
+}
+
+// barFigure draws a bar for each run and labels
+func barFigure(entries []entry, name string) Figure {
+	bars := []bar{}
+	var last metrics.Sample
+	for _, e := range entries {
+		for _, sample := range e.summary.Samples {
+			if sample.Name == name {
+				last = sample
+				bars = append(bars, bar{Name: seriesName(e.run, sample, "unit"), Value: sample.Value})
+			}
+		}
+	}
+	return Figure{Title: name, SVG: barChart(bars, axisLabel(name, last))}
+}
+
+// heatmapFigures draw a matrix of the labels of the chart for each run and other labels
+func heatmapFigures(entries []entry, name string, chart metrics.Chart) []Figure {
+	figures := []Figure{}
+	for _, e := range entries {
+		matrices := map[string]map[[2]string]float64{}
+		keys := []string{}
+		var last metrics.Sample
+		for _, sample := range e.summary.Samples {
+			if sample.Name != name {
+				continue
+			}
+			last = sample
+			key := seriesName(e.run, sample, chart.X, chart.Y, "unit")
+			if _, ok := matrices[key]; !ok {
+				keys = append(keys, key)
+				matrices[key] = map[[2]string]float64{}
+			}
+			matrices[key][[2]string{sample.Labels[chart.X], sample.Labels[chart.Y]}] = sample.Value
+		}
+		for _, key := range keys {
+			columns := map[string]bool{}
+			rows := map[string]bool{}
+			for cell := range matrices[key] {
+				columns[cell[0]] = true
+				rows[cell[1]] = true
+			}
+			figures = append(figures, Figure{
+				Title: fmt.Sprintf("%s (%s)", name, key),
+				SVG:   heatmap(sortValues(columns), sortValues(rows), matrices[key], chart.X, chart.Y, axisLabel(name, last)),
+			})
+		}
+	}
+	return figures
+}
+
+// newTable has a row for each sample of each run. The labels a heatmap or time
+// series plots are merged, with the min, mean, and max of the values.
+func newTable(entries []entry, chart metrics.Chart) Table {
+	merged := chart.Kind == metrics.ChartHeatmap || chart.Kind == metrics.ChartTimeSeries
+	skip := []string{"unit"}
+	if merged {
+		skip = append(skip, chart.X, chart.Y)
+	}
+	labels := map[string]bool{}
+	for _, e := range entries {
+		for _, sample := range e.summary.Samples {
+			for name := range sample.Labels {
+				if !contains(skip, name) {
+					labels[name] = true
+				}
+			}
+		}
+	}
+	columns := append([]string{"run", "field"}, sortValues(labels)...)
+	table := Table{Columns: columns}
+	if merged {
+		table.Columns = append(columns, "min", "mean", "max", "unit")
+	} else {
+		table.Columns = append(columns, "value", "unit")
+	}
+
+	for _, e := range entries {
+		rows := map[string][]float64{}
+		keys := []string{}
+		cells := map[string][]string{}
+		for _, sample := range e.summary.Samples {
+			row := []string{e.run, sample.Name}
+			for _, name := range columns[2:] {
+				row = append(row, sample.Labels[name])
+			}
+			if !merged {
+				table.Rows = append(table.Rows, append(row, formatValue(sample.Value), sample.Unit))
+				continue
+			}
+			key := strings.Join(append(row, sample.Unit), "\x00")
+			if _, ok := rows[key]; !ok {
+				keys = append(keys, key)
+				cells[key] = row
+			}
+			rows[key] = append(rows[key], sample.Value)
+		}
+		for _, key := range keys {
+			low, high, total := math.Inf(1), math.Inf(-1), 0.0
+			for _, value := range rows[key] {
+				low = math.Min(low, value)
+				high = math.Max(high, value)
+				total += value
+			}
+			unit := key[strings.LastIndex(key, "\x00")+1:]
+			table.Rows = append(table.Rows, append(cells[key],
+				formatValue(low), formatValue(total/float64(len(rows[key]))), formatValue(high), unit))
+		}
+	}
+	return table
+}
+
+// newProvenance has the metadata of the first log of a metric in a run,
+// with the nodes, kernels, and CPU models of all of its logs
+func newProvenance(e entry) Provenance {
+	provenance := Provenance{Run: e.run}
+	add := func(name, value string) {
+		if value != "" {
+			provenance.Fields = append(provenance.Fields, Field{Name: name, Value: value})
+		}
+	}
+	add("MetricSet", e.summary.Namespace+"/"+e.summary.MetricSet)
+	add("Options", e.summary.Options)
+	add("Pods", strconv.Itoa(e.summary.Pods))
+
+	nodes, kernels, cpus := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, result := range e.results {
+		nodes[result.Node] = true
+		if result.Metadata != nil {
+			kernels[result.Metadata.Kernel] = true
+			cpus[result.Metadata.CPUModel] = true
+		}
+	}
+	for _, result := range e.results {
+		if result.Metadata == nil {
+			continue
+		}
+		meta := result.Metadata
+		add("UID", meta.UID)
+		add("Spec hash", meta.SpecHash)
+		add("Operator version", meta.OperatorVersion)
+		add("Metric type", meta.MetricType)
+		images := []string{}
+		for _, image := range meta.Images {
+			if image.Digest != "" {
+				images = append(images, image.Image+"@"+image.Digest)
+			} else {
+				images = append(images, image.Image)
+			}
+		}
+		add("Images", strings.Join(images, ", "))
+		break
+	}
+	add("Nodes", strings.Join(sortValues(nodes), ", "))
+	add("Kernels", strings.Join(sortValues(kernels), ", "))
+	add("CPU models", strings.Join(sortValues(cpus), ", "))
+	return provenance
+}
+
+// sortValues sorts the keys of a set, numerically if they are all numbers,
+// and without the empty string
+func sortValues(set map[string]bool) []string {
+	values := []string{}
+	numeric := true
+	for value := range set {
+		if value == "" {
+			continue
+		}
+		values = append(values, value)
+		_, err := strconv.ParseFloat(value, 64)
+		numeric = numeric && err == nil
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if numeric {
+			a, _ := strconv.ParseFloat(values[i], 64)
+			b, _ := strconv.ParseFloat(values[j], 64)
+			return a < b
+		}
+		return values[i] < values[j]
+	})
+	return values
+}
+
+func sortedLabels(labels map[string]string) []string {
+	names := []string{}
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value && v != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+	"github.com/converged-computing/metrics-operator/pkg/results"
+
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/io"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/network"
+	_ "github.com/converged-computing/metrics-operator/pkg/metrics/perf"
+)
+
+// logOf returns a log of a metric with metadata and the output of each timepoint
+func logOf(metric, pod string, timepoints ...string) string {
+	lines := []string{
+		fmt.Sprintf(`METADATA START {"version":2,"pods":2,"metricSet":"ms","namespace":"default","metricName":"%s","pod":"%s","node":"node-%s","kernel":"6.1.0","images":[{"image":"ghcr.io/example:latest","digest":"sha256:abc"}]}`, metric, pod, pod),
+		"METADATA END",
+		metadata.CollectionStart,
+	}
+	for _, timepoint := range timepoints {
+		lines = append(lines, metadata.Separator, timepoint)
+	}
+	return strings.Join(append(lines, metadata.CollectionEnd), "\n")
+}
+
+func parse(t *testing.T, logs ...string) []results.Result {
+	found := []results.Result{}
+	for i, log := range logs {
+		result, err := results.ParseLog(results.Log{Source: fmt.Sprintf("log-%d", i), Content: log})
+		if err != nil {
+			t.Fatal(err)
+		}
+		found = append(found, result)
+	}
+	return found
+}
+
+func TestReport(t *testing.T) {
+	osu := func(latency string) string {
+		return strings.Join([]string{
+			"mpirun -np 2 /opt/pt2pt/osu_latency",
+			"# OSU MPI Latency Test v5.8",
+			"# Size          Latency (us)",
+			"0                       1.52",
+			"8                       " + latency,
+			"16                      1.70",
+		}, "\n")
+	}
+	netmark := strings.Join([]string{"ls", "NETMARK RTT.CSV START", "0.0,1.5,", "1.5,0.0,", "NETMARK RTT.CSV END"}, "\n")
+	iostat := func(util float64) string {
+		return fmt.Sprintf(`{"sysstat": {"hosts": [{"nodename": "n", "statistics": [{"disk": [{"disk_device": "sda", "r/s": 1.0, "w/s": 2.0, "util": %g}]}]}]}}`, util)
+	}
+	pidstat := func(cpu float64) string {
+		return fmt.Sprintf("CPU STATISTICS TASK\n"+`[{"time":1,"uid":0,"pid":10,"percent_usr":1.0,"percent_cpu":%g,"cpu":0,"command":"app"}]`+"\nPAGEFAULTS TASK\n"+`[{"pid":10,"rss":2048,"command":"app"}]`, cpu)
+	}
+
+	runs := []Run{
+		{Name: "a", Results: parse(t,
+			logOf("network-osu-benchmark", "0", osu("1.61")),
+			logOf("network-osu-benchmark", "1", osu("1.63")),
+			logOf("network-netmark", "0", netmark),
+			logOf("io-sysstat", "0", iostat(10), iostat(20), iostat(30)),
+			logOf("perf-sysstat", "0", pidstat(50), pidstat(70)),
+		)},
+		{Name: "b", Results: parse(t, logOf("network-osu-benchmark", "0", osu("2.00")))},
+	}
+	for _, result := range runs[0].Results {
+		if len(result.Samples) == 0 {
+			t.Fatalf("expected samples of %s", result.Metric)
+		}
+	}
+
+	report := New("Test", runs)
+	kinds := map[string]string{}
+	sections := map[string]Section{}
+	for _, section := range report.Sections {
+		kinds[section.Metric] = section.Chart.Kind
+		sections[section.Metric] = section
+	}
+	expected := map[string]string{
+		"io-sysstat":            metrics.ChartTimeSeries,
+		"network-netmark":       metrics.ChartHeatmap,
+		"network-osu-benchmark": metrics.ChartLine,
+		"perf-sysstat":          metrics.ChartTimeSeries,
+	}
+	if fmt.Sprint(kinds) != fmt.Sprint(expected) {
+		t.Fatalf("expected sections %v, found %v", expected, kinds)
+	}
+
+	// OSU has a line for each run, merged across pods with the max
+	osuSection := sections["network-osu-benchmark"]
+	if len(osuSection.Figures) != 1 || strings.Count(osuSection.Figures[0].SVG, "<polyline") != 2 {
+		t.Errorf("expected one chart with a line for each run")
+	}
+	if strings.Join(osuSection.Table.Columns, ",") != "run,field,size,value,unit" || len(osuSection.Table.Rows) != 6 ||
+		strings.Join(osuSection.Table.Rows[1], ",") != "a,osu_latency,8,1.63,us" {
+		t.Errorf("unexpected table %v", osuSection.Table)
+	}
+	if len(osuSection.Provenance) != 2 || osuSection.Provenance[0].Run != "a" {
+		t.Errorf("expected provenance for each run: %v", osuSection.Provenance)
+	}
+
+	// Netmark is a matrix of ranks
+	netmarkSection := sections["network-netmark"]
+	if len(netmarkSection.Figures) != 1 || strings.Count(netmarkSection.Figures[0].SVG, "<title>") != 4 {
+		t.Errorf("expected a heatmap of four cells")
+	}
+
+	// Time series are summarized over timepoints
+	iostatRows := sections["io-sysstat"].Table.Rows
+	if strings.Join(sections["io-sysstat"].Table.Columns, ",") != "run,field,device,min,mean,max,unit" ||
+		strings.Join(iostatRows[len(iostatRows)-1], ",") != "a,util,sda,10,20,30,%" {
+		t.Errorf("unexpected table %v", sections["io-sysstat"].Table)
+	}
+	pidstatNames := []string{}
+	for _, figure := range sections["perf-sysstat"].Figures {
+		pidstatNames = append(pidstatNames, figure.Title)
+	}
+	if len(pidstatNames) != 3 || !strings.Contains(strings.Join(pidstatNames, ","), "cpu_statistics_task_percent_cpu") {
+		t.Errorf("unexpected pidstat charts %v", pidstatNames)
+	}
+
+	// Each chart is valid XML
+	for _, section := range report.Sections {
+		for _, figure := range section.Figures {
+			err := xml.Unmarshal([]byte(figure.SVG), new(interface{}))
+			if err != nil {
+				t.Errorf("chart %s is not valid SVG: %s", figure.Title, err)
+			}
+		}
+	}
+
+	out := bytes.Buffer{}
+	err := report.Write(&out, FormatHTML)
+	if err != nil || strings.Count(out.String(), "<svg") != 8 || !strings.Contains(out.String(), "sha256:abc") {
+		t.Errorf("expected charts and provenance in the html: %v", err)
+	}
+	out.Reset()
+	err = report.Write(&out, FormatMarkdown)
+	if err != nil || strings.Count(out.String(), "data:image/svg+xml;base64,") != 8 || !strings.Contains(out.String(), "| a | osu_latency | 8 | 1.63 | us |") {
+		t.Errorf("expected charts and tables in the markdown: %v\n%s", err, out.String())
+	}
+}
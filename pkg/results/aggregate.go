@@ -0,0 +1,195 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package results
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// Scopes of a summary
+const (
+	ScopeNode    = "node"
+	ScopeCluster = "cluster"
+)
+
+// A Summary has the samples of a metric merged across the pods of one
+// node, or across all pods for the cluster (without a node)
+type Summary struct {
+	Namespace string
+	MetricSet string
+	Metric    string
+	Options   string
+	Node      string
+	Pods      int
+	Samples   []metrics.Sample
+}
+
+// Scope of the summary, a node or the cluster
+func (s Summary) Scope() string {
+	if s.Node == "" {
+		return ScopeCluster
+	}
+	return ScopeNode
+}
+
+// Summarize merges the samples of results from all pods of all replicated jobs,
+// for each node and for the cluster. Results are grouped by MetricSet, metric,
+// and options, and each group has its node summaries followed by the cluster.
+func Summarize(results []Result) []Summary {
+	groups := map[string][]Result{}
+	keys := []string{}
+	for _, result := range results {
+		if len(result.Samples) == 0 {
+			continue
+		}
+		key := strings.Join([]string{result.Namespace, result.MetricSet, result.Metric, result.Options}, "/")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], result)
+	}
+	sort.Strings(keys)
+
+	summaries := []Summary{}
+	for _, key := range keys {
+		group := groups[key]
+		nodes := map[string][]Result{}
+		names := []string{}
+		for _, result := range group {
+			if _, ok := nodes[result.Node]; !ok {
+				names = append(names, result.Node)
+			}
+			nodes[result.Node] = append(nodes[result.Node], result)
+		}
+		sort.Strings(names)
+		for _, node := range names {
+
+			// A pod that isn't scheduled to a node is only in the cluster summary
+			if node == "" {
+				continue
+			}
+			summaries = append(summaries, summarize(nodes[node], node))
+		}
+		summaries = append(summaries, summarize(group, ""))
+	}
+	return summaries
+}
+
+// summarize merges the samples of a group of results, for a node or the cluster
+func summarize(results []Result, node string) Summary {
+	names := []string{}
+	pods := map[string][]metrics.Sample{}
+	for _, result := range results {
+		if _, ok := pods[result.Pod]; !ok {
+			names = append(names, result.Pod)
+		}
+		pods[result.Pod] = append(pods[result.Pod], result.Samples...)
+	}
+	samples := [][]metrics.Sample{}
+	for _, name := range names {
+		samples = append(samples, pods[name])
+	}
+	return Summary{
+		Namespace: results[0].Namespace,
+		MetricSet: results[0].MetricSet,
+		Metric:    results[0].Metric,
+		Options:   results[0].Options,
+		Node:      node,
+		Pods:      len(samples),
+		Samples:   Merge(samples),
+	}
+}
+
+// Merge merges the samples of pods with the same name and labels, with the
+// merge of the sample. The values of each pod are merged first, and then
+// the values of the pods, so a rate is the mean of a pod, summed across pods.
+func Merge(pods [][]metrics.Sample) []metrics.Sample {
+	perPod := [][]metrics.Sample{}
+	for _, samples := range pods {
+		perPod = append(perPod, mergeSamples(samples, false))
+	}
+	all := []metrics.Sample{}
+	for _, samples := range perPod {
+		all = append(all, samples...)
+	}
+	return mergeSamples(all, true)
+}
+
+// mergeSamples merges samples with the same name and labels, in order of first appearance
+func mergeSamples(samples []metrics.Sample, acrossPods bool) []metrics.Sample {
+	merged := []metrics.Sample{}
+	values := map[string][]float64{}
+	index := map[string]int{}
+	for _, sample := range samples {
+		key := sampleKey(sample)
+		if _, ok := index[key]; !ok {
+			index[key] = len(merged)
+			merged = append(merged, sample)
+		}
+		values[key] = append(values[key], sample.Value)
+	}
+	for key, i := range index {
+		merged[i].Value = mergeValues(merged[i].Merge, values[key], acrossPods)
+	}
+	return merged
+}
+
+// mergeValues merges values of one pod, or of the pods
+func mergeValues(merge string, values []float64, acrossPods bool) float64 {
+	switch merge {
+	case metrics.MergeMax:
+		result := math.Inf(-1)
+		for _, value := range values {
+			result = math.Max(result, value)
+		}
+		return result
+	case metrics.MergeMin:
+		result := math.Inf(1)
+		for _, value := range values {
+			result = math.Min(result, value)
+		}
+		return result
+	case metrics.MergeHistogram:
+		return sum(values)
+	case metrics.MergeSum:
+		if acrossPods {
+			return sum(values)
+		}
+	}
+	return sum(values) / float64(len(values))
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// sampleKey is unique for the name and labels of a sample
+func sampleKey(sample metrics.Sample) string {
+	labels := []string{}
+	for name, value := range sample.Labels {
+		labels = append(labels, name+"="+value)
+	}
+	sort.Strings(labels)
+	return sample.Name + "\x00" + strings.Join(labels, "\x00")
+}
+
+// mergeName is the merge of a sample, for a label
+func mergeName(sample metrics.Sample) string {
+	if sample.Merge == "" {
+		return metrics.MergeMean
+	}
+	return sample.Merge
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package results
+
+import (
+	"testing"
+
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// samples has a rate, latency, histogram bucket, and a value without a merge
+func samples(bandwidth, latency, count, value float64) []metrics.Sample {
+	return []metrics.Sample{
+		{Name: "bandwidth", Value: bandwidth, Merge: metrics.MergeSum},
+		{Name: "latency", Value: latency, Merge: metrics.MergeMax},
+		{Name: "requests", Labels: map[string]string{"le": "10"}, Value: count, Merge: metrics.MergeHistogram},
+		{Name: "value", Value: value},
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	result := Result{Namespace: "default", MetricSet: "ms", Metric: "io-fio"}
+	results := []Result{}
+	for _, pod := range []struct {
+		node, pod string
+		samples   []metrics.Sample
+	}{
+		// Two timepoints for the first pod
+		{"node-0", "ms-w-0-0", append(samples(10, 1, 1, 1), samples(20, 3, 2, 3)...)},
+		{"node-0", "ms-w-0-1", samples(30, 2, 3, 4)},
+		{"node-1", "ms-w-0-2", samples(40, 5, 4, 6)},
+	} {
+		result.Node = pod.node
+		result.Pod = pod.pod
+		result.Container = pod.pod + "/workers"
+		result.Samples = pod.samples
+		results = append(results, result)
+	}
+
+	summaries := Summarize(results)
+	if len(summaries) != 3 || summaries[0].Node != "node-0" || summaries[1].Node != "node-1" || summaries[2].Scope() != ScopeCluster {
+		t.Fatalf("expected summaries for two nodes and the cluster, found %+v", summaries)
+	}
+	for i, expected := range []map[string]float64{
+		// The first pod has a bandwidth of 15 and a value of 2
+		{"bandwidth": 45, "latency": 3, "requests": 6, "value": 3},
+		{"bandwidth": 40, "latency": 5, "requests": 4, "value": 6},
+		{"bandwidth": 85, "latency": 5, "requests": 10, "value": 4},
+	} {
+		summary := summaries[i]
+		if len(summary.Samples) != 4 {
+			t.Fatalf("unexpected samples for %s: %v", summary.Node, summary.Samples)
+		}
+		for _, sample := range summary.Samples {
+			if sample.Value != expected[sample.Name] {
+				t.Errorf("expected %s=%v for %q, found %v", sample.Name, expected[sample.Name], summary.Node, sample.Value)
+			}
+		}
+	}
+	if summaries[0].Pods != 2 || summaries[2].Pods != 3 || summaries[2].Samples[2].Labels["le"] != "10" {
+		t.Errorf("unexpected cluster summary %+v", summaries[2])
+	}
+}
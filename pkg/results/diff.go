@@ -0,0 +1,214 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package results
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// Status of a sample between two runs
+const (
+	StatusUnchanged = "unchanged"
+	StatusImproved  = "improved"
+	StatusRegressed = "regressed"
+
+	// A significant change of a sample without a direction
+	StatusChanged = "changed"
+
+	// A sample in only one of the runs
+	StatusRemoved = "removed"
+	StatusAdded   = "added"
+)
+
+// Thresholds for a change to be significant. A change must be at least the
+// relative and absolute thresholds, and when both runs have repetitions of a
+// sample (from pods or timepoints), the difference of their means must be at
+// least Sigma standard errors.
+type Thresholds struct {
+	Relative float64
+	Absolute float64
+	Sigma    float64
+}
+
+// DefaultThresholds are a change of 5% and two standard errors
+var DefaultThresholds = Thresholds{Relative: 0.05, Sigma: 2}
+
+// A Difference is a sample (merged across pods, as for the cluster) in two runs
+type Difference struct {
+	Metric  string
+	Options string
+	Field   string
+	Labels  map[string]string
+	Unit    string
+	Better  string
+
+	// Values of the runs, and the number of values merged for each
+	A           float64
+	B           float64
+	Repetitions [2]int
+
+	// B - A, relative to A, and in standard errors (zero without repetitions)
+	Delta    float64
+	Relative float64
+	Sigma    float64
+
+	Status string
+}
+
+// Diff aligns the samples of two runs by metric, options, field (the name of
+// a sample) and labels, and compares them with the thresholds
+func Diff(a, b []Result, thresholds Thresholds) []Difference {
+	before, keys := diffSamples(a)
+	after, added := diffSamples(b)
+	for _, key := range added {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	differences := []Difference{}
+	for _, key := range keys {
+		x, inA := before[key]
+		y, inB := after[key]
+		sample := x
+		if !inA {
+			sample = y
+		}
+		difference := Difference{
+			Metric:      sample.metric,
+			Options:     sample.options,
+			Field:       sample.Name,
+			Labels:      sample.Labels,
+			Unit:        sample.Unit,
+			Better:      sample.Better,
+			A:           x.Value,
+			B:           y.Value,
+			Repetitions: [2]int{len(x.values), len(y.values)},
+		}
+		switch {
+		case !inB:
+			difference.Status = StatusRemoved
+		case !inA:
+			difference.Status = StatusAdded
+		default:
+			difference.compare(x.values, y.values, thresholds)
+		}
+		differences = append(differences, difference)
+	}
+	return differences
+}
+
+// compare sets the delta and status of a sample in both runs
+func (d *Difference) compare(a, b []float64, thresholds Thresholds) {
+	d.Delta = d.B - d.A
+	switch {
+	case d.Delta == 0:
+		d.Relative = 0
+	case d.A == 0:
+		d.Relative = math.Inf(int(math.Copysign(1, d.Delta)))
+	default:
+		d.Relative = d.Delta / math.Abs(d.A)
+	}
+
+	significant := math.Abs(d.Delta) > 0 &&
+		math.Abs(d.Delta) >= thresholds.Absolute &&
+		math.Abs(d.Relative) >= thresholds.Relative
+	if len(a) > 1 && len(b) > 1 {
+		meanA, varA := meanVariance(a)
+		meanB, varB := meanVariance(b)
+		stderr := math.Sqrt(varA/float64(len(a)) + varB/float64(len(b)))
+		switch {
+		case stderr > 0:
+			d.Sigma = math.Abs(meanB-meanA) / stderr
+		case meanA != meanB:
+			d.Sigma = math.Inf(1)
+		}
+		significant = significant && d.Sigma >= thresholds.Sigma
+	}
+
+	switch {
+	case !significant:
+		d.Status = StatusUnchanged
+	case d.Better == metrics.BetterHigher && d.Delta > 0, d.Better == metrics.BetterLower && d.Delta < 0:
+		d.Status = StatusImproved
+	case d.Better == metrics.BetterHigher, d.Better == metrics.BetterLower:
+		d.Status = StatusRegressed
+	default:
+		d.Status = StatusChanged
+	}
+}
+
+// meanVariance returns the mean and sample variance of values
+func meanVariance(values []float64) (float64, float64) {
+	mean := sum(values) / float64(len(values))
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, variance / float64(len(values)-1)
+}
+
+// diffSample is a sample merged across pods, with the values that were merged
+type diffSample struct {
+	metrics.Sample
+	metric  string
+	options string
+	values  []float64
+}
+
+// diffSamples merges the samples of a run across pods for each metric and options,
+// by key, and returns the keys in order. A run is aligned by options, so the
+// MetricSet can have another name.
+func diffSamples(run []Result) (map[string]diffSample, []string) {
+	groups := map[string][]Result{}
+	names := []string{}
+	for _, result := range run {
+		key := result.Metric + "\x00" + result.Options
+		if _, ok := groups[key]; !ok {
+			names = append(names, key)
+		}
+		groups[key] = append(groups[key], result)
+	}
+	sort.Strings(names)
+	samples := map[string]diffSample{}
+	keys := []string{}
+	for _, group := range names {
+		found := groups[group]
+		values := map[string][]float64{}
+		for _, result := range found {
+			for _, sample := range result.Samples {
+				values[sampleKey(sample)] = append(values[sampleKey(sample)], sample.Value)
+			}
+		}
+		for _, sample := range summarize(found, "").Samples {
+			key := group + "\x00" + sampleKey(sample)
+			keys = append(keys, key)
+			samples[key] = diffSample{
+				Sample:  sample,
+				metric:  found[0].Metric,
+				options: found[0].Options,
+				values:  values[sampleKey(sample)],
+			}
+		}
+	}
+	return samples, keys
+}
+
+// FormatLabels returns labels as name=value, sorted by name
+func FormatLabels(labels map[string]string) string {
+	parts := []string{}
+	for name, value := range labels {
+		parts = append(parts, name+"="+value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package results
+
+import (
+	"math"
+	"testing"
+
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+func TestDiff(t *testing.T) {
+	run := func(metricset string, bandwidth, latency []float64, extra string) []Result {
+		found := []Result{}
+		for i := range bandwidth {
+			samples := []metrics.Sample{
+				{Name: "bandwidth", Value: bandwidth[i], Merge: metrics.MergeSum, Better: metrics.BetterHigher},
+				{Name: "latency", Labels: map[string]string{"size": "8"}, Value: latency[i], Merge: metrics.MergeMax, Better: metrics.BetterLower},
+				{Name: "memory", Value: 100},
+			}
+			if extra != "" {
+				samples = append(samples, metrics.Sample{Name: extra, Value: 1})
+			}
+			found = append(found, Result{MetricSet: metricset, Metric: "m", Options: "tasks=2", Pod: string(rune('a' + i)), Samples: samples})
+		}
+		return found
+	}
+	a := run("a", []float64{10, 11, 10}, []float64{2.0, 2.1, 2.0}, "removed")
+	b := run("b", []float64{13, 14, 13}, []float64{2.05, 2.1, 2.0}, "added")
+
+	expected := map[string]string{
+		"bandwidth": StatusImproved,
+		"latency":   StatusUnchanged,
+		"memory":    StatusUnchanged,
+		"removed":   StatusRemoved,
+		"added":     StatusAdded,
+	}
+	differences := Diff(a, b, DefaultThresholds)
+	if len(differences) != len(expected) {
+		t.Fatalf("expected %d differences, found %d", len(expected), len(differences))
+	}
+	for _, d := range differences {
+		if d.Status != expected[d.Field] {
+			t.Errorf("expected %s to be %s, found %s (%+v)", d.Field, expected[d.Field], d.Status, d)
+		}
+	}
+
+	// Bandwidth is summed across pods, and compared with the repetitions of each pod
+	bandwidth := differences[0]
+	if bandwidth.A != 31 || bandwidth.B != 40 || bandwidth.Delta != 9 || bandwidth.Repetitions != [2]int{3, 3} || bandwidth.Sigma < 2 {
+		t.Errorf("unexpected difference %+v", bandwidth)
+	}
+
+	// The worst latency is the same, and without repetitions a change over the threshold regresses
+	latency := Diff(a[:1], b[2:], DefaultThresholds)[1]
+	if latency.Status != StatusUnchanged {
+		t.Errorf("expected latency to be unchanged, found %+v", latency)
+	}
+	latency = Diff(a[:1], b[:1], Thresholds{Relative: 0.01})[1]
+	if latency.Status != StatusRegressed || math.Abs(latency.Relative-0.025) > 1e-9 || latency.Sigma != 0 {
+		t.Errorf("expected latency to regress, found %+v", latency)
+	}
+	latency = Diff(a[:1], b[:1], Thresholds{Relative: 0.01, Absolute: 0.1})[1]
+	if latency.Status != StatusUnchanged {
+		t.Errorf("expected a change under the absolute threshold to be unchanged, found %+v", latency)
+	}
+
+	// A change from zero is infinitely relative, and a change without a direction is changed
+	zero := []Result{{Metric: "m", Pod: "a", Samples: []metrics.Sample{{Name: "count", Value: 0}}}}
+	one := []Result{{Metric: "m", Pod: "a", Samples: []metrics.Sample{{Name: "count", Value: 1}}}}
+	count := Diff(zero, one, DefaultThresholds)[0]
+	if count.Status != StatusChanged || !math.IsInf(count.Relative, 1) {
+		t.Errorf("expected count to be changed, found %+v", count)
+	}
+}
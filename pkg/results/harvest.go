@@ -0,0 +1,195 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package results
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha2"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// A Log is the log of one metric container, read from the cluster or a saved
+// file. Where it ran is taken from the metadata of the log if it isn't known.
+type Log struct {
+	Source        string
+	Content       string
+	Pod           string
+	Node          string
+	ReplicatedJob string
+	PodIndex      string
+	Container     string
+}
+
+// ReadCluster reads the logs of the metric containers of a MetricSet that completed
+func ReadCluster(ctx context.Context, clientset kubernetes.Interface, namespace, metricset string) ([]Log, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "metricset-name=" + metricset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	logs := []Log{}
+	for _, pod := range pods.Items {
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.State.Terminated == nil {
+				continue
+			}
+			content, err := clientset.CoreV1().Pods(namespace).
+				GetLogs(pod.Name, &corev1.PodLogOptions{Container: container.Name}).
+				DoRaw(ctx)
+			if err != nil {
+				return logs, fmt.Errorf("cannot read logs for %s/%s: %s", pod.Name, container.Name, err)
+			}
+			logs = append(logs, Log{
+				Source:        fmt.Sprintf("%s/%s/%s", namespace, pod.Name, container.Name),
+				Content:       string(content),
+				Pod:           pod.Name,
+				Node:          pod.Spec.NodeName,
+				ReplicatedJob: pod.Labels[jobset.ReplicatedJobNameKey],
+				PodIndex:      pod.Annotations[batchv1.JobCompletionIndexAnnotation],
+				Container:     container.Name,
+			})
+		}
+	}
+	return logs, nil
+}
+
+// ReadFiles reads saved logs from files and directories. In a directory (e.g., a copy
+// of the results volume) the output files are read with their manifest, and other
+// files ending in .log are read too.
+func ReadFiles(paths []string) ([]Log, error) {
+	logs := []Log{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return logs, err
+		}
+		if !info.IsDir() {
+			log, err := readFile(path)
+			if err != nil {
+				return logs, err
+			}
+			logs = append(logs, log)
+			continue
+		}
+		err = filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// Files saved by metrics are not logs
+			if entry.IsDir() && entry.Name() == metrics.ResultsFilesDir {
+				return filepath.SkipDir
+			}
+			if entry.IsDir() || (entry.Name() != metrics.ResultsOutputFile && filepath.Ext(path) != ".log") {
+				return nil
+			}
+			log, err := readFile(path)
+			if err == nil {
+				logs = append(logs, log)
+			}
+			return err
+		})
+		if err != nil {
+			return logs, err
+		}
+	}
+	return logs, nil
+}
+
+// readFile reads a saved log, with the manifest next to it if there is one
+func readFile(path string) (Log, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Log{}, err
+	}
+	log := Log{Source: path, Content: string(content)}
+	raw, err := os.ReadFile(filepath.Join(filepath.Dir(path), metrics.ResultsManifestFile))
+	if err != nil {
+		return log, nil
+	}
+	manifest := metrics.ResultsManifest{}
+	if json.Unmarshal(raw, &manifest) == nil {
+		log.Pod = manifest.Pod
+		log.ReplicatedJob = manifest.ReplicatedJob
+		log.PodIndex = strconv.Itoa(manifest.PodIndex)
+		log.Container = manifest.Container
+	}
+	return log, nil
+}
+
+// ParseLog parses the samples of a log with the parser of its metric, which
+// (with the options) is in the metadata of the log
+func ParseLog(log Log) (Result, error) {
+	export, ok := metadata.ParseMetadata(log.Content)
+	if !ok {
+		return Result{}, fmt.Errorf("%s does not have metric metadata", log.Source)
+	}
+	metric := api.Metric{
+		Name:        export.MetricName,
+		Options:     export.MetricOptions,
+		ListOptions: export.MetricListOptions,
+	}
+	set := &api.MetricSet{
+		ObjectMeta: metav1.ObjectMeta{Name: export.MetricSet, Namespace: export.Namespace},
+		Spec:       api.MetricSetSpec{Pods: export.Pods, Metrics: []api.Metric{metric}},
+	}
+	m, err := metrics.GetMetric(&set.Spec.Metrics[0], set)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %s", log.Source, err)
+	}
+	parser, ok := metrics.GetResultParser(m)
+	if !ok {
+		return Result{}, fmt.Errorf("%s: metric %s does not have a parser", log.Source, export.MetricName)
+	}
+	container := log.Container
+	if container == "" {
+		container = log.Source
+	}
+	return Result{
+		MetricSet:     export.MetricSet,
+		Namespace:     export.Namespace,
+		Metric:        export.MetricName,
+		Options:       metadata.FormatOptions(export.MetricOptions, export.MetricListOptions),
+		Node:          first(log.Node, export.Node),
+		Pod:           first(log.Pod, export.Pod),
+		ReplicatedJob: first(log.ReplicatedJob, export.ReplicatedJob),
+		PodIndex:      first(log.PodIndex, export.PodIndex),
+		Container:     container,
+		Samples:       parser.ParseResults(log.Content),
+		Metadata:      &export,
+	}, nil
+}
+
+// first returns the first value that isn't empty
+func first(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
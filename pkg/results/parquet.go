@@ -0,0 +1,215 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package results
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// A table is small, so it is written as a Parquet file with one row group and
+// one uncompressed (plain) page for each column. Cells are required UTF8 strings,
+// and the value is a required double. The metadata is encoded with the Thrift
+// compact protocol, see https://github.com/apache/parquet-format
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet types and encodings we use
+const (
+	parquetDouble     = 5
+	parquetByteArray  = 6
+	parquetUTF8       = 0
+	parquetRequired   = 0
+	parquetPlain      = 0
+	parquetRLE        = 3
+	parquetDataPage   = 0
+	parquetCodecPlain = 0
+)
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// parquetColumn is a column chunk that was written
+type parquetColumn struct {
+	name   string
+	kind   int32
+	offset int64
+	size   int64
+}
+
+// WriteParquet writes the table as a Parquet file
+func (t Table) WriteParquet(w io.Writer) error {
+	file := bytes.Buffer{}
+	file.Write(parquetMagic)
+
+	columns := []parquetColumn{}
+	for _, name := range t.Columns {
+		column := parquetColumn{name: name, kind: parquetByteArray, offset: int64(file.Len())}
+		data := bytes.Buffer{}
+		for _, row := range t.Rows {
+			if name == ValueColumn {
+				binary.Write(&data, binary.LittleEndian, math.Float64bits(row.Value))
+				continue
+			}
+			binary.Write(&data, binary.LittleEndian, uint32(len(row.Cells[name])))
+			data.WriteString(row.Cells[name])
+		}
+		if name == ValueColumn {
+			column.kind = parquetDouble
+		}
+
+		header := thriftWriter{}
+		header.structBody(func() {
+			header.i32(1, parquetDataPage)
+			header.i32(2, int32(data.Len()))
+			header.i32(3, int32(data.Len()))
+			header.structField(5, func() {
+				header.i32(1, int32(len(t.Rows)))
+				header.i32(2, parquetPlain)
+				header.i32(3, parquetRLE)
+				header.i32(4, parquetRLE)
+			})
+		})
+		file.Write(header.Bytes())
+		file.Write(data.Bytes())
+		column.size = int64(file.Len()) - column.offset
+		columns = append(columns, column)
+	}
+
+	footer := thriftWriter{}
+	footer.structBody(func() {
+		footer.i32(1, 1)
+
+		// The schema is a root with the columns as children
+		footer.list(2, thriftStruct, len(columns)+1)
+		footer.structBody(func() {
+			footer.binary(4, "schema")
+			footer.i32(5, int32(len(columns)))
+		})
+		for _, column := range columns {
+			footer.structBody(func() {
+				footer.i32(1, column.kind)
+				footer.i32(3, parquetRequired)
+				footer.binary(4, column.name)
+				if column.kind == parquetByteArray {
+					footer.i32(6, parquetUTF8)
+				}
+			})
+		}
+		footer.i64(3, int64(len(t.Rows)))
+
+		footer.list(4, thriftStruct, 1)
+		footer.structBody(func() {
+			footer.list(1, thriftStruct, len(columns))
+			total := int64(0)
+			for _, column := range columns {
+				total += column.size
+				footer.structBody(func() {
+					footer.i64(2, column.offset)
+					footer.structField(3, func() {
+						footer.i32(1, column.kind)
+						footer.list(2, thriftI32, 2)
+						footer.varint(zigzag(parquetPlain))
+						footer.varint(zigzag(parquetRLE))
+						footer.list(3, thriftBinary, 1)
+						footer.varint(uint64(len(column.name)))
+						footer.WriteString(column.name)
+						footer.i32(4, parquetCodecPlain)
+						footer.i64(5, int64(len(t.Rows)))
+						footer.i64(6, column.size)
+						footer.i64(7, column.size)
+						footer.i64(9, column.offset)
+					})
+				})
+			}
+			footer.i64(2, total)
+			footer.i64(3, int64(len(t.Rows)))
+		})
+		footer.binary(6, "metrics-operator")
+	})
+	file.Write(footer.Bytes())
+	binary.Write(&file, binary.LittleEndian, uint32(footer.Len()))
+	file.Write(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// thriftWriter writes structs with the Thrift compact protocol. Each struct
+// remembers the last field id, since field headers have the difference.
+type thriftWriter struct {
+	bytes.Buffer
+	last []int16
+}
+
+func (t *thriftWriter) header(id int16, kind byte) {
+	delta := id - t.last[len(t.last)-1]
+	if delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | kind)
+	} else {
+		t.WriteByte(kind)
+		t.varint(zigzag(int64(id)))
+	}
+	t.last[len(t.last)-1] = id
+}
+
+func (t *thriftWriter) varint(value uint64) {
+	t.Write(binary.AppendUvarint(nil, value))
+}
+
+func zigzag(value int64) uint64 {
+	return uint64((value << 1) ^ (value >> 63))
+}
+
+func (t *thriftWriter) i32(id int16, value int32) {
+	t.header(id, thriftI32)
+	t.varint(zigzag(int64(value)))
+}
+
+func (t *thriftWriter) i64(id int16, value int64) {
+	t.header(id, thriftI64)
+	t.varint(zigzag(value))
+}
+
+func (t *thriftWriter) binary(id int16, value string) {
+	t.header(id, thriftBinary)
+	t.varint(uint64(len(value)))
+	t.WriteString(value)
+}
+
+// list writes the header of a list, and the caller writes the elements
+func (t *thriftWriter) list(id int16, kind byte, size int) {
+	t.header(id, thriftList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | kind)
+		return
+	}
+	t.WriteByte(0xf0 | kind)
+	t.varint(uint64(size))
+}
+
+func (t *thriftWriter) structField(id int16, body func()) {
+	t.header(id, thriftStruct)
+	t.structBody(body)
+}
+
+// structBody writes the fields of a struct (or an element of a list) and the stop
+func (t *thriftWriter) structBody(body func()) {
+	t.last = append(t.last, 0)
+	body()
+	t.WriteByte(0)
+	t.last = t.last[:len(t.last)-1]
+}
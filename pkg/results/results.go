@@ -0,0 +1,317 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+// Package results keeps the latest parsed results of MetricSets, and serves
+// them as Prometheus gauges (or pushes them to a pushgateway).
+package results
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+var (
+	// DefaultRetention is how long results are served after they are parsed
+	DefaultRetention = 24 * time.Hour
+
+	// Gauges are named <namespace>_<metric>_<sample>
+	namespace = "metrics_operator"
+	pushJob   = "metrics-operator"
+
+	// Characters that are not valid in a metric or label name
+	invalidName = regexp.MustCompile("[^a-zA-Z0-9_]")
+)
+
+// A Result has the samples parsed from the log of one metric container
+type Result struct {
+	MetricSet string
+	Namespace string
+	Metric    string
+	Options   string
+	Node      string
+	Pod       string
+
+	// The replicated job and index of the pod (job completion index)
+	ReplicatedJob string
+	PodIndex      string
+
+	// The container (e.g., <pod uid>/<container>) is only recorded once
+	Container string
+	Samples   []metrics.Sample
+	Time      time.Time
+
+	// Metadata of the log, when it was parsed from one
+	Metadata *metadata.MetricExport
+}
+
+// key is unique for the container of a MetricSet
+func (r Result) key() string {
+	return fmt.Sprintf("%s/%s/%s", r.Namespace, r.MetricSet, r.Container)
+}
+
+// A Collector serves the latest results as gauges, until they are older
+// than the retention. It is an unchecked collector, since the gauges
+// depend on the metrics that have results.
+type Collector struct {
+	Retention   time.Duration
+	Pushgateway string
+
+	// The options of a metric are a label, when enabled
+	OptionsLabel bool
+
+	mutex   sync.Mutex
+	results map[string]Result
+	now     func() time.Time
+
+	// Containers that were recorded are kept after their results expire,
+	// so they are not read again while their pods exist
+	recorded map[string]bool
+}
+
+// NewCollector returns a collector, optionally pushing to a pushgateway URL
+func NewCollector(retention time.Duration, pushgateway string) *Collector {
+	return &Collector{
+		Retention:   retention,
+		Pushgateway: pushgateway,
+		results:     map[string]Result{},
+		recorded:    map[string]bool{},
+		now:         time.Now,
+	}
+}
+
+// Has determines if the container of a MetricSet was already recorded,
+// even if its results expired
+func (c *Collector) Has(namespace, metricset, container string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.recorded[Result{Namespace: namespace, MetricSet: metricset, Container: container}.key()]
+}
+
+// Record saves a result, replacing the last one of the same container
+func (c *Collector) Record(result Result) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if result.Time.IsZero() {
+		result.Time = c.now()
+	}
+	c.expire()
+	c.results[result.key()] = result
+	c.recorded[result.key()] = true
+}
+
+// Forget removes the recorded containers of a MetricSet that are not in the
+// list (e.g., their pods are gone). Their results are still served until they expire.
+func (c *Collector) Forget(namespace, metricset string, containers []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	keep := map[string]bool{}
+	for _, container := range containers {
+		keep[Result{Namespace: namespace, MetricSet: metricset, Container: container}.key()] = true
+	}
+	prefix := Result{Namespace: namespace, MetricSet: metricset}.key()
+	for key := range c.recorded {
+		if strings.HasPrefix(key, prefix) && !keep[key] {
+			delete(c.recorded, key)
+		}
+	}
+}
+
+// Push replaces the results of a MetricSet in the pushgateway. The namespace
+// and MetricSet are grouping labels, so they are not labels of the gauges.
+func (c *Collector) Push(namespace, metricset string) error {
+	if c.Pushgateway == "" {
+		return nil
+	}
+	return push.New(c.Pushgateway, pushJob).
+		Grouping("namespace", namespace).
+		Grouping("metricset", metricset).
+		Collector(&metricSetCollector{collector: c, namespace: namespace, metricset: metricset}).
+		Push()
+}
+
+// Describe doesn't send descriptions, so the collector is unchecked
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect sends the gauges of all results that are within the retention
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, metric := range c.gauges(c.current(), true) {
+		ch <- metric
+	}
+}
+
+// current removes expired results, and returns the rest in a consistent order
+func (c *Collector) current() []Result {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.expire()
+	results := []Result{}
+	for _, result := range c.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].key() < results[j].key() })
+	return results
+}
+
+// expire removes results that are older than the retention, the mutex must be held
+func (c *Collector) expire() {
+	if c.Retention <= 0 {
+		return
+	}
+	for key, result := range c.results {
+		if c.now().Sub(result.Time) > c.Retention {
+			delete(c.results, key)
+		}
+	}
+}
+
+// A gauge has the values of samples with the same name and label values
+type gauge struct {
+	desc   *prometheus.Desc
+	values []string
+	sample metrics.Sample
+	merged []float64
+}
+
+// gauges converts results, and their summaries for each node and the cluster, to gauges.
+// Without the MetricSet labels, they can be pushed with them as grouping labels. The
+// collector is unchecked, so samples with the same name and labels (e.g., of two
+// containers of a pod) are merged, since duplicate gauges would fail to gather.
+func (c *Collector) gauges(results []Result, withMetricSet bool) []prometheus.Metric {
+	merged := []*gauge{}
+	lookup := map[string]*gauge{}
+	add := func(name, help string, names, values []string, sample metrics.Sample) {
+
+		// Names and values are shared by samples, so labels are added to copies
+		if withMetricSet {
+			names = append([]string{"namespace", "metricset"}, names...)
+			values = append([]string{}, values...)
+		} else {
+			names = append([]string{}, names...)
+			values = append([]string{}, values[2:]...)
+		}
+		labels := []string{}
+		for label := range sample.Labels {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			names = append(names, sanitize(label))
+			values = append(values, sample.Labels[label])
+		}
+		key := name + "\x00" + strings.Join(names, "\x00") + "\x00" + strings.Join(values, "\x00")
+		g, ok := lookup[key]
+		if !ok {
+			g = &gauge{desc: prometheus.NewDesc(name, help, names, nil), values: values, sample: sample}
+			lookup[key] = g
+			merged = append(merged, g)
+		}
+		g.merged = append(g.merged, sample.Value)
+	}
+
+	// Values start with the namespace and MetricSet, which are labels if requested
+	for _, result := range results {
+		names := []string{"metric", "node", "pod", "replicated_job", "pod_index"}
+		values := []string{result.Namespace, result.MetricSet, result.Metric, result.Node, result.Pod, result.ReplicatedJob, result.PodIndex}
+		if c.OptionsLabel {
+			names = append(names, "options")
+			values = append(values, result.Options)
+		}
+		for _, sample := range result.Samples {
+			add(
+				GaugeName(result.Metric, sample.Name),
+				fmt.Sprintf("Result %s parsed from the log of metric %s", sample.Name, result.Metric),
+				names, values, sample,
+			)
+		}
+	}
+	for _, summary := range Summarize(results) {
+		names := []string{"metric", "merge"}
+		if c.OptionsLabel {
+			names = append(names, "options")
+		}
+		if summary.Node != "" {
+			names = append(names, "node")
+		}
+		for _, sample := range summary.Samples {
+			values := []string{summary.Namespace, summary.MetricSet, summary.Metric, mergeName(sample)}
+			if c.OptionsLabel {
+				values = append(values, summary.Options)
+			}
+			if summary.Node != "" {
+				values = append(values, summary.Node)
+			}
+			add(
+				GaugeName(summary.Metric, sample.Name)+"_"+summary.Scope(),
+				fmt.Sprintf("Result %s of metric %s merged across the pods of each %s", sample.Name, summary.Metric, summary.Scope()),
+				names, values, sample,
+			)
+		}
+	}
+
+	gauges := []prometheus.Metric{}
+	for _, g := range merged {
+		value := mergeValues(g.sample.Merge, g.merged, false)
+		metric, err := prometheus.NewConstMetric(g.desc, prometheus.GaugeValue, value, g.values...)
+		if err == nil {
+			gauges = append(gauges, metric)
+		}
+	}
+	return gauges
+}
+
+// GaugeName is the name of the gauge for a sample of a metric,
+// e.g., metrics_operator_app_hpl_gflops
+func GaugeName(metric, sample string) string {
+	return prometheus.BuildFQName(namespace, sanitize(metric), sanitize(sample))
+}
+
+// sanitize replaces characters that are not valid in Prometheus names
+func sanitize(name string) string {
+	name = invalidName.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// Options returns the options of a metric as a label value,
+// e.g., commands=osu_bw;osu_latency,tasks=2
+func Options(metric *api.Metric) string {
+	return metadata.FormatOptions(metric.Options, metric.ListOptions)
+}
+
+// metricSetCollector collects the results of one MetricSet to push them
+type metricSetCollector struct {
+	collector *Collector
+	namespace string
+	metricset string
+}
+
+func (c *metricSetCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (c *metricSetCollector) Collect(ch chan<- prometheus.Metric) {
+	results := []Result{}
+	for _, result := range c.collector.current() {
+		if result.Namespace == c.namespace && result.MetricSet == c.metricset {
+			results = append(results, result)
+		}
+	}
+	for _, metric := range c.collector.gauges(results, false) {
+		ch <- metric
+	}
+}
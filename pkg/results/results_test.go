@@ -0,0 +1,222 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package results
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+	"github.com/converged-computing/metrics-operator/pkg/metrics/network"
+)
+
+var osuLog = strings.Join([]string{
+	"METADATA START {}",
+	metadata.CollectionStart,
+	metadata.Separator,
+	"mpirun --allow-run-as-root --hostfile ./hostfile-pairs.mpi -np 2 /opt/pt2pt/osu_latency",
+	"# OSU MPI Latency Test v5.8",
+	"# Size          Latency (us)",
+	"0                       1.52",
+	"8                       1.61",
+	metadata.CollectionEnd,
+}, "\n")
+
+func TestCollector(t *testing.T) {
+	samples := network.OSUBenchmark{}.ParseResults(osuLog)
+	if len(samples) != 2 || samples[1].Value != 1.61 || samples[1].Labels["size"] != "8" || samples[1].Labels["unit"] != "us" {
+		t.Fatalf("unexpected samples %v", samples)
+	}
+
+	now := time.Now()
+	collector := NewCollector(time.Hour, "")
+	collector.OptionsLabel = true
+	collector.now = func() time.Time { return now }
+	collector.Record(Result{
+		MetricSet: "ms",
+		Namespace: "default",
+		Metric:    "network-osu-benchmark",
+		Options:   "tasks=2",
+		Node:      "node-0",
+		Pod:       "ms-l-0-0",
+		Container: "uid/launcher",
+		Samples:   samples,
+	})
+	if !collector.Has("default", "ms", "uid/launcher") {
+		t.Errorf("expected the container to be recorded")
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The results of the pod, and summaries for its node and the cluster
+	if len(families) != 3 || families[0].GetName() != "metrics_operator_network_osu_benchmark_osu_latency" ||
+		families[1].GetName() != "metrics_operator_network_osu_benchmark_osu_latency_cluster" ||
+		families[2].GetName() != "metrics_operator_network_osu_benchmark_osu_latency_node" {
+		t.Fatalf("unexpected gauges %v", families)
+	}
+	labels := map[string]string{}
+	for _, label := range families[0].GetMetric()[0].GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+	for name, value := range map[string]string{"metricset": "ms", "node": "node-0", "options": "tasks=2", "size": "0"} {
+		if labels[name] != value {
+			t.Errorf("expected label %s=%s, found %v", name, value, labels)
+		}
+	}
+
+	// Results are removed after the retention, and the container is
+	// still recorded until its pod is gone
+	now = now.Add(2 * time.Hour)
+	families, err = registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 0 {
+		t.Errorf("expected results to expire, found %v", families)
+	}
+	collector.Forget("default", "ms", []string{"uid/launcher"})
+	if !collector.Has("default", "ms", "uid/launcher") {
+		t.Errorf("expected the container to be recorded while its pod exists")
+	}
+	collector.Forget("default", "ms", nil)
+	if collector.Has("default", "ms", "uid/launcher") {
+		t.Errorf("expected the container to be forgotten when its pod is gone")
+	}
+}
+
+func TestCollectorDuplicates(t *testing.T) {
+	collector := NewCollector(time.Hour, "")
+
+	// Two containers of a pod, and repeated samples of one container
+	samples := []metrics.Sample{
+		{Name: "bandwidth", Labels: map[string]string{"size": "8"}, Value: 1, Merge: metrics.MergeMax},
+		{Name: "bandwidth", Labels: map[string]string{"size": "8"}, Value: 3, Merge: metrics.MergeMax},
+		{Name: "latency", Value: 2},
+	}
+	for _, container := range []string{"uid/launcher", "uid/sidecar"} {
+		collector.Record(Result{
+			MetricSet: "ms",
+			Namespace: "default",
+			Metric:    "network-osu-benchmark",
+			Node:      "node-0",
+			Pod:       "ms-l-0-0",
+			Container: container,
+			Samples:   samples,
+		})
+	}
+	collector.Record(Result{
+		MetricSet: "ms",
+		Namespace: "default",
+		Metric:    "network-osu-benchmark",
+		Node:      "node-0",
+		Pod:       "ms-l-0-0",
+		Container: "uid/other",
+		Samples:   []metrics.Sample{{Name: "latency", Value: 5}},
+	})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		if len(family.GetMetric()) != 1 {
+			t.Errorf("expected one gauge for %s, found %v", family.GetName(), family.GetMetric())
+			continue
+		}
+		values[family.GetName()] = family.GetMetric()[0].GetGauge().GetValue()
+
+		// The options label is opt-in
+		for _, label := range family.GetMetric()[0].GetLabel() {
+			if label.GetName() == "options" {
+				t.Errorf("expected no options label for %s", family.GetName())
+			}
+		}
+	}
+	expected := map[string]float64{
+		"metrics_operator_network_osu_benchmark_bandwidth":         3,
+		"metrics_operator_network_osu_benchmark_bandwidth_node":    3,
+		"metrics_operator_network_osu_benchmark_bandwidth_cluster": 3,
+		"metrics_operator_network_osu_benchmark_latency":           3,
+		"metrics_operator_network_osu_benchmark_latency_node":      3,
+		"metrics_operator_network_osu_benchmark_latency_cluster":   3,
+	}
+	if len(values) != len(expected) {
+		t.Errorf("expected gauges %v, found %v", expected, values)
+	}
+	for name, value := range expected {
+		if values[name] != value {
+			t.Errorf("expected %s to be %v, found %v", name, value, values[name])
+		}
+	}
+}
+
+func TestPush(t *testing.T) {
+	var path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		decoded, err := expfmt.ExtractSamples(&expfmt.DecodeOptions{Timestamp: 0}, decodeFamilies(t, r)...)
+		if err != nil {
+			t.Error(err)
+		}
+		for _, sample := range decoded {
+			body += sample.Metric.String() + "\n"
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	collector := NewCollector(time.Hour, server.URL)
+	collector.Record(Result{MetricSet: "ms", Namespace: "default", Metric: "network-osu-benchmark", Container: "uid/launcher", Samples: network.OSUBenchmark{}.ParseResults(osuLog)})
+	collector.Record(Result{MetricSet: "other", Namespace: "default", Metric: "network-osu-benchmark", Container: "uid/launcher", Samples: network.OSUBenchmark{}.ParseResults(osuLog)})
+	err := collector.Push("default", "ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The grouping labels are in any order
+	if !strings.HasPrefix(path, "/metrics/job/metrics-operator/") ||
+		!strings.Contains(path+"/", "/namespace/default/") || !strings.Contains(path+"/", "/metricset/ms/") {
+		t.Errorf("unexpected push path %s", path)
+	}
+	if strings.Count(body, "metrics_operator_network_osu_benchmark_osu_latency{") != 2 || strings.Contains(body, "metricset=") {
+		t.Errorf("expected the results of one MetricSet, without grouping labels:\n%s", body)
+	}
+}
+
+// decodeFamilies reads the metric families of a push request
+func decodeFamilies(t *testing.T, r *http.Request) []*dto.MetricFamily {
+	families := []*dto.MetricFamily{}
+	decoder := expfmt.NewDecoder(r.Body, expfmt.ResponseFormat(r.Header))
+	for {
+		family := &dto.MetricFamily{}
+		err := decoder.Decode(family)
+		if err == io.EOF {
+			return families
+		}
+		if err != nil {
+			t.Error(err)
+			return families
+		}
+		families = append(families, family)
+	}
+}
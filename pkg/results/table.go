@@ -0,0 +1,186 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package results
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formats of an exported table
+const (
+	FormatCSV       = "csv"
+	FormatJSONLines = "jsonl"
+	FormatParquet   = "parquet"
+)
+
+var (
+	// Options and labels of samples are columns with a prefix
+	optionPrefix = "option_"
+	labelPrefix  = "label_"
+
+	// Columns of every table, options come after the metric and labels after the field
+	ValueColumn   = "value"
+	metricColumns = []string{"metricset", "namespace", "metric"}
+	podColumns    = []string{"replicated_job", "pod_index", "pod", "node", "field"}
+	unitColumn    = "unit"
+)
+
+// A Table is a long-form table of results, with one row for each sample.
+// Cells are strings, except for the value.
+type Table struct {
+	Columns []string
+	Rows    []Row
+}
+
+// A Row has the cells of each column, and the value
+type Row struct {
+	Cells map[string]string
+	Value float64
+}
+
+// NewTable returns a table with the samples of results. The columns are the metricset,
+// namespace, metric, each option, the replicated job, pod index, pod, node, field (the
+// name of the sample), each label of a sample, the value, and the unit.
+func NewTable(results []Result) Table {
+	options := map[string]bool{}
+	labels := map[string]bool{}
+	rows := []Row{}
+	for _, result := range results {
+		cells := map[string]string{
+			"metricset":      result.MetricSet,
+			"namespace":      result.Namespace,
+			"metric":         result.Metric,
+			"replicated_job": result.ReplicatedJob,
+			"pod_index":      result.PodIndex,
+			"pod":            result.Pod,
+			"node":           result.Node,
+		}
+		for name, value := range resultOptions(result) {
+			options[optionPrefix+name] = true
+			cells[optionPrefix+name] = value
+		}
+		for _, sample := range result.Samples {
+			row := Row{Cells: map[string]string{}, Value: sample.Value}
+			for name, value := range cells {
+				row.Cells[name] = value
+			}
+			row.Cells["field"] = sample.Name
+			row.Cells[unitColumn] = sample.Unit
+			for name, value := range sample.Labels {
+
+				// The unit is its own column
+				if name == unitColumn && value == sample.Unit {
+					continue
+				}
+				labels[labelPrefix+name] = true
+				row.Cells[labelPrefix+name] = value
+			}
+			rows = append(rows, row)
+		}
+	}
+	columns := append([]string{}, metricColumns...)
+	columns = append(columns, sortedKeys(options)...)
+	columns = append(columns, podColumns...)
+	columns = append(columns, sortedKeys(labels)...)
+	columns = append(columns, ValueColumn, unitColumn)
+	return Table{Columns: columns, Rows: rows}
+}
+
+// resultOptions are the options of a result, from the metadata of its log
+func resultOptions(result Result) map[string]string {
+	options := map[string]string{}
+	if result.Metadata == nil {
+		return options
+	}
+	for name, value := range result.Metadata.MetricOptions {
+		options[name] = value.String()
+	}
+	for name, values := range result.Metadata.MetricListOptions {
+		items := []string{}
+		for _, value := range values {
+			items = append(items, value.String())
+		}
+		options[name] = strings.Join(items, ";")
+	}
+	return options
+}
+
+func sortedKeys(values map[string]bool) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Write writes the table in a format: csv, jsonl, or parquet
+func (t Table) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatCSV:
+		return t.WriteCSV(w)
+	case FormatJSONLines:
+		return t.WriteJSONLines(w)
+	case FormatParquet:
+		return t.WriteParquet(w)
+	}
+	return fmt.Errorf("%s is not a known format, choose %s, %s, or %s", format, FormatCSV, FormatJSONLines, FormatParquet)
+}
+
+// WriteCSV writes the table with a header
+func (t Table) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(t.Columns)
+	if err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		record := []string{}
+		for _, column := range t.Columns {
+			if column == ValueColumn {
+				record = append(record, strconv.FormatFloat(row.Value, 'g', -1, 64))
+				continue
+			}
+			record = append(record, row.Cells[column])
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSONLines writes one object for each row, with every column.
+// A value that isn't a number (NaN or infinity) is null.
+func (t Table) WriteJSONLines(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, row := range t.Rows {
+		object := map[string]interface{}{}
+		for _, column := range t.Columns {
+			object[column] = row.Cells[column]
+		}
+		object[ValueColumn] = nil
+		if !math.IsNaN(row.Value) && !math.IsInf(row.Value, 0) {
+			object[ValueColumn] = row.Value
+		}
+		err := encoder.Encode(object)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
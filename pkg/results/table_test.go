@@ -0,0 +1,279 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package results
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/converged-computing/metrics-operator/pkg/metrics"
+)
+
+// A saved log of osu_latency, with the metadata of a launcher
+var savedLog = `METADATA START {"version":2,"pods":2,"metricSet":"ms","namespace":"default","metricName":"network-osu-benchmark","metricOptions":{"tasks":2},"metricListOptions":{"commands":["osu_latency"]},"node":"node-0","pod":"ms-l-0-0-abcde","podIndex":"0"}
+METADATA END
+` + osuLog
+
+func TestExport(t *testing.T) {
+	tmp := t.TempDir()
+	directory := filepath.Join(tmp, "ms", "1234", "l", "0", "launcher")
+	err := os.MkdirAll(filepath.Join(directory, metrics.ResultsFilesDir), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, _ := json.Marshal(metrics.ResultsManifest{Pod: "ms-l-0-0", ReplicatedJob: "l", Container: "launcher"})
+	for path, content := range map[string]string{
+		filepath.Join(directory, metrics.ResultsOutputFile):            savedLog,
+		filepath.Join(directory, metrics.ResultsManifestFile):          string(manifest),
+		filepath.Join(directory, metrics.ResultsFilesDir, "trace.log"): "not a log",
+		filepath.Join(tmp, "ms-l-0-0.log"):                             savedLog,
+		filepath.Join(tmp, "notes.txt"):                                "not read",
+	} {
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	logs, err := ReadFiles([]string{tmp})
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("expected two logs, found %d: %v", len(logs), err)
+	}
+	results := []Result{}
+	for _, log := range logs {
+		result, err := ParseLog(log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	if results[0].Pod != "ms-l-0-0" || results[0].ReplicatedJob != "l" || results[0].Node != "node-0" || results[1].Pod != "ms-l-0-0-abcde" {
+		t.Errorf("expected the pod from the metadata or the manifest: %+v", results)
+	}
+
+	table := NewTable(results)
+	expected := "metricset,namespace,metric,option_commands,option_tasks,replicated_job,pod_index,pod,node,field,label_size,value,unit"
+	if strings.Join(table.Columns, ",") != expected || len(table.Rows) != 4 {
+		t.Fatalf("unexpected table %v with %d rows", table.Columns, len(table.Rows))
+	}
+
+	// CSV
+	out := bytes.Buffer{}
+	err = table.Write(&out, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil || len(records) != 5 || strings.Join(records[4], ",") != "ms,default,network-osu-benchmark,osu_latency,2,,0,ms-l-0-0-abcde,node-0,osu_latency,8,1.61,us" {
+		t.Errorf("unexpected csv %v: %v", records, err)
+	}
+
+	// JSON lines
+	out.Reset()
+	err = table.Write(&out, FormatJSONLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(&out)
+	rows := 0
+	for scanner.Scan() {
+		row := map[string]interface{}{}
+		err = json.Unmarshal(scanner.Bytes(), &row)
+		if err != nil || row["field"] != "osu_latency" || row["unit"] != "us" || len(row) != len(table.Columns) {
+			t.Errorf("unexpected row %s: %v", scanner.Text(), err)
+		}
+		rows++
+	}
+	if rows != 4 {
+		t.Errorf("expected 4 rows, found %d", rows)
+	}
+	if table.Write(&out, "xlsx") == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestParquet(t *testing.T) {
+	out := bytes.Buffer{}
+	err := parquetTable.WriteParquet(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := readParquet(t, out.Bytes())
+	if contents.CreatedBy != "metrics-operator" || contents.Rows != 3 {
+		t.Fatalf("unexpected footer %+v", contents)
+	}
+	schema := []string{"metric 6 0 0", "node 6 0 0", "value 5 0 <nil>"}
+	if !reflect.DeepEqual(contents.Schema, schema) {
+		t.Errorf("expected schema %v, found %v", schema, contents.Schema)
+	}
+	values := [][]interface{}{
+		{"io-fio", "perf-stream", "network-iperf3"},
+		{"node-0", "", "nœud-1"},
+		{1.5, -2.0, 9376.904},
+	}
+	if !reflect.DeepEqual(contents.Columns, values) {
+		t.Errorf("expected values %v, found %v", values, contents.Columns)
+	}
+}
+
+// TestParquetReference compares the table with the same table written by a
+// reference writer, parquet-go v1.6.2 (github.com/xitongsys/parquet-go), with
+// plain encoding and without compression. It has statistics and page indexes we
+// don't write, so the schema and values are compared, and not the bytes.
+func TestParquetReference(t *testing.T) {
+	reference, err := os.ReadFile(filepath.Join("testdata", "reference.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := readParquet(t, reference)
+	out := bytes.Buffer{}
+	err = parquetTable.WriteParquet(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := readParquet(t, out.Bytes())
+	if contents.Rows != expected.Rows || !reflect.DeepEqual(contents.Schema, expected.Schema) ||
+		!reflect.DeepEqual(contents.Columns, expected.Columns) {
+		t.Errorf("expected the reference %+v, found %+v", expected, contents)
+	}
+}
+
+// The table of the reference Parquet file
+var parquetTable = Table{
+	Columns: []string{"metric", "node", ValueColumn},
+	Rows: []Row{
+		{Cells: map[string]string{"metric": "io-fio", "node": "node-0"}, Value: 1.5},
+		{Cells: map[string]string{"metric": "perf-stream"}, Value: -2},
+		{Cells: map[string]string{"metric": "network-iperf3", "node": "nœud-1"}, Value: 9376.904},
+	},
+}
+
+// parquetContents are read from a Parquet file with one row group, and
+// plain, uncompressed data pages of required columns
+type parquetContents struct {
+	CreatedBy string
+	Rows      int64
+
+	// Each column is "<name> <type> <repetition> <converted type>"
+	Schema  []string
+	Columns [][]interface{}
+}
+
+// readParquet reads the footer, and the values of each column from its pages
+func readParquet(t *testing.T, file []byte) parquetContents {
+	if !bytes.HasPrefix(file, parquetMagic) || !bytes.HasSuffix(file, parquetMagic) {
+		t.Fatalf("expected the parquet magic")
+	}
+	length := binary.LittleEndian.Uint32(file[len(file)-8:])
+	footer := readThrift(t, bytes.NewReader(file[len(file)-8-int(length):len(file)-8]))
+	contents := parquetContents{Rows: footer[3].(int64), Schema: []string{}, Columns: [][]interface{}{}}
+	contents.CreatedBy, _ = footer[6].(string)
+
+	schema := footer[2].([]interface{})
+	if schema[0].(map[int16]interface{})[5] != int64(len(schema)-1) {
+		t.Fatalf("unexpected schema root %v", schema[0])
+	}
+	for _, element := range schema[1:] {
+		fields := element.(map[int16]interface{})
+		contents.Schema = append(contents.Schema, fmt.Sprintf("%v %v %v %v", fields[4], fields[1], fields[3], fields[6]))
+	}
+
+	groups := footer[4].([]interface{})
+	if len(groups) != 1 {
+		t.Fatalf("expected one row group, found %d", len(groups))
+	}
+	for _, chunk := range groups[0].(map[int16]interface{})[1].([]interface{}) {
+		meta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+		if meta[4] != int64(parquetCodecPlain) {
+			t.Fatalf("unexpected codec %v", meta[4])
+		}
+		reader := bytes.NewReader(file[meta[9].(int64):])
+		column := []interface{}{}
+		for int64(len(column)) < meta[5].(int64) {
+			page := readThrift(t, reader)
+			data := page[5].(map[int16]interface{})
+			if page[1] != int64(parquetDataPage) || data[2] != int64(parquetPlain) {
+				t.Fatalf("unexpected page %v", page)
+			}
+			for i := int64(0); i < data[1].(int64); i++ {
+				if meta[1] == int64(parquetDouble) {
+					var bits uint64
+					binary.Read(reader, binary.LittleEndian, &bits)
+					column = append(column, math.Float64frombits(bits))
+					continue
+				}
+				var size uint32
+				binary.Read(reader, binary.LittleEndian, &size)
+				value := make([]byte, size)
+				reader.Read(value)
+				column = append(column, string(value))
+			}
+		}
+		contents.Columns = append(contents.Columns, column)
+	}
+	return contents
+}
+
+// readThrift reads a struct of the Thrift compact protocol, as fields by id
+func readThrift(t *testing.T, reader *bytes.Reader) map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	last := int16(0)
+	for {
+		header, err := reader.ReadByte()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			value, _ := binary.ReadUvarint(reader)
+			id = int16(int64(value>>1) ^ -int64(value&1))
+		}
+		last = id
+		fields[id] = readThriftValue(t, reader, header&0x0f)
+	}
+}
+
+func readThriftValue(t *testing.T, reader *bytes.Reader, kind byte) interface{} {
+	switch kind {
+	case thriftI32, thriftI64:
+		value, _ := binary.ReadUvarint(reader)
+		return int64(value>>1) ^ -int64(value&1)
+	case thriftBinary:
+		size, _ := binary.ReadUvarint(reader)
+		value := make([]byte, size)
+		reader.Read(value)
+		return string(value)
+	case thriftList:
+		header, _ := reader.ReadByte()
+		size := uint64(header >> 4)
+		if size == 15 {
+			size, _ = binary.ReadUvarint(reader)
+		}
+		items := []interface{}{}
+		for i := uint64(0); i < size; i++ {
+			items = append(items, readThriftValue(t, reader, header&0x0f))
+		}
+		return items
+	case thriftStruct:
+		return readThrift(t, reader)
+	}
+	t.Fatalf("unexpected thrift type %d", kind)
+	return nil
+}
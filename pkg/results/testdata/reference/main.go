@@ -0,0 +1,53 @@
+//go:build ignore
+
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+// Write reference.parquet with a reference writer, parquet-go v1.6.2. It is not
+// a dependency of the operator, so run it from a module that requires it:
+//
+//	go run main.go ../reference.parquet
+package main
+
+import (
+	"os"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type row struct {
+	Metric string  `parquet:"name=metric, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN"`
+	Node   string  `parquet:"name=node, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN"`
+	Value  float64 `parquet:"name=value, type=DOUBLE, encoding=PLAIN"`
+}
+
+func main() {
+	fw, err := local.NewLocalFileWriter(os.Args[1])
+	if err != nil {
+		panic(err)
+	}
+	pw, err := writer.NewParquetWriter(fw, new(row), 1)
+	if err != nil {
+		panic(err)
+	}
+	pw.CompressionType = parquet.CompressionCodec_UNCOMPRESSED
+	for _, r := range []row{
+		{Metric: "io-fio", Node: "node-0", Value: 1.5},
+		{Metric: "perf-stream", Value: -2},
+		{Metric: "network-iperf3", Node: "nœud-1", Value: 9376.904},
+	} {
+		if err := pw.Write(r); err != nil {
+			panic(err)
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		panic(err)
+	}
+	fw.Close()
+}
@@ -11,8 +11,10 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	api "github.com/converged-computing/metrics-operator/api/v1alpha2"
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -61,12 +63,216 @@ type EntrypointScript struct {
 
 	// Anything after the command!
 	Post string
+
+	// Seconds the pre and command blocks can run before they are stopped
+	Timeout int32
+
+	// Hold the container for debugging (onFailure or always) after the post block
+	Debug            string
+	DebugLines       int32
+	DebugHoldSeconds int32
+
+	// Save the output to a file (e.g., for an addon that uploads it)
+	OutputFile string
+
+	// Metric name for the JSON-lines events written after markers (no events if empty)
+	Metric string
+}
+
+// The entrypoint runs the pre and command blocks in the entrypoint shell, so
+// the post block can use anything they define. A timeout runs a watchdog alongside
+// the pre and command blocks. The trap only runs when the current command returns,
+// so the watchdog stops the children of the entrypoint (except tee, for debug mode).
+// The post block still runs so the collection end is written, and it can read the
+// exit code of the command (or the timeout) from metrics_operator_exit_code. With a
+// timeout or debug mode, it is also the exit code of the entrypoint (and the job fails
+// instead of restarting), otherwise that is the exit code of the post block. Debug mode
+// saves the output, and a function writes the exit code and last lines of output to the
+// debug file and holds the container. For onFailure, a zero exit code does not hold.
+// An output file gets the same output, for the post block to use. With a metric name,
+// a filter keeps a JSON-lines event with a timestamp for each marker line it sees
+// (including markers written in loops), and writes them after the collection end. A flush
+// function waits for the output to be written (background processes can hold it open), and
+// output after it only goes to the container log. It runs on exit, and before the output is saved.
+var entrypointTemplate = template.Must(template.New("entrypoint").Parse(`
+{{- if not (or .Timeout .Debug .OutputFile .Metric) -}}
+{{ .Pre }}
+{{ .Command }}
+{{ .Post }}
+{{ else -}}
+#!/bin/bash
+{{- if .Metric }}
+# Keep a JSON-lines event for each marker, iteration counts the timepoints before it.
+# Events are written after the collection end (or the end of the output), outside of
+# the sections that parsers split at the markers.
+metrics_operator_events() {
+    local line marker event json iteration=0 events=()
+    while IFS= read -r line || [[ -n "${line}" ]]; do
+        printf '%s\n' "${line}"
+        [[ "${line}" == *"{{ .MarkerPrefix }}"* ]] || continue
+        [[ "${line}" =~ ^[[:space:]]*(.*[^[:space:]])[[:space:]]*$ ]] && marker="${BASH_REMATCH[1]}"
+        case "${marker}" in
+            "{{ .CollectionStart }}") event={{ .EventStart }} ;;
+            "{{ .Separator }}") event={{ .EventTimepoint }} ;;
+            "{{ .CollectionEnd }}") event={{ .EventEnd }} ;;
+            "{{ .TimeoutMarker }}") event={{ .EventTimeout }} ;;
+            *) continue ;;
+        esac
+        printf -v json '{"event":"%s","ts":%s,"metric":"%s","iteration":%d}' "${event}" "${EPOCHREALTIME:-$(date +%s)}" "{{ .Metric }}" "${iteration}"
+        events+=("${json}")
+        [[ "${event}" != "{{ .EventTimepoint }}" ]] || iteration=$((iteration + 1))
+        if [[ "${event}" == "{{ .EventEnd }}" ]]; then
+            printf '%s\n' "${events[@]}"
+            events=()
+        fi
+    done
+    [[ ${#events[@]} -eq 0 ]] || printf '%s\n' "${events[@]}"
+}
+{{- end }}
+{{- if or .Debug .OutputFile .Metric }}
+# Save output{{ if .Debug }} and hold the container for debugging ({{ .Debug }}){{ end }}{{ if .Metric }}, with events{{ end }}
+# Flush waits for the output to be written, and anything after only goes to the container log
+exec {metrics_operator_stdout}>&1 {metrics_operator_stderr}>&2
+exec > >({{ .OutputPipeline }}) 2>&1
+metrics_operator_tee=$!
+metrics_operator_flush() {
+    exec >&${metrics_operator_stdout} 2>&${metrics_operator_stderr}
+    for i in {1..20}; do
+        kill -0 ${metrics_operator_tee} 2>/dev/null || return 0
+        sleep 0.1
+    done
+}
+trap metrics_operator_flush EXIT
+{{- end }}
+{{- if .Debug }}
+metrics_operator_debug() {
+{{- if eq .Debug "onFailure" }}
+    if [[ "${1}" == "0" ]]; then
+        return
+    fi
+{{- end }}
+    metrics_operator_flush
+    echo "exit code: ${1}" > {{ .DebugFile }}
+    echo "last {{ .DebugLines }} lines of output:" >> {{ .DebugFile }}
+    tail -n {{ .DebugLines }} {{ .DebugOutputFile }} >> {{ .DebugFile }}
+    echo "Metric exited with code ${1}, details are in {{ .DebugFile }}"
+    echo "Holding for {{ .DebugHoldSeconds }} seconds, run 'pkill -f metrics_operator_hold' in the container to continue"
+    echo "{{ .DebugHold }}"
+    bash -c "exec -a metrics_operator_hold sleep {{ .DebugHoldSeconds }}"
+}
+{{- end }}
+{{- if .Timeout }}
+# Stop the metric if it runs for more than {{ .Timeout }} seconds
+metrics_operator_timeout() {
+    trap - USR1
+    echo "{{ .TimeoutMarker }}"
+    echo "Metric did not finish within {{ .Timeout }} seconds"
+    echo "timeout" > /dev/termination-log 2>/dev/null
+    metrics_operator_exit_code={{ .TimeoutExitCode }}
+{{ .Post }}
+{{ if .Debug }}metrics_operator_debug {{ .TimeoutExitCode }}{{ end }}
+    exit {{ .TimeoutExitCode }}
+}
+trap metrics_operator_timeout USR1
+(
+    # Stopping the watchdog also stops its sleep, which would hold the output open
+    sleep {{ .Timeout }} &
+    metrics_operator_sleep=$!
+    trap 'kill ${metrics_operator_sleep} 2>/dev/null; exit 0' TERM
+    wait ${metrics_operator_sleep}
+    kill -USR1 $$
+    for stat in /proc/[0-9]*/stat; do
+        read -r pid comm state ppid rest < ${stat} 2>/dev/null || continue
+        if [[ "${ppid}" == "$$" ]] && [[ "${pid}" != "${BASHPID}" ]] && [[ "${pid}" != "${metrics_operator_tee}" ]]; then
+            kill -TERM ${pid} 2>/dev/null
+        fi
+    done
+) &
+metrics_operator_watchdog=$!
+{{- end }}
+{{ .Pre }}
+{{ .Command }}
+metrics_operator_exit_code=$?
+{{- if .Timeout }}
+kill ${metrics_operator_watchdog} 2>/dev/null
+trap - USR1
+{{- end }}
+{{ .Post }}
+{{ if .Debug }}metrics_operator_debug ${metrics_operator_exit_code}
+{{ end -}}
+{{ if or .Timeout .Debug }}exit ${metrics_operator_exit_code}{{ end }}
+{{- end }}`))
+
+// entrypointData adds the markers and defaults used by the entrypoint template
+type entrypointData struct {
+	EntrypointScript
+	TimeoutMarker   string
+	TimeoutExitCode int32
+	DebugHold       string
+	DebugFile       string
+	DebugOutputFile string
+	OutputPipeline  string
+
+	// Markers and the events written for them
+	MarkerPrefix    string
+	CollectionStart string
+	Separator       string
+	CollectionEnd   string
+	EventStart      string
+	EventTimepoint  string
+	EventEnd        string
+	EventTimeout    string
 }
 
 // WriteScript writes the final script, combining the pre, command, and post
 func (e EntrypointScript) WriteScript() string {
-	return fmt.Sprintf("%s\n%s\n%s\n", e.Pre, e.Command, e.Post)
+	data := entrypointData{
+		EntrypointScript: e,
+		TimeoutMarker:    metadata.Timeout,
+		TimeoutExitCode:  metadata.TimeoutExitCode,
+		MarkerPrefix:     metadata.MarkerPrefix,
+		CollectionStart:  metadata.CollectionStart,
+		Separator:        metadata.Separator,
+		CollectionEnd:    metadata.CollectionEnd,
+		EventStart:       metadata.EventStart,
+		EventTimepoint:   metadata.EventTimepoint,
+		EventEnd:         metadata.EventEnd,
+		EventTimeout:     metadata.EventTimeout,
+		DebugHold:        metadata.DebugHold,
+		DebugFile:        metadata.DebugFile,
+		DebugOutputFile:  metadata.DebugOutputFile,
+	}
+	if data.DebugLines <= 0 {
+		data.DebugLines = metadata.DebugLines
+	}
+	if data.DebugHoldSeconds <= 0 {
+		data.DebugHoldSeconds = metadata.DebugHoldSeconds
+	}
+
+	// Events are written first, so saved output has them too
+	pipeline := []string{}
+	if e.Metric != "" {
+		pipeline = append(pipeline, "metrics_operator_events")
+	}
+	if e.Debug != "" || e.OutputFile != "" {
+		tee := "tee -a"
+		if e.Debug != "" {
+			tee += " " + metadata.DebugOutputFile
+		}
+		if e.OutputFile != "" {
+			tee += " " + e.OutputFile
+		}
+		pipeline = append(pipeline, tee)
+	}
+	data.OutputPipeline = strings.Join(pipeline, " | ")
 
+	// The template is fixed and the data only has strings and numbers
+	var script strings.Builder
+	err := entrypointTemplate.Execute(&script, data)
+	if err != nil {
+		fmt.Printf("🟥️ Issue writing entrypoint %s: %s\n", e.Name, err)
+	}
+	return script.String()
 }
 
 // Given a full path, derive the key from the script name minus the extension
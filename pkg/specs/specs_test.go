@@ -0,0 +1,147 @@
+/*
+Copyright 2023 Lawrence Livermore National Security, LLC
+ (c.f. AUTHORS, NOTICE.LLNS, COPYING)
+
+SPDX-License-Identifier: MIT
+*/
+
+package specs_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/converged-computing/metrics-operator/pkg/metadata"
+	"github.com/converged-computing/metrics-operator/pkg/specs"
+)
+
+// TestEntrypointExitCode runs entrypoints that succeed, fail, and time out, and checks
+// the exit code is that of the command (or the timeout) when there is a timeout. Without
+// one, a failing command exits with the post block, so the pod does not restart.
+func TestEntrypointExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		timeout  int32
+		exitCode int
+		markers  []string
+	}{
+		{name: "success", command: "true", exitCode: 0, markers: []string{metadata.CollectionEnd}},
+		{name: "failure", command: "exit_with() { return $1; }; exit_with 3", exitCode: 0, markers: []string{metadata.CollectionEnd}},
+		{name: "success with timeout", command: "true", timeout: 5, exitCode: 0, markers: []string{metadata.CollectionEnd}},
+		{name: "failure with timeout", command: "false", timeout: 5, exitCode: 1, markers: []string{metadata.CollectionEnd}},
+		{
+			name:     "timeout",
+			command:  "sleep 10",
+			timeout:  1,
+			exitCode: int(metadata.TimeoutExitCode),
+			markers:  []string{metadata.Timeout, metadata.CollectionEnd},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entrypoint := specs.EntrypointScript{
+				Pre:     fmt.Sprintf("#!/bin/bash\necho %s", metadata.CollectionStart),
+				Command: test.command,
+				Post:    fmt.Sprintf("echo %s", metadata.CollectionEnd),
+				Timeout: test.timeout,
+			}
+			script := entrypoint.WriteScript()
+			out, err := exec.Command("bash", "-c", script).CombinedOutput()
+			exitCode := 0
+			if exitError, ok := err.(*exec.ExitError); ok {
+				exitCode = exitError.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if exitCode != test.exitCode {
+				t.Errorf("expected exit code %d, found %d\n%s\n%s", test.exitCode, exitCode, out, script)
+			}
+			for _, marker := range test.markers {
+				if !strings.Contains(string(out), marker) {
+					t.Errorf("expected %s in output:\n%s", marker, out)
+				}
+			}
+		})
+	}
+}
+
+// TestEntrypointDebugOnFailure checks that onFailure only holds the container
+// when the command fails, and that the exit code is kept after the hold
+func TestEntrypointDebugOnFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		exitCode int
+		hold     bool
+	}{
+		{name: "success", command: "true", exitCode: 0},
+		{name: "failure", command: "exit_with() { return $1; }; exit_with 3", exitCode: 3, hold: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entrypoint := specs.EntrypointScript{
+				Pre:              "#!/bin/bash",
+				Command:          test.command,
+				Post:             fmt.Sprintf("echo %s", metadata.CollectionEnd),
+				Debug:            "onFailure",
+				DebugHoldSeconds: 1,
+			}
+
+			// Write the debug files to a temporary directory
+			tmp := t.TempDir()
+			script := entrypoint.WriteScript()
+			script = strings.ReplaceAll(script, metadata.DebugFile, filepath.Join(tmp, "debug.log"))
+			script = strings.ReplaceAll(script, metadata.DebugOutputFile, filepath.Join(tmp, "output.log"))
+
+			out, err := exec.Command("bash", "-c", script).CombinedOutput()
+			exitCode := 0
+			if exitError, ok := err.(*exec.ExitError); ok {
+				exitCode = exitError.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if exitCode != test.exitCode {
+				t.Errorf("expected exit code %d, found %d\n%s", test.exitCode, exitCode, out)
+			}
+			if strings.Contains(string(out), metadata.DebugHold) != test.hold {
+				t.Errorf("expected hold to be %t, output:\n%s", test.hold, out)
+			}
+			_, err = os.Stat(filepath.Join(tmp, "debug.log"))
+			if (err == nil) != test.hold {
+				t.Errorf("expected debug file to exist to be %t", test.hold)
+			}
+		})
+	}
+}
+
+// TestEntrypointFlush checks the output file has all output before the flush,
+// and output after it only goes to the container log
+func TestEntrypointFlush(t *testing.T) {
+	tmp := t.TempDir()
+	output := filepath.Join(tmp, "output.txt")
+	entrypoint := specs.EntrypointScript{
+		Pre:        "#!/bin/bash",
+		Command:    "echo hello",
+		Post:       fmt.Sprintf("echo %s\nmetrics_operator_flush\ncp %s %s\necho goodbye", metadata.CollectionEnd, output, filepath.Join(tmp, "copy.txt")),
+		OutputFile: output,
+	}
+	out, err := exec.Command("bash", "-c", entrypoint.WriteScript()).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	saved, err := os.ReadFile(filepath.Join(tmp, "copy.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(saved), metadata.CollectionEnd) || strings.Contains(string(saved), "goodbye") {
+		t.Errorf("expected the output up to the flush to be saved, found:\n%s", saved)
+	}
+	if !strings.Contains(string(out), "hello") || !strings.Contains(string(out), "goodbye") {
+		t.Errorf("expected all output in the log, found:\n%s", out)
+	}
+}
@@ -10,3 +10,8 @@ func EscapeCharacters(str string) string {
 	str = strings.ReplaceAll(str, "'", "\\'")
 	return str
 }
+
+// ShellQuote single quotes a value for the shell
+func ShellQuote(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", `'\''`) + "'"
+}